@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
 
 	"path/filepath"
 )
@@ -13,15 +14,42 @@ var (
 )
 
 type Arguments struct {
-	Cache  string   // Argument for cache folder path
-	Config string   // Argument for config file path
-	Lock   string   // Argument for lock file path
-	Log    string   // Argument for log file path
-	VVV    bool     // Argument for very very verbose mode
-	VV     bool     // Argument for very verbose mode
-	V      bool     // Argument for verbose mode
-	P      []string // Argument for positional values
-	Dbus   struct {
+	Cache     string   // Argument for cache folder path
+	Config    string   // Argument for config file path
+	Lock      string   // Argument for lock file path
+	Log       string   // Argument for log file path
+	LogFormat string   // Argument for log output format
+	LogLevels string   // Argument for log levels per module
+	Displays  string   // Argument for displays managed by workers
+	Instance  string   // Argument for worker instance name
+	DryRun    bool     // Argument for dry-run mode
+	VVV       bool     // Argument for very very verbose mode
+	VV        bool     // Argument for very verbose mode
+	V         bool     // Argument for verbose mode
+	P         []string // Argument for positional values
+	Doctor    bool     // Argument for doctor subcommand
+	Check     bool     // Argument for check-config subcommand
+	Focus     string   // Argument for focus subcommand
+	Layout    string   // Argument for layout subcommand
+	CacheCmd  string   // Argument for cache subcommand
+	Explain   string   // Argument for explain subcommand
+	Inspect   bool     // Argument for inspect subcommand
+	List      struct {
+		Format string   // Argument for list output format
+		P      []string // Argument for list positional values
+	}
+	Rules struct {
+		Test    bool   // Argument for rules test subcommand
+		Class   string // Argument for tested window class
+		Name    string // Argument for tested window name
+		Process string // Argument for tested process name
+		Cmdline string // Argument for tested process command line
+		Type    string // Argument for tested window type
+		State   string // Argument for tested window state
+		Desktop int    // Argument for tested desktop number
+		Screen  int    // Argument for tested screen index
+	}
+	Dbus struct {
 		Listen   bool     // Argument for dbus listen flag
 		Method   string   // Argument for dbus method name
 		Property string   // Argument for dbus property name
@@ -36,6 +64,11 @@ func InitArgs(introspect map[string][]string) {
 	flag.StringVar(&Args.Config, "config", filepath.Join(ConfigFolderPath(Build.Name), "config.toml"), "config file path")
 	flag.StringVar(&Args.Lock, "lock", filepath.Join(os.TempDir(), fmt.Sprintf("%s.lock", Build.Name)), "lock file path")
 	flag.StringVar(&Args.Log, "log", filepath.Join(os.TempDir(), fmt.Sprintf("%s.log", Build.Name)), "log file path")
+	flag.StringVar(&Args.LogFormat, "log-format", "text", "log output format (text | json)")
+	flag.StringVar(&Args.LogLevels, "log-levels", "", "comma separated log levels per module (e.g. store=debug,ui=warn)")
+	flag.StringVar(&Args.Displays, "displays", "", "comma separated X displays managed by separate worker processes")
+	flag.StringVar(&Args.Instance, "instance", "", "instance name of a worker process (e.g. display1)")
+	flag.BoolVar(&Args.DryRun, "dry-run", false, "log window operations without applying them")
 	flag.BoolVar(&Args.VVV, "vvv", false, "very very verbose mode")
 	flag.BoolVar(&Args.VV, "vv", false, "very verbose mode")
 	flag.BoolVar(&Args.V, "v", false, "verbose mode")
@@ -51,6 +84,12 @@ func InitArgs(introspect map[string][]string) {
 	flag.Parse()
 	Args.P = flag.Args()
 
+	// Check command line arguments
+	if !regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)?$`).MatchString(Args.Instance) || !IsInList(Args.LogFormat, []string{"text", "json"}) {
+		flag.CommandLine.Usage()
+		os.Exit(2)
+	}
+
 	// Subcommand line arguments
 	dbus := flag.NewFlagSet("dbus", flag.ExitOnError)
 	dbus.BoolVar(&Args.Dbus.Listen, "listen", false, "dbus listen mode")
@@ -58,8 +97,171 @@ func InitArgs(introspect map[string][]string) {
 	dbus.StringVar(&Args.Dbus.Property, "property", "", "dbus property reader")
 	Args.Dbus.P = []string{}
 
+	list := flag.NewFlagSet("list", flag.ExitOnError)
+	list.StringVar(&Args.List.Format, "format", "tsv", "output format (tsv | json)")
+	Args.List.P = []string{}
+
+	focus := flag.NewFlagSet("focus", flag.ExitOnError)
+	layout := flag.NewFlagSet("layout", flag.ExitOnError)
+	doctor := flag.NewFlagSet("doctor", flag.ExitOnError)
+	check := flag.NewFlagSet("check-config", flag.ExitOnError)
+	cache := flag.NewFlagSet("cache", flag.ExitOnError)
+	explain := flag.NewFlagSet("explain", flag.ExitOnError)
+	inspect := flag.NewFlagSet("inspect", flag.ExitOnError)
+	rules := flag.NewFlagSet("rules", flag.ExitOnError)
+	rules.StringVar(&Args.Rules.Class, "class", "", "window class (WM_CLASS)")
+	rules.StringVar(&Args.Rules.Name, "name", "", "window title (WM_NAME)")
+	rules.StringVar(&Args.Rules.Process, "process", "", "process name")
+	rules.StringVar(&Args.Rules.Cmdline, "cmdline", "", "process command line")
+	rules.StringVar(&Args.Rules.Type, "type", "", "window type (e.g. _NET_WM_WINDOW_TYPE_DIALOG)")
+	rules.StringVar(&Args.Rules.State, "state", "", "window state (e.g. _NET_WM_STATE_ABOVE)")
+	rules.IntVar(&Args.Rules.Desktop, "desktop", 1, "desktop number of workspace scoped rules")
+	rules.IntVar(&Args.Rules.Screen, "screen", 0, "screen index of workspace scoped rules")
+	focused := explain.Bool("focused", false, "explain the focused window")
+
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
+		case "list":
+
+			// Subcommand line usage text
+			list.Usage = func() {
+				fmt.Fprintf(list.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(list.Output(), "  %s list windows|workspaces|layouts\n\tprint tab separated lines for dmenu, rofi or fzf\n", Build.Name)
+				list.PrintDefaults()
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(list, os.Args[2:])
+			Args.List.P = list.Args()
+
+			// Check subcommand line arguments
+			if len(Args.List.P) != 1 || !IsInList(Args.List.P[0], []string{"windows", "workspaces", "layouts"}) || !IsInList(Args.List.Format, []string{"tsv", "json"}) {
+				list.Usage()
+				os.Exit(2)
+			}
+		case "focus":
+
+			// Subcommand line usage text
+			focus.Usage = func() {
+				fmt.Fprintf(focus.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(focus.Output(), "  %s focus <id>\n\tactivate the window with the id from '%s list windows'\n", Build.Name, Build.Name)
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(focus, os.Args[2:])
+
+			// Check subcommand line arguments
+			if focus.NArg() != 1 {
+				focus.Usage()
+				os.Exit(2)
+			}
+			Args.Focus = focus.Arg(0)
+		case "layout":
+
+			// Subcommand line usage text
+			layout.Usage = func() {
+				fmt.Fprintf(layout.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(layout.Output(), "  %s layout <name>\n\tactivate the layout with the name from '%s list layouts'\n", Build.Name, Build.Name)
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(layout, os.Args[2:])
+
+			// Check subcommand line arguments
+			if layout.NArg() != 1 {
+				layout.Usage()
+				os.Exit(2)
+			}
+			Args.Layout = layout.Arg(0)
+		case "doctor":
+
+			// Subcommand line usage text
+			doctor.Usage = func() {
+				fmt.Fprintf(doctor.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(doctor.Output(), "  %s doctor\n\tcheck window manager, displays, keybindings, cache and config\n", Build.Name)
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(doctor, os.Args[2:])
+			Args.Doctor = true
+		case "check-config":
+
+			// Subcommand line usage text
+			check.Usage = func() {
+				fmt.Fprintf(check.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(check.Output(), "  %s check-config\n\tvalidate the config file and print line-numbered errors\n", Build.Name)
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(check, os.Args[2:])
+			Args.Check = true
+		case "cache":
+
+			// Subcommand line usage text
+			cache.Usage = func() {
+				fmt.Fprintf(cache.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(cache.Output(), "  %s cache gc|clear|stats\n\tprune expired entries, remove all entries or print cache statistics\n", Build.Name)
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(cache, os.Args[2:])
+
+			// Check subcommand line arguments
+			if cache.NArg() != 1 || !IsInList(cache.Arg(0), []string{"gc", "clear", "stats"}) {
+				cache.Usage()
+				os.Exit(2)
+			}
+			Args.CacheCmd = cache.Arg(0)
+		case "explain":
+
+			// Subcommand line usage text
+			explain.Usage = func() {
+				fmt.Fprintf(explain.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(explain.Output(), "  %s explain <id>|-focused\n\tprint the tiling decision chain of the window with the id from '%s list windows'\n", Build.Name, Build.Name)
+				explain.PrintDefaults()
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(explain, os.Args[2:])
+
+			// Check subcommand line arguments
+			if *focused == (explain.NArg() == 1) || explain.NArg() > 1 {
+				explain.Usage()
+				os.Exit(2)
+			}
+			Args.Explain = "0"
+			if !*focused {
+				Args.Explain = explain.Arg(0)
+			}
+		case "inspect":
+
+			// Subcommand line usage text
+			inspect.Usage = func() {
+				fmt.Fprintf(inspect.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(inspect.Output(), "  %s inspect\n\tclick a window and print its properties and tiling decision as json\n", Build.Name)
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(inspect, os.Args[2:])
+			Args.Inspect = true
+		case "rules":
+
+			// Subcommand line usage text
+			rules.Usage = func() {
+				fmt.Fprintf(rules.Output(), "%s\n\nUsage:\n", Build.Summary)
+				fmt.Fprintf(rules.Output(), "  %s rules test -class <class> [-name <name>]\n\tevaluate the configured window rules against the given values\n", Build.Name)
+				rules.PrintDefaults()
+			}
+
+			// Parse subcommand line arguments
+			FlagParse(rules, os.Args[2:])
+
+			// Check subcommand line arguments
+			if rules.NArg() != 1 || rules.Arg(0) != "test" || len(Args.Rules.Class) == 0 || Args.Rules.Desktop < 1 || Args.Rules.Screen < 0 {
+				rules.Usage()
+				os.Exit(2)
+			}
+			Args.Rules.Test = true
 		case "dbus":
 
 			// Subcommand line usage text
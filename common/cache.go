@@ -1,22 +1,49 @@
 package common
 
 import (
+	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"io/fs"
 	"path/filepath"
 
+	bolt "go.etcd.io/bbolt"
+
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	Database *bolt.DB // Cache database
+)
+
+var (
+	cacheTouched map[string]int64 = make(map[string]int64)          // Pending access times of cache entries
+	cacheMutex   sync.Mutex                                         // Mutex for pending access times
+	cacheBucket  string           = "touched"                       // Bucket of cache entry access times
+	cacheLegacy  []string         = []string{"workplaces", "infos"} // Folders of legacy cache files
+)
+
+type CacheStats struct {
+	Buckets int       // Number of cache buckets
+	Entries int       // Number of cache entries
+	Size    int       // Size of cache entries in bytes
+	File    int64     // Size of cache database in bytes
+	Oldest  time.Time // Access time of oldest cache entry
+}
+
 type Cache[T any] struct {
-	Folder string // Cache file folder
-	Name   string // Cache file name
-	Data   T      // Cache file data
+	Bucket string // Cache database bucket
+	Key    string // Cache database key
+	Data   T      // Cache data
 }
 
 func InitCache() {
-	if HasFlag("disable-cache-folder") {
+	if HasFlag("disable-cache-folder") || Args.DryRun {
 		Args.Cache = "disabled"
 	}
 	if CacheDisabled() {
@@ -28,6 +55,18 @@ func InitCache() {
 	if _, err := os.Stat(cacheFolderPath); os.IsNotExist(err) {
 		os.MkdirAll(cacheFolderPath, 0755)
 	}
+
+	// Open cache database
+	db, err := bolt.Open(filepath.Join(cacheFolderPath, "cache.db"), 0644, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		log.Warn("Error opening cache database: ", err)
+		Args.Cache = "disabled"
+		return
+	}
+	Database = db
+
+	// Migrate legacy cache files
+	migrateCache()
 }
 
 func CacheFolderPath(name string) string {
@@ -45,3 +84,297 @@ func CacheDisabled() bool {
 	arg := strings.ToLower(strings.TrimSpace(Args.Cache))
 	return IsInList(arg, []string{"", "0", "off", "false", "disabled"})
 }
+
+func (c Cache[T]) Exists() bool {
+	_, err := c.Read()
+	return err == nil
+}
+
+func (c Cache[T]) Write(data []byte) error {
+	if Database == nil {
+		return os.ErrInvalid
+	}
+
+	// Write data into bucket
+	return Database.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(c.Bucket))
+		if err != nil {
+			return err
+		}
+		if err := touchEntry(tx, cacheEntry(c.Bucket, c.Key), time.Now().Unix()); err != nil {
+			return err
+		}
+		return bucket.Put([]byte(c.Key), data)
+	})
+}
+
+func (c Cache[T]) Read() ([]byte, error) {
+	if Database == nil {
+		return nil, os.ErrInvalid
+	}
+
+	// Read data from bucket
+	var data []byte
+	Database.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(c.Bucket))
+		if bucket == nil {
+			return nil
+		}
+		if value := bucket.Get([]byte(c.Key)); value != nil {
+			data = append([]byte{}, value...)
+		}
+		return nil
+	})
+	if data != nil {
+		cacheMutex.Lock()
+		cacheTouched[cacheEntry(c.Bucket, c.Key)] = time.Now().Unix()
+		cacheMutex.Unlock()
+		return data, nil
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func migrateCache() {
+	migrated := 0
+
+	// Migrate legacy workplace files into buckets
+	filepath.WalkDir(filepath.Join(Args.Cache, cacheLegacy[0]), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		folder, err := filepath.Rel(Args.Cache, filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+		bucket, subfolder := filepath.Dir(folder), filepath.Base(folder)
+		hash := strings.TrimSuffix(filepath.Base(path), ".json")
+
+		// Recover cache key (e.g. class-desktop) from hashed file name
+		for index := 0; index < 64; index++ {
+			key := fmt.Sprintf("%s-%d", subfolder, index)
+			if HashString(key, 20) != hash {
+				continue
+			}
+			cache := Cache[[]byte]{Bucket: bucket, Key: key}
+			if data, err := os.ReadFile(path); err == nil && !cache.Exists() && cache.Write(data) == nil {
+				migrated += 1
+			}
+			break
+		}
+
+		return nil
+	})
+
+	// Remove legacy cache folders (infos are regenerated)
+	removed := false
+	for _, name := range cacheLegacy {
+		folder := filepath.Join(Args.Cache, name)
+		if _, err := os.Stat(folder); err == nil {
+			removed = os.RemoveAll(folder) == nil || removed
+		}
+	}
+	if removed {
+		log.Info("Migrate ", migrated, " legacy cache files [", Args.Cache, "]")
+	}
+}
+
+func PruneCache() (int, error) {
+	expiry := time.Duration(Config.CacheExpiry) * 24 * time.Hour
+	limit := Config.CacheSize * 1024 * 1024
+
+	// Remove expired and oversized cache entries
+	removed, err := CacheGC(expiry, limit)
+	if err != nil {
+		log.Warn("Error pruning cache: ", err)
+		return removed, err
+	}
+	if removed > 0 {
+		log.Info("Prune ", removed, " cache entries")
+	}
+
+	return removed, nil
+}
+
+func CacheGC(expiry time.Duration, limit int) (int, error) {
+	if Database == nil {
+		return 0, os.ErrInvalid
+	}
+	removed := 0
+
+	// Obtain pending access times
+	cacheMutex.Lock()
+	touched := cacheTouched
+	cacheTouched = make(map[string]int64)
+	cacheMutex.Unlock()
+
+	err := Database.Update(func(tx *bolt.Tx) error {
+		now := time.Now().Unix()
+
+		// Write pending access times
+		times, err := tx.CreateBucketIfNotExists([]byte(cacheBucket))
+		if err != nil {
+			return err
+		}
+		for entry, t := range touched {
+			if err := touchEntry(tx, entry, t); err != nil {
+				return err
+			}
+		}
+
+		// Collect cache entries with access times
+		type item struct {
+			bucket []byte
+			key    []byte
+			size   int
+			time   int64
+		}
+		items, size := []item{}, 0
+		err = tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			if string(name) == cacheBucket {
+				return nil
+			}
+			return bucket.ForEach(func(k []byte, v []byte) error {
+				t, ok := entryTime(times, name, k)
+				if !ok {
+					t = now
+				}
+				items = append(items, item{bucket: append([]byte{}, name...), key: append([]byte{}, k...), size: len(k) + len(v), time: t})
+				size += len(k) + len(v)
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		// Write access times of untouched entries
+		for _, it := range items {
+			if _, ok := entryTime(times, it.bucket, it.key); !ok {
+				if err := touchEntry(tx, cacheEntry(string(it.bucket), string(it.key)), now); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Remove least recently used entries
+		sort.Slice(items, func(i, j int) bool {
+			return items[i].time < items[j].time
+		})
+		for _, it := range items {
+			expired := expiry > 0 && now-it.time > int64(expiry.Seconds())
+			oversized := limit > 0 && size > limit
+			if !expired && !oversized {
+				break
+			}
+			if err := removeEntry(tx, it.bucket, it.key); err != nil {
+				return err
+			}
+			size -= it.size
+			removed++
+		}
+
+		return nil
+	})
+
+	return removed, err
+}
+
+func CacheClear() error {
+	if Database == nil {
+		return os.ErrInvalid
+	}
+
+	// Remove all buckets
+	return Database.Update(func(tx *bolt.Tx) error {
+		names := [][]byte{}
+		tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			names = append(names, append([]byte{}, name...))
+			return nil
+		})
+		for _, name := range names {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func CacheStatsGet() (CacheStats, error) {
+	stats := CacheStats{}
+	if Database == nil {
+		return stats, os.ErrInvalid
+	}
+
+	// Collect bucket and entry sizes
+	err := Database.View(func(tx *bolt.Tx) error {
+		stats.File = tx.Size()
+		times := tx.Bucket([]byte(cacheBucket))
+		return tx.ForEach(func(name []byte, bucket *bolt.Bucket) error {
+			if string(name) == cacheBucket {
+				return nil
+			}
+			stats.Buckets++
+			return bucket.ForEach(func(k []byte, v []byte) error {
+				stats.Entries++
+				stats.Size += len(k) + len(v)
+				if t, ok := entryTime(times, name, k); ok {
+					if oldest := time.Unix(t, 0); stats.Oldest.IsZero() || oldest.Before(stats.Oldest) {
+						stats.Oldest = oldest
+					}
+				}
+				return nil
+			})
+		})
+	})
+
+	return stats, err
+}
+
+func touchEntry(tx *bolt.Tx, entry string, t int64) error {
+	times, err := tx.CreateBucketIfNotExists([]byte(cacheBucket))
+	if err != nil {
+		return err
+	}
+
+	// Write access time of cache entry
+	return times.Put([]byte(entry), []byte(strconv.FormatInt(t, 10)))
+}
+
+func entryTime(times *bolt.Bucket, name []byte, key []byte) (int64, bool) {
+	if times == nil {
+		return 0, false
+	}
+
+	// Read access time of cache entry
+	t, err := strconv.ParseInt(string(times.Get([]byte(cacheEntry(string(name), string(key))))), 10, 64)
+
+	return t, err == nil
+}
+
+func removeEntry(tx *bolt.Tx, name []byte, key []byte) error {
+	bucket := tx.Bucket(name)
+	if bucket == nil {
+		return nil
+	}
+
+	// Remove cache entry and access time
+	if err := bucket.Delete(key); err != nil {
+		return err
+	}
+	if times := tx.Bucket([]byte(cacheBucket)); times != nil {
+		times.Delete([]byte(cacheEntry(string(name), string(key))))
+	}
+
+	// Remove empty bucket
+	if k, _ := bucket.Cursor().First(); k == nil {
+		return tx.DeleteBucket(name)
+	}
+
+	return nil
+}
+
+func cacheEntry(bucket string, key string) string {
+	return bucket + "\x00" + key
+}
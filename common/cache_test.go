@@ -0,0 +1,48 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestMigrateCache(t *testing.T) {
+	cache, database := Args.Cache, Database
+	t.Cleanup(func() {
+		Database.Close()
+		Args.Cache, Database = cache, database
+	})
+
+	Args.Cache = t.TempDir()
+	db, err := bolt.Open(filepath.Join(Args.Cache, "cache.db"), 0644, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	Database = db
+
+	// Write legacy client and info files
+	folder := filepath.Join("workplaces", "eDP-1", "clients")
+	legacy := filepath.Join(Args.Cache, folder, "kitty", HashString("kitty-2", 20)+".json")
+	info := filepath.Join(Args.Cache, "infos", "client", HashString("client-kitty-1", 20)+".json")
+	for _, path := range []string{legacy, info} {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(`{"Class":"kitty"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrateCache()
+
+	data, err := Cache[[]byte]{Bucket: folder, Key: "kitty-2"}.Read()
+	if err != nil || string(data) != `{"Class":"kitty"}` {
+		t.Errorf("Read() of migrated entry = %q, %v", data, err)
+	}
+	for _, name := range cacheLegacy {
+		if _, err := os.Stat(filepath.Join(Args.Cache, name)); !os.IsNotExist(err) {
+			t.Errorf("migrateCache() kept legacy folder %s", name)
+		}
+	}
+}
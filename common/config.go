@@ -2,7 +2,17 @@ package common
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bytes"
+	"reflect"
 
 	"encoding/json"
 	"path/filepath"
@@ -15,33 +25,272 @@ import (
 )
 
 var (
-	Config Configuration // Decoded config values
+	Config   Configuration            // Decoded config values
+	Revision int                      // Number of config value changes
+	Profile  string                   // Active config profile
+	profiles map[string]configProfile // Decoded config profiles
+)
+
+var (
+	configOverrides    string   = "overrides.toml" // Config fragment of persisted runtime values
+	configCallbacksFun []func()                    // Config file change callback functions
+)
+
+var (
+	ConfigOptions = []string{ // Config values changeable at runtime
+		"tiling_gui",
+		"gui_indicator",
+		"gui_announce",
+		"gui_large_print",
+		"window_ignore",
+		"window_ignore_title",
+		"window_master_class",
+		"window_slave_class",
+		"window_insert",
+		"window_overflow",
+		"window_tiled_max",
+		"window_above",
+		"window_gap_size",
+		"window_gap_outer",
+		"window_gap_step",
+		"window_focus_delay",
+		"window_focus_master",
+		"window_decoration",
+		"window_auto_decor",
+		"window_buttons",
+		"window_placeholder",
+		"window_preplace",
+		"window_animation",
+		"window_sync_timeout",
+		"window_extents",
+		"window_opacity",
+		"window_opaque_class",
+		"proportion_step",
+		"proportion_min",
+		"resize_modifier",
+		"resize_button",
+		"resize_edges",
+		"edge_corner_delay",
+		"idle_timeout",
+		"idle_defer_tiling",
+		"tiling_desktop_auto",
+	}
 )
 
 type Configuration struct {
-	CacheWorkspaces   bool              `toml:"cache_workspaces"`    // Cache workspace properties (Tiling enablement, Current layout, proportions)
-	CacheWindows      bool              `toml:"cache_windows"`       // Cache window properties ( Positions, Dimensions)
-	TilingEnabled     bool              `toml:"tiling_enabled"`      // Tile windows on startup
-	TilingLayout      string            `toml:"tiling_layout"`       // Initial tiling layout
-	TilingCycle       []string          `toml:"tiling_cycle"`        // Cycle layout order
-	TilingGui         int               `toml:"tiling_gui"`          // Time duration of gui
-	TilingIcon        [][]string        `toml:"tiling_icon"`         // Menu entries of systray
-	WindowIgnore      [][]string        `toml:"window_ignore"`       // Regex to ignore windows
-	WindowMastersMax  int               `toml:"window_masters_max"`  // Maximum number of allowed masters
-	WindowSlavesMax   int               `toml:"window_slaves_max"`   // Maximum number of allowed slaves
-	WindowGapSize     int               `toml:"window_gap_size"`     // Gap size between windows
-	WindowFocusDelay  int               `toml:"window_focus_delay"`  // Window focus delay when hovered
-	WindowDecoration  bool              `toml:"window_decoration"`   // Show window decorations
-	ProportionStep    float64           `toml:"proportion_step"`     // Master-slave area step size proportion
-	ProportionMin     float64           `toml:"proportion_min"`      // Window size minimum proportion
-	EdgeMargin        []int             `toml:"edge_margin"`         // Margin values of tiling area
-	EdgeMarginPrimary []int             `toml:"edge_margin_primary"` // Margin values of primary tiling area
-	EdgeCornerSize    int               `toml:"edge_corner_size"`    // Size of square defining edge corners
-	EdgeCenterSize    int               `toml:"edge_center_size"`    // Length of rectangle defining edge centers
-	Colors            map[string][]int  `toml:"colors"`              // List of color values for gui elements
-	Keys              map[string]string `toml:"keys"`                // Event bindings for keyboard shortcuts
-	Corners           map[string]string `toml:"corners"`             // Event bindings for hot-corner actions
-	Systray           map[string]string `toml:"systray"`             // Event bindings for systray icon
+	Include           []string                  `toml:"include"`             // Config fragments merged over this file
+	Profile           string                    `toml:"profile"`             // Initial config profile
+	CacheWorkspaces   bool                      `toml:"cache_workspaces"`    // Cache workspace properties (Tiling enablement, Current layout, proportions)
+	CacheWindows      bool                      `toml:"cache_windows"`       // Cache window properties ( Positions, Dimensions)
+	CacheExpiry       int                       `toml:"cache_expiry"`        // Days until untouched cache entries are pruned
+	CacheSize         int                       `toml:"cache_size"`          // Maximum size of cache entries in megabytes
+	TilingEnabled     bool                      `toml:"tiling_enabled"`      // Tile windows on startup
+	TilingLayout      string                    `toml:"tiling_layout"`       // Initial tiling layout
+	TilingCycle       []string                  `toml:"tiling_cycle"`        // Cycle layout order
+	TilingScreens     ScreenList                `toml:"tiling_screens"`      // Screen indices or output names managed by tiling
+	ScreenSplits      map[string][]float64      `toml:"screen_splits"`       // Width proportions of virtual screens per monitor
+	TilingDesktops    []int                     `toml:"tiling_desktops"`     // Desktop indices managed by tiling
+	TilingDesktopAuto bool                      `toml:"tiling_desktop_auto"` // Keep exactly one trailing empty desktop
+	TilingGui         int                       `toml:"tiling_gui"`          // Time duration of gui
+	GuiFontPath       string                    `toml:"gui_font_path"`       // Legacy font file path of gui text (superseded by theme)
+	GuiFontSize       int                       `toml:"gui_font_size"`       // Legacy font size of gui text (superseded by theme)
+	GuiSwitcherGlobal bool                      `toml:"gui_switcher_global"` // Switch windows of all workspaces
+	GuiChordOverlay   bool                      `toml:"gui_chord_overlay"`   // Show continuations of key chords
+	GuiChordTimeout   int                       `toml:"gui_chord_timeout"`   // Time duration of key chords
+	GuiIndicator      string                    `toml:"gui_indicator"`       // Screen corner of status indicator
+	GuiAnnounce       string                    `toml:"gui_announce"`        // Speech command for screen reader announcements
+	GuiLargePrint     bool                      `toml:"gui_large_print"`     // Large print and high contrast overlays
+	TilingIcon        [][]string                `toml:"tiling_icon"`         // Menu entries of systray
+	WindowIgnore      [][]string                `toml:"window_ignore"`       // Regex to ignore windows
+	WindowIgnoreTitle []string                  `toml:"window_ignore_title"` // Regex to ignore windows by title
+	WindowIgnoreType  []string                  `toml:"window_ignore_type"`  // Additional window types to ignore
+	WindowIgnoreState []string                  `toml:"window_ignore_state"` // Additional window states to ignore
+	WindowAbove       string                    `toml:"window_above"`        // Handling policy of always-on-top windows
+	WindowMaster      []string                  `toml:"window_master_class"` // Regex to always insert windows as master
+	WindowSlave       []string                  `toml:"window_slave_class"`  // Regex to always insert windows as last slave
+	WindowGroup       []string                  `toml:"window_group_class"`  // Regex to group windows into shared tiles
+	WindowGroupTabs   int                       `toml:"window_group_tabs"`   // Height of tab strips of grouped windows
+	WindowInsert      string                    `toml:"window_insert"`       // Insertion policy of new windows
+	WindowOverflow    string                    `toml:"window_overflow"`     // Handling policy of windows exceeding the layout
+	WindowMastersMax  int                       `toml:"window_masters_max"`  // Maximum number of allowed masters
+	WindowMasterStack bool                      `toml:"window_master_stack"` // Stack masters of horizontal layouts
+	WindowSlavesMax   int                       `toml:"window_slaves_max"`   // Maximum number of allowed slaves
+	WindowTiledMax    int                       `toml:"window_tiled_max"`    // Tiled windows per workspace until new windows move to an empty desktop
+	WindowGapSize     int                       `toml:"window_gap_size"`     // Gap size between windows
+	WindowGapOuter    int                       `toml:"window_gap_outer"`    // Gap size to the screen edges
+	WindowGapStep     int                       `toml:"window_gap_step"`     // Gap step size of gap actions
+	WindowFocusDelay  int                       `toml:"window_focus_delay"`  // Window focus delay when hovered
+	WindowFocusMaster int                       `toml:"window_focus_master"` // Slave focus duration until promoted to master
+	WindowDecoration  bool                      `toml:"window_decoration"`   // Show window decorations
+	WindowAutoDecor   bool                      `toml:"window_auto_decor"`   // Restore window decorations when untiled
+	WindowButtons     bool                      `toml:"window_buttons"`      // Show tiling buttons in title bars
+	WindowPlaceholder bool                      `toml:"window_placeholder"`  // Reserve tiles of autostarted windows
+	WindowPreplace    bool                      `toml:"window_preplace"`     // Move new windows to their tile before mapping
+	WindowAnimation   int                       `toml:"window_animation"`    // Duration of animated window movements
+	WindowSyncTimeout int                       `toml:"window_sync_timeout"` // Maximum wait time for clients to acknowledge resizes
+	WindowExtents     string                    `toml:"window_extents"`      // Extents correction profile of move/resize requests
+	WindowOpacity     float64                   `toml:"window_opacity"`      // Opacity of unfocused tiled windows
+	WindowOpaque      []string                  `toml:"window_opaque_class"` // Regex to exclude windows from opacity
+	WindowPipSize     []int                     `toml:"window_pip_size"`     // Size of picture-in-picture windows
+	WindowPipCorner   string                    `toml:"window_pip_corner"`   // Corner of picture-in-picture windows
+	WindowSpanScreens ScreenList                `toml:"window_span_screens"` // Screens spanned by fullscreen windows
+	ProportionStep    float64                   `toml:"proportion_step"`     // Master-slave area step size proportion
+	ProportionMin     float64                   `toml:"proportion_min"`      // Window size minimum proportion
+	ResizeModifier    string                    `toml:"resize_modifier"`     // Modifier keys required for proportion resize
+	ResizeButton      string                    `toml:"resize_button"`       // Pointer button required for proportion resize
+	ResizeEdges       []string                  `toml:"resize_edges"`        // Window edges allowed for proportion resize
+	EdgeMargin        []int                     `toml:"edge_margin"`         // Margin values of tiling area
+	EdgeMarginPrimary []int                     `toml:"edge_margin_primary"` // Margin values of primary tiling area
+	EdgeMarginScreens map[string][]int          `toml:"edge_margin_screens"` // Margin values of tiling area per screen
+	EdgeCornerSize    int                       `toml:"edge_corner_size"`    // Size of square defining edge corners
+	EdgeCenterSize    int                       `toml:"edge_center_size"`    // Length of rectangle defining edge centers
+	EdgeCornerDelay   int                       `toml:"edge_corner_delay"`   // Pointer dwell time before corner actions
+	IdleTimeout       int                       `toml:"idle_timeout"`        // Time without user input until session is idle
+	IdleDeferTiling   bool                      `toml:"idle_defer_tiling"`   // Defer retiles while session is idle
+	Colors            map[string][]int          `toml:"colors"`              // List of color values for gui elements
+	Theme             Theme                     `toml:"theme"`               // Appearance of gui elements
+	Keys              map[string]string         `toml:"keys"`                // Event bindings for keyboard shortcuts
+	Corners           map[string]string         `toml:"corners"`             // Event bindings for hot-corner actions
+	Systray           map[string]string         `toml:"systray"`             // Event bindings for systray icon
+	Gestures          map[string]string         `toml:"gestures"`            // Event bindings for touchpad gestures
+	Layouts           map[string]string         `toml:"layouts"`             // Initial tiling layouts per desktop
+	Limits            map[string]Limit          `toml:"limits"`              // Allowed masters and slaves per layout or desktop
+	Quirks            map[string]bool           `toml:"quirks"`              // Window manager quirks overriding detection
+	Dock              Dock                      `toml:"dock"`                // Actions on connecting an external monitor
+	Autostart         []Autostart               `toml:"autostart"`           // Applications launched at startup
+	Schedule          []Schedule                `toml:"schedule"`            // Layouts and profiles switched on a schedule
+	Profiles          map[string]toml.Primitive `toml:"profiles"`            // Named config profiles merged over config values
+}
+
+type Autostart struct {
+	Command string `toml:"command"` // Command line launched at startup
+	Class   string `toml:"class"`   // Regex to match windows by class instead of process
+	Desktop int    `toml:"desktop"` // Target desktop number (0 = current)
+	Screen  string `toml:"screen"`  // Target screen index or output name (empty = current)
+	Role    string `toml:"role"`    // Target stack of windows (master, slave)
+	Index   int    `toml:"index"`   // Target position within stack (starting at 1)
+}
+
+type Schedule struct {
+	Cron    string `toml:"cron"`    // Cron expression (minute hour day month weekday)
+	Desktop int    `toml:"desktop"` // Target desktop number (0 = current)
+	Profile string `toml:"profile"` // Config profile switched to
+	Layout  string `toml:"layout"`  // Tiling layout switched to on target desktop
+	Action  string `toml:"action"`  // Action string executed on target desktop
+}
+
+type Dock struct {
+	Internal        string   `toml:"internal"`         // Regex of internal laptop outputs
+	Profile         string   `toml:"profile"`          // Config profile switched to when docked
+	UndockedProfile string   `toml:"undocked_profile"` // Config profile switched to when undocked
+	Desktops        []int    `toml:"desktops"`         // Desktop numbers moved to the external monitor
+	Classes         []string `toml:"classes"`          // Regex of window classes moved to the external monitor
+	Actions         []string `toml:"actions"`          // Action strings executed when docked
+	UndockedActions []string `toml:"undocked_actions"` // Action strings executed when undocked
+}
+
+type ScreenList []string // Screen references by index or output name
+
+func (s *ScreenList) UnmarshalTOML(data interface{}) error {
+	values, ok := data.([]interface{})
+	if !ok {
+		return fmt.Errorf("expected list of screen indices or output names")
+	}
+
+	// Convert screen indices and output names
+	*s = ScreenList{}
+	for _, v := range values {
+		switch v := v.(type) {
+		case int64:
+			*s = append(*s, strconv.FormatInt(v, 10))
+		case string:
+			*s = append(*s, v)
+		default:
+			return fmt.Errorf("unexpected screen reference %v", v)
+		}
+	}
+
+	return nil
+}
+
+type WorkspaceScope struct {
+	Desktop int    // Desktop number (0 = all desktops)
+	Screen  string // Screen index or output name (empty = all screens)
+}
+
+func ParseWorkspaceScope(value string) ([]WorkspaceScope, error) {
+	scopes := []WorkspaceScope{}
+	if len(strings.TrimSpace(value)) == 0 {
+		return scopes, nil
+	}
+
+	// Parse comma separated desktop:screen references
+	for _, ref := range strings.Split(value, ",") {
+		desktop, screen, _ := strings.Cut(strings.TrimSpace(ref), ":")
+		scope := WorkspaceScope{}
+		if desktop != "*" {
+			n, err := strconv.Atoi(desktop)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("desktop %q must be a number starting at 1 or *", desktop)
+			}
+			scope.Desktop = n
+		}
+		if screen != "*" {
+			scope.Screen = screen
+		}
+		scopes = append(scopes, scope)
+	}
+
+	return scopes, nil
+}
+
+type Limit struct {
+	Masters *Allowed `toml:"masters_allowed"` // Maximum number of allowed masters
+	Slaves  *Allowed `toml:"slaves_allowed"`  // Maximum number of allowed slaves
+}
+
+type Allowed int // Number of allowed clients (-1 = unlimited)
+
+func (a *Allowed) UnmarshalTOML(data interface{}) error {
+
+	// Convert allowed numbers and unlimited values
+	switch v := data.(type) {
+	case int64:
+		*a = Allowed(v)
+	case string:
+		if v != "unlimited" {
+			return fmt.Errorf("unexpected value %q, expected a number or \"unlimited\"", v)
+		}
+		*a = -1
+	default:
+		return fmt.Errorf("unexpected value %v, expected a number or \"unlimited\"", v)
+	}
+
+	return nil
+}
+
+type ConfigState struct {
+	config   Configuration            // Decoded config values
+	profiles map[string]configProfile // Decoded config profiles
+}
+
+type configProfile struct {
+	meta toml.MetaData  // Metadata of config file
+	data toml.Primitive // Undecoded profile values
+}
+
+type ConfigError struct {
+	File    string // File path of config file
+	Line    int    // Line number of config file
+	Key     string // Key name of config value
+	Message string // Validation error message
+}
+
+func (e ConfigError) Error() string {
+	if len(e.Key) == 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", e.File, e.Line, e.Key, e.Message)
 }
 
 /*
@@ -49,8 +298,29 @@ Partially for backward compatibility, partially to stop the config file getting
 for the options which are not commonly needed/wanted, set their default values.
 */
 func SetConfigDefaults() {
-	Config.CacheWindows = true
-	Config.CacheWorkspaces = true
+	setConfigDefaults(&Config)
+}
+
+func setConfigDefaults(config *Configuration) {
+	config.CacheWindows = true
+	config.CacheWorkspaces = true
+	config.CacheExpiry = 90
+	config.CacheSize = 32
+	config.GuiChordOverlay = true
+	config.GuiChordTimeout = 2000
+	config.WindowGapOuter = -1
+	config.WindowGapStep = 5
+	config.WindowAbove = "ignore"
+	config.WindowOverflow = "stack"
+	config.WindowOpacity = 1.0
+	config.WindowPlaceholder = true
+	config.WindowGroupTabs = 20
+	config.WindowSyncTimeout = 100
+	config.WindowExtents = "heuristic"
+	config.Dock.Internal = "^(edp|lvds|dsi)"
+	config.WindowPipSize = []int{480, 270}
+	Config.WindowPipCorner = "bottom_right"
+	Config.ResizeEdges = []string{"top", "right", "bottom", "left"}
 }
 
 func InitConfig() {
@@ -67,7 +337,7 @@ func InitConfig() {
 	}
 
 	// Read config file into memory
-	readConfig(Args.Config, true)
+	readConfig(Args.Config)
 
 	// Config file system watcher
 	watchConfig(Args.Config)
@@ -84,40 +354,883 @@ func ConfigFolderPath(name string) string {
 	return filepath.Join(userConfigDir, name)
 }
 
-func readConfig(configFilePath string, initial bool) {
+func readConfig(configFilePath string) {
 
 	// Print runtime infos
-	if initial {
-		fmt.Print("BUILD")
-		if HasReleaseInfos() {
-			fmt.Printf(" [>>> %s v%s is available <<<]", Build.Name, Source.Releases[0].Name)
+	fmt.Print("BUILD")
+	if HasReleaseInfos() {
+		fmt.Printf(" [>>> %s v%s is available <<<]", Build.Name, Source.Releases[0].Name)
+	}
+	fmt.Printf(": \n  name: %s\n  target: %s\n  version: v%s-%s\n  date: %s\n  flags: %s\n\n", Build.Name, Build.Target, Build.Version, Build.Commit, Build.Date, Build.Flags)
+	fmt.Printf("FILES: \n  log: %s\n  lock: %s\n  cache: %s\n  config: %s\n", Args.Log, Args.Lock, Args.Cache, configFilePath)
+
+	// Decode config file into struct
+	_, err := DecodeConfig(configFilePath)
+
+	// Print included files
+	for _, fragment := range configFragments(configFilePath, Config.Include) {
+		fmt.Printf("  include: %s\n", fragment)
+	}
+	fmt.Println()
+	if err != nil {
+		log.Fatal("Error reading config file ", err)
+	}
+
+	// Print validation errors
+	for _, err := range ValidateConfig(configFilePath) {
+		log.Warn("Error validating config file ", err)
+	}
+
+	// Print shortcut infos
+	keys, _ := json.MarshalIndent(Config.Keys, "", "  ")
+	corners, _ := json.MarshalIndent(Config.Corners, "", "  ")
+	systray, _ := json.MarshalIndent(Config.Systray, "", "  ")
+	gestures, _ := json.MarshalIndent(Config.Gestures, "", "  ")
+
+	fmt.Printf("KEYS: %s\n", RemoveChars(string(keys), []string{"{", "}", "\"", ","}))
+	fmt.Printf("CORNERS: %s\n", RemoveChars(string(corners), []string{"{", "}", "\"", ","}))
+	fmt.Printf("SYSTRAY: %s\n", RemoveChars(string(systray), []string{"{", "}", "\"", ","}))
+	fmt.Printf("GESTURES: %s\n", RemoveChars(string(gestures), []string{"{", "}", "\"", ","}))
+}
+
+func DecodeConfig(configFilePath string) ([]string, error) {
+	state, undecoded, err := decodeConfig(configFilePath)
+	if err != nil {
+		return undecoded, err
+	}
+
+	return undecoded, state.Apply()
+}
+
+func LoadConfig(configFilePath string) (*ConfigState, error) {
+
+	// Decode config files into local state
+	state, _, err := decodeConfig(configFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep current config on validation errors
+	errs := ValidateConfig(configFilePath)
+	for _, err := range errs {
+		log.Warn("Error validating config file ", err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%d invalid config values in %s", len(errs), configFilePath)
+	}
+
+	return state, nil
+}
+
+func (s *ConfigState) Apply() error {
+	config := s.config
+
+	// Decode active profile over config values
+	profile := Profile
+	if len(profile) == 0 {
+		profile = config.Profile
+	}
+	if p, ok := s.profiles[profile]; ok {
+		err := p.meta.PrimitiveDecode(p.data, &config)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", profile, err)
 		}
-		fmt.Printf(": \n  name: %s\n  target: %s\n  version: v%s-%s\n  date: %s\n  flags: %s\n\n", Build.Name, Build.Target, Build.Version, Build.Commit, Build.Date, Build.Flags)
-		fmt.Printf("FILES: \n  log: %s\n  lock: %s\n  cache: %s\n  config: %s\n\n", Args.Log, Args.Lock, Args.Cache, configFilePath)
 	}
 
-	SetConfigDefaults()
+	// Replace current config values
+	Config = config
+	Profile = profile
+	profiles = s.profiles
+	Revision += 1
+
+	return nil
+}
+
+func OnConfigUpdate(fun func()) {
+	configCallbacksFun = append(configCallbacksFun, fun)
+}
+
+func configCallbacks() {
+	log.Info("Config file changed")
+
+	for _, fun := range configCallbacksFun {
+		fun()
+	}
+}
+
+func decodeConfig(configFilePath string) (*ConfigState, []string, error) {
+	state := &ConfigState{profiles: map[string]configProfile{}}
+	setConfigDefaults(&state.config)
+
+	// Decode config file or embedded defaults
+	var err error
+	var meta toml.MetaData
+	if _, err = os.Stat(configFilePath); os.IsNotExist(err) {
+		meta, err = toml.Decode(string(File.Toml), &state.config)
+	} else {
+		meta, err = toml.DecodeFile(configFilePath, &state.config)
+	}
+	if err != nil {
+		return nil, []string{}, err
+	}
+	undecoded := decodeProfiles(state.profiles, meta, state.config.Profiles)
+
+	// Decode config fragments over config file
+	includes := state.config.Include
+	for _, fragment := range configFragments(configFilePath, includes) {
+		meta, err = toml.DecodeFile(fragment, &state.config)
+		if err != nil {
+			return nil, undecoded, fmt.Errorf("%s: %w", fragment, err)
+		}
+		undecoded = append(undecoded, decodeProfiles(state.profiles, meta, state.config.Profiles)...)
+	}
+	state.config.Include = includes
+
+	return state, undecoded, nil
+}
+
+func ProfileNames() []string {
+	names := []string{"default"}
+
+	// Collect sorted profile names
+	keys := []string{}
+	for name := range profiles {
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+
+	return append(names, keys...)
+}
+
+func ProfileSet(name string) error {
+	if !IsInList(name, ProfileNames()) {
+		return fmt.Errorf("config profile %s not found (%s)", name, strings.Join(ProfileNames(), ", "))
+	}
+
+	// Decode config with profile
+	Profile = name
+	log.Info("Switch config profile to ", name)
+
+	_, err := DecodeConfig(Args.Config)
+	return err
+}
+
+func decodeProfiles(profiles map[string]configProfile, meta toml.MetaData, primitives map[string]toml.Primitive) []string {
+
+	// Store profiles and mark their keys as decoded
+	for name, data := range primitives {
+		var config Configuration
+		meta.PrimitiveDecode(data, &config)
+		profiles[name] = configProfile{meta: meta, data: data}
+	}
+
+	// Collect unknown keys
+	undecoded := []string{}
+	for _, key := range meta.Undecoded() {
+		undecoded = append(undecoded, key.String())
+	}
+
+	return undecoded
+}
+
+func ClientLimits(desktop uint, layout string) (int, int) {
+	masters, slaves := Config.WindowMastersMax, Config.WindowSlavesMax
+
+	// Override limits from least to most specific reference
+	for _, ref := range []string{fmt.Sprint(desktop + 1), layout, fmt.Sprintf("%d:%s", desktop+1, layout)} {
+		limit, ok := Config.Limits[ref]
+		if !ok {
+			continue
+		}
+		if limit.Masters != nil {
+			masters = int(*limit.Masters)
+		}
+		if limit.Slaves != nil {
+			slaves = int(*limit.Slaves)
+		}
+	}
+
+	return masters, slaves
+}
+
+func ConfigGet(name string) (interface{}, error) {
+	field, err := configField(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return field.Interface(), nil
+}
+
+func ConfigSet(name string, value string, persist bool) error {
+	field, err := configField(name)
+	if err != nil {
+		return err
+	}
+
+	// Decode value into config type
+	decoded := reflect.New(field.Type())
+	if err := json.Unmarshal([]byte(value), decoded.Interface()); err != nil {
+		return fmt.Errorf("invalid value for %s (%s)", name, err)
+	}
+
+	// Validate decoded value
+	var data bytes.Buffer
+	if err := toml.NewEncoder(&data).Encode(map[string]interface{}{name: decoded.Elem().Interface()}); err != nil {
+		return err
+	}
+	for _, err := range validateConfig(Args.Config, data.Bytes()) {
+		return fmt.Errorf("invalid value for %s (%s)", name, err.Message)
+	}
+
+	// Update config value
+	field.Set(decoded.Elem())
+	Revision += 1
+	log.Info("Update config value ", name, " to ", value)
+
+	// Write config value
+	if persist {
+		return writeConfig(Args.Config, name, decoded.Elem().Interface())
+	}
+
+	return nil
+}
+
+func IgnoreAdd(class string, name string, workspaces string, persist bool) error {
+	entries := [][]string{}
+	added := ignoreEntry(class, name, workspaces)
+	if _, err := ParseWorkspaceScope(workspaces); err != nil {
+		return fmt.Errorf("invalid workspaces %q (%s)", workspaces, err)
+	}
+
+	// Append ignore entry
+	for _, entry := range Config.WindowIgnore {
+		if slices.Equal(entry, added) {
+			return fmt.Errorf("ignore entry %q already exists", added)
+		}
+		entries = append(entries, entry)
+	}
+	entries = append(entries, added)
+
+	return setIgnore(entries, persist)
+}
+
+func IgnoreRemove(class string, name string, workspaces string, persist bool) error {
+	entries := [][]string{}
+	removed := ignoreEntry(class, name, workspaces)
+
+	// Remove ignore entry
+	for _, entry := range Config.WindowIgnore {
+		if slices.Equal(entry, removed) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == len(Config.WindowIgnore) {
+		return fmt.Errorf("ignore entry %q not found", removed)
+	}
+
+	return setIgnore(entries, persist)
+}
+
+func ignoreEntry(class string, name string, workspaces string) []string {
+	if len(workspaces) > 0 {
+		return []string{class, name, workspaces}
+	}
+	return []string{class, name}
+}
+
+func setIgnore(entries [][]string, persist bool) error {
+	value, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return ConfigSet("window_ignore", string(value), persist)
+}
+
+func configField(name string) (reflect.Value, error) {
+	if !IsInList(name, ConfigOptions) {
+		return reflect.Value{}, fmt.Errorf("config value %s is not changeable at runtime (%s)", name, strings.Join(ConfigOptions, ", "))
+	}
+
+	// Find field by toml tag
+	value := reflect.ValueOf(&Config).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).Tag.Get("toml") == name {
+			return value.Field(i), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("config value %s not found", name)
+}
+
+func writeConfig(configFilePath string, name string, value interface{}) error {
+	overridesFilePath := filepath.Join(filepath.Dir(configFilePath), "config.d", configOverrides)
+
+	// Decode existing override values
+	overrides := map[string]interface{}{}
+	if _, err := os.Stat(overridesFilePath); err == nil {
+		if _, err := toml.DecodeFile(overridesFilePath, &overrides); err != nil {
+			return fmt.Errorf("%s: %w", overridesFilePath, err)
+		}
+	}
+	overrides[name] = value
+
+	// Encode override values
+	var data bytes.Buffer
+	if err := toml.NewEncoder(&data).Encode(overrides); err != nil {
+		return err
+	}
+
+	// Write override fragment
+	if err := os.MkdirAll(filepath.Dir(overridesFilePath), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(overridesFilePath, data.Bytes(), 0644)
+}
+
+func ValidateConfig(configFilePath string) []ConfigError {
+	var config Configuration
+
+	// Read config file or embedded defaults
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		data = File.Toml
+	}
+	toml.Decode(string(data), &config)
+
+	// Validate config file and fragments
+	errs := validateConfig(configFilePath, data)
+	for _, fragment := range configFragments(configFilePath, config.Include) {
+		data, err := os.ReadFile(fragment)
+		if err != nil {
+			errs = append(errs, ConfigError{File: fragment, Message: err.Error()})
+			continue
+		}
+		errs = append(errs, validateConfig(fragment, data)...)
+	}
+
+	return errs
+}
+
+func validateConfig(configFilePath string, data []byte) []ConfigError {
+	var config Configuration
+	lines := configLines(string(data))
 
 	// Decode config file into struct
-	_, err := toml.DecodeFile(configFilePath, &Config)
+	meta, err := toml.Decode(string(data), &config)
 	if err != nil {
-		if initial {
-			log.Fatal("Error reading config file ", err)
-		} else {
-			log.Warn("Error updating config file ", err)
+		match := regexp.MustCompile(`line (\d+) \(last key "([^"]*)"\): (.*)`).FindStringSubmatch(err.Error())
+		if len(match) == 4 {
+			return []ConfigError{{File: configFilePath, Line: StringsToInts(match[1:2])[0], Key: match[2], Message: match[3]}}
 		}
+		return []ConfigError{{File: configFilePath, Message: err.Error()}}
 	}
 
-	// Print shortcut infos
-	if initial {
-		keys, _ := json.MarshalIndent(Config.Keys, "", "  ")
-		corners, _ := json.MarshalIndent(Config.Corners, "", "  ")
-		systray, _ := json.MarshalIndent(Config.Systray, "", "  ")
+	errs := []ConfigError{}
+	invalid := func(key string, format string, a ...interface{}) {
+		errs = append(errs, ConfigError{File: configFilePath, Line: lines[key], Key: key, Message: fmt.Sprintf(format, a...)})
+	}
+
+	// Validate profile values
+	for name, data := range config.Profiles {
+		var profile Configuration
+		if err := meta.PrimitiveDecode(data, &profile); err != nil {
+			invalid("profiles."+name, "%s", err)
+		}
+	}
+
+	// Validate key names
+	for _, key := range meta.Undecoded() {
+		invalid(key.String(), "unknown key")
+	}
+
+	// Validate layout names
+	layouts := []string{"vertical-left", "vertical-right", "horizontal-top", "horizontal-bottom", "maximized", "fullscreen"}
+	if meta.IsDefined("tiling_layout") && !IsInList(config.TilingLayout, layouts) {
+		invalid("tiling_layout", "unknown layout %q, expected one of %s", config.TilingLayout, strings.Join(layouts, ", "))
+	}
+	for _, layout := range config.TilingCycle {
+		if !IsInList(layout, layouts) {
+			invalid("tiling_cycle", "unknown layout %q, expected one of %s", layout, strings.Join(layouts, ", "))
+		}
+	}
+	for desktop, layout := range config.Layouts {
+		if n, err := strconv.Atoi(desktop); err != nil || n < 1 {
+			invalid("layouts."+desktop, "desktop %q must be a number starting at 1", desktop)
+		}
+		if !IsInList(layout, layouts) {
+			invalid("layouts."+desktop, "unknown layout %q, expected one of %s", layout, strings.Join(layouts, ", "))
+		}
+	}
+
+	// Validate client limits
+	for ref, limit := range config.Limits {
+		desktop, layout, found := strings.Cut(ref, ":")
+		if !found {
+			desktop, layout = "", ref
+			if _, err := strconv.Atoi(ref); err == nil {
+				desktop, layout = ref, ""
+			}
+		}
+		if n, err := strconv.Atoi(desktop); len(desktop) > 0 && (err != nil || n < 1) {
+			invalid("limits."+ref, "desktop %q must be a number starting at 1", desktop)
+		}
+		if len(layout) > 0 && !IsInList(layout, layouts) {
+			invalid("limits."+ref, "unknown layout %q, expected one of %s", layout, strings.Join(layouts, ", "))
+		}
+		if limit.Masters != nil && *limit.Masters < -1 {
+			invalid("limits."+ref, "masters_allowed %d is out of range (0 - n or \"unlimited\")", *limit.Masters)
+		}
+		if limit.Slaves != nil && (*limit.Slaves < -1 || *limit.Slaves == 0) {
+			invalid("limits."+ref, "slaves_allowed %d is out of range (1 - n or \"unlimited\")", *limit.Slaves)
+		}
+	}
+
+	// Validate window manager quirks
+	for name := range config.Quirks {
+		if _, ok := Quirks[name]; !ok {
+			invalid("quirks."+name, "unknown quirk %q, expected one of %s", name, strings.Join(QuirkNames(), ", "))
+		}
+	}
+
+	// Validate numeric ranges
+	ranges := []struct {
+		key   string
+		value float64
+		min   float64
+		max   float64
+	}{
+		{"cache_expiry", float64(config.CacheExpiry), 0, 3650},
+		{"cache_size", float64(config.CacheSize), 0, 1024},
+		{"tiling_gui", float64(config.TilingGui), 0, 1e9},
+		{"gui_font_size", float64(config.GuiFontSize), 8, 64},
+		{"theme.font_size", float64(config.Theme.FontSize), 8, 64},
+		{"gui_chord_timeout", float64(config.GuiChordTimeout), 100, 1e9},
+		{"window_masters_max", float64(config.WindowMastersMax), 0, 5},
+		{"window_slaves_max", float64(config.WindowSlavesMax), 1, 5},
+		{"window_tiled_max", float64(config.WindowTiledMax), 0, 100},
+		{"window_group_tabs", float64(config.WindowGroupTabs), 0, 100},
+		{"window_gap_size", float64(config.WindowGapSize), 0, 100},
+		{"window_gap_outer", float64(config.WindowGapOuter), -1, 100},
+		{"window_gap_step", float64(config.WindowGapStep), 1, 100},
+		{"window_focus_delay", float64(config.WindowFocusDelay), 0, 1e9},
+		{"window_focus_master", float64(config.WindowFocusMaster), 0, 1e9},
+		{"window_animation", float64(config.WindowAnimation), 0, 1000},
+		{"window_sync_timeout", float64(config.WindowSyncTimeout), 0, 1000},
+		{"window_opacity", config.WindowOpacity, 0.1, 1},
+		{"proportion_step", config.ProportionStep, 0, 1},
+		{"proportion_min", config.ProportionMin, 0, 1},
+		{"edge_corner_size", float64(config.EdgeCornerSize), 0, 100},
+		{"edge_center_size", float64(config.EdgeCenterSize), 0, 100},
+		{"edge_corner_delay", float64(config.EdgeCornerDelay), 0, 1e9},
+		{"idle_timeout", float64(config.IdleTimeout), 0, 86400},
+	}
+	for _, r := range ranges {
+		if meta.IsDefined(strings.Split(r.key, ".")...) && (r.value < r.min || r.value > r.max) {
+			invalid(r.key, "value %v is out of range (%v - %v)", r.value, r.min, r.max)
+		}
+	}
+
+	// Validate managed screens and desktops
+	for _, screen := range config.TilingScreens {
+		if i, err := strconv.Atoi(screen); err == nil && i < 0 {
+			invalid("tiling_screens", "index %d is out of range (0 - n)", i)
+		}
+	}
+	for _, i := range config.TilingDesktops {
+		if i < 0 {
+			invalid("tiling_desktops", "index %d is out of range (0 - n)", i)
+		}
+	}
+
+	// Validate virtual screen splits
+	for ref, proportions := range config.ScreenSplits {
+		sum, positive := 0.0, true
+		for _, p := range proportions {
+			sum += p
+			positive = positive && p > 0
+		}
+		if len(proportions) < 2 || len(proportions) > 3 {
+			invalid("screen_splits."+ref, "expected 2 or 3 proportions, got %d", len(proportions))
+		} else if !positive || math.Abs(sum-1) > 0.01 {
+			invalid("screen_splits."+ref, "proportions must be greater than 0 and sum up to 1")
+		}
+	}
+
+	// Validate font paths
+	for key, path := range map[string]string{"gui_font_path": config.GuiFontPath, "theme.font_path": config.Theme.FontPath} {
+		if len(path) > 0 {
+			if _, err := os.Stat(path); err != nil {
+				invalid(key, "font file can't be read (%s)", err)
+			}
+		}
+	}
+
+	// Validate margins
+	for key, margin := range map[string][]int{"edge_margin": config.EdgeMargin, "edge_margin_primary": config.EdgeMarginPrimary} {
+		if meta.IsDefined(key) && len(margin) != 4 {
+			invalid(key, "expected 4 values [top, right, bottom, left], got %d", len(margin))
+		}
+	}
+	for ref, margin := range config.EdgeMarginScreens {
+		if len(margin) != 4 {
+			invalid("edge_margin_screens", "screen %q expected 4 values [top, right, bottom, left], got %d", ref, len(margin))
+		}
+	}
+
+	// Validate insertion policy
+	policies := []string{"default", "master", "before", "after", "end"}
+	if meta.IsDefined("window_insert") && !IsInList(config.WindowInsert, policies) {
+		invalid("window_insert", "unknown policy %q, expected one of %s", config.WindowInsert, strings.Join(policies, ", "))
+	}
+
+	// Validate extents profile
+	extents := append([]string{"auto", "calibrated"}, ExtentsProfileNames()...)
+	if meta.IsDefined("window_extents") && !IsInList(config.WindowExtents, extents) {
+		invalid("window_extents", "unknown profile %q, expected one of %s", config.WindowExtents, strings.Join(extents, ", "))
+	}
+
+	// Validate overflow policy
+	overflows := []string{"stack", "minimize", "next", "shrink"}
+	if meta.IsDefined("window_overflow") && !IsInList(config.WindowOverflow, overflows) {
+		invalid("window_overflow", "unknown policy %q, expected one of %s", config.WindowOverflow, strings.Join(overflows, ", "))
+	}
+
+	// Validate always-on-top policy
+	above := []string{"ignore", "float", "tile"}
+	if meta.IsDefined("window_above") && !IsInList(config.WindowAbove, above) {
+		invalid("window_above", "unknown policy %q, expected one of %s", config.WindowAbove, strings.Join(above, ", "))
+	}
+
+	// Validate picture-in-picture values
+	if meta.IsDefined("window_pip_size") {
+		if len(config.WindowPipSize) != 2 {
+			invalid("window_pip_size", "expected 2 values [width, height], got %d", len(config.WindowPipSize))
+		} else if config.WindowPipSize[0] <= 0 || config.WindowPipSize[1] <= 0 {
+			invalid("window_pip_size", "values must be greater than 0")
+		}
+	}
+	corners := []string{"top_left", "top_right", "bottom_right", "bottom_left"}
+	if meta.IsDefined("window_pip_corner") && !IsInList(config.WindowPipCorner, corners) {
+		invalid("window_pip_corner", "unknown corner %q, expected one of %s", config.WindowPipCorner, strings.Join(corners, ", "))
+	}
+
+	// Validate announce command
+	if params := strings.Fields(config.GuiAnnounce); len(params) > 0 {
+		if _, err := exec.LookPath(params[0]); err != nil {
+			invalid("gui_announce", "command %q not found (%s)", params[0], err)
+		}
+	}
+
+	// Validate status indicator corner
+	if len(config.GuiIndicator) > 0 && !IsInList(config.GuiIndicator, corners) {
+		invalid("gui_indicator", "unknown corner %q, expected one of %s", config.GuiIndicator, strings.Join(corners, ", "))
+	}
+
+	// Validate systray menu entries
+	for i, entry := range config.TilingIcon {
+		if len(entry) != 2 {
+			invalid("tiling_icon", "entry %d must have 2 values [action, text], got %d", i+1, len(entry))
+		}
+	}
+
+	// Validate proportion resize pointer states
+	buttons := []string{"", "left", "middle", "right"}
+	if meta.IsDefined("resize_button") && !IsInList(config.ResizeButton, buttons) {
+		invalid("resize_button", "unknown button %q, expected one of %s", config.ResizeButton, strings.Join(buttons[1:], ", "))
+	}
+	for _, modifier := range strings.Split(config.ResizeModifier, "-") {
+		if len(config.ResizeModifier) > 0 && !IsInList(strings.ToLower(modifier), []string{"shift", "lock", "control", "mod1", "mod2", "mod3", "mod4", "mod5"}) {
+			invalid("resize_modifier", "unknown modifier %q", modifier)
+		}
+	}
+	for _, edge := range config.ResizeEdges {
+		if !IsInList(edge, []string{"top", "right", "bottom", "left"}) {
+			invalid("resize_edges", "unknown edge %q, expected one of top, right, bottom, left", edge)
+		}
+	}
+
+	// Validate gesture names
+	gestures := []string{"pinch_in", "pinch_out"}
+	for _, fingers := range []string{"3", "4"} {
+		for _, direction := range []string{"left", "right", "up", "down"} {
+			gestures = append(gestures, "swipe_"+fingers+"_"+direction)
+		}
+	}
+	for name := range config.Gestures {
+		if !IsInList(name, gestures) {
+			invalid("gestures."+name, "unknown gesture %q, expected one of %s", name, strings.Join(gestures, ", "))
+		}
+	}
+
+	// Validate autostart entries
+	for i, entry := range config.Autostart {
+		if len(strings.TrimSpace(entry.Command)) == 0 {
+			invalid("autostart", "entry %d has an empty command", i+1)
+		}
+		if _, err := regexp.Compile(strings.ToLower(entry.Class)); err != nil {
+			invalid("autostart", "entry %d has invalid regex %q (%s)", i+1, entry.Class, err)
+		}
+		if entry.Desktop < 0 || entry.Index < 0 {
+			invalid("autostart", "entry %d has negative desktop or index", i+1)
+		}
+		if !IsInList(entry.Role, []string{"", "master", "slave"}) {
+			invalid("autostart", "entry %d has unknown role %q, expected one of master, slave", i+1, entry.Role)
+		}
+	}
+
+	// Validate schedule entries
+	for i, entry := range config.Schedule {
+		if _, err := ParseCron(entry.Cron); err != nil {
+			invalid("schedule", "entry %d has invalid cron %q (%s)", i+1, entry.Cron, err)
+		}
+		if entry.Desktop < 0 {
+			invalid("schedule", "entry %d has negative desktop", i+1)
+		}
+		if len(entry.Layout) > 0 && !IsInList(entry.Layout, layouts) {
+			invalid("schedule", "entry %d has unknown layout %q, expected one of %s", i+1, entry.Layout, strings.Join(layouts, ", "))
+		}
+		if _, ok := config.Profiles[entry.Profile]; len(entry.Profile) > 0 && entry.Profile != "default" && !ok {
+			invalid("schedule", "entry %d has unknown profile %q", i+1, entry.Profile)
+		}
+		if len(entry.Profile) == 0 && len(entry.Layout) == 0 && len(entry.Action) == 0 {
+			invalid("schedule", "entry %d has no profile, layout or action", i+1)
+		}
+	}
+
+	// Validate dock values
+	if _, err := regexp.Compile(strings.ToLower(config.Dock.Internal)); err != nil {
+		invalid("dock.internal", "invalid regex %q (%s)", config.Dock.Internal, err)
+	}
+	for key, profile := range map[string]string{"dock.profile": config.Dock.Profile, "dock.undocked_profile": config.Dock.UndockedProfile} {
+		if _, ok := config.Profiles[profile]; len(profile) > 0 && profile != "default" && !ok {
+			invalid(key, "unknown profile %q", profile)
+		}
+	}
+	for _, desktop := range config.Dock.Desktops {
+		if desktop < 1 {
+			invalid("dock.desktops", "desktop %d must be a number starting at 1", desktop)
+		}
+	}
+	for i, expr := range config.Dock.Classes {
+		if _, err := regexp.Compile(strings.ToLower(expr)); err != nil {
+			invalid("dock.classes", "entry %d has invalid regex %q (%s)", i+1, expr, err)
+		}
+	}
+
+	// Validate window ignore regexes
+	for i, entry := range config.WindowIgnore {
+		if len(entry) != 2 && len(entry) != 3 {
+			invalid("window_ignore", "entry %d must have 2 or 3 values [class, name, workspaces], got %d", i+1, len(entry))
+			continue
+		}
+		for _, expr := range entry[:2] {
+			if _, err := regexp.Compile(strings.ToLower(expr)); err != nil {
+				invalid("window_ignore", "entry %d has invalid regex %q (%s)", i+1, expr, err)
+			}
+		}
+		if len(entry) == 3 {
+			if _, err := ParseWorkspaceScope(entry[2]); err != nil {
+				invalid("window_ignore", "entry %d has invalid workspaces %q (%s)", i+1, entry[2], err)
+			}
+		}
+	}
+
+	// Validate window types and states
+	for _, entry := range config.WindowIgnoreType {
+		if !strings.HasPrefix(entry, "_NET_WM_WINDOW_TYPE_") {
+			invalid("window_ignore_type", "entry %q must start with _NET_WM_WINDOW_TYPE_", entry)
+		}
+	}
+	for _, entry := range config.WindowIgnoreState {
+		if !strings.HasPrefix(entry, "_NET_WM_STATE_") {
+			invalid("window_ignore_state", "entry %q must start with _NET_WM_STATE_", entry)
+		}
+	}
+
+	// Validate window title and placement regexes
+	for key, entries := range map[string][]string{"window_ignore_title": config.WindowIgnoreTitle, "window_master_class": config.WindowMaster, "window_slave_class": config.WindowSlave, "window_group_class": config.WindowGroup, "window_opaque_class": config.WindowOpaque} {
+		for i, expr := range entries {
+			if _, err := regexp.Compile(strings.ToLower(expr)); err != nil {
+				invalid(key, "entry %d has invalid regex %q (%s)", i+1, expr, err)
+			}
+		}
+	}
 
-		fmt.Printf("KEYS: %s\n", RemoveChars(string(keys), []string{"{", "}", "\"", ","}))
-		fmt.Printf("CORNERS: %s\n", RemoveChars(string(corners), []string{"{", "}", "\"", ","}))
-		fmt.Printf("SYSTRAY: %s\n", RemoveChars(string(systray), []string{"{", "}", "\"", ","}))
+	// Validate theme values
+	if meta.IsDefined("theme", "preset") && !IsInList(config.Theme.Preset, ThemePresetNames()) {
+		invalid("theme.preset", "unknown preset %q, expected one of %s", config.Theme.Preset, strings.Join(ThemePresetNames(), ", "))
+	}
+	if r := config.Theme.Radius; r != nil && (*r < 0 || *r > 64) {
+		invalid("theme.radius", "value %v is out of range (%v - %v)", *r, 0, 64)
+	}
+	if o := config.Theme.Opacity; o != nil && (*o < 0.1 || *o > 1) {
+		invalid("theme.opacity", "value %v is out of range (%v - %v)", *o, 0.1, 1)
+	}
+	if p := config.Theme.Padding; p != nil && (*p < 0 || *p > 64) {
+		invalid("theme.padding", "value %v is out of range (%v - %v)", *p, 0, 64)
+	}
+
+	// Validate color values
+	colors := map[string][]int{}
+	for name, color := range config.Colors {
+		colors["colors."+name] = color
+	}
+	for name, color := range map[string][]int{"text": config.Theme.Text, "background": config.Theme.Background, "client_slave": config.Theme.ClientSlave, "client_master": config.Theme.ClientMaster} {
+		if color != nil {
+			colors["theme."+name] = color
+		}
+	}
+	for key, color := range colors {
+		if len(color) != 4 {
+			invalid(key, "expected 4 values [r, g, b, a], got %d", len(color))
+			continue
+		}
+		for _, c := range color {
+			if c < 0 || c > 255 {
+				invalid(key, "value %d is out of range (0 - 255)", c)
+				break
+			}
+		}
 	}
+
+	// Validate keybinding syntax
+	modifiers := []string{"shift", "lock", "control", "mod1", "mod2", "mod3", "mod4", "mod5", "any"}
+	for action, binding := range config.Keys {
+		key := "keys." + action
+		if len(strings.TrimSpace(binding)) == 0 {
+			continue
+		}
+		for _, name := range SplitChain(action) {
+			if len(name) == 0 {
+				invalid(key, "action chain %q contains an empty action", action)
+				break
+			}
+			if strings.HasPrefix(name, "exec:") && len(strings.TrimSpace(name[5:])) == 0 {
+				invalid(key, "command of %q is empty", name)
+				break
+			}
+		}
+		chord := strings.Fields(binding)
+		if len(chord) > 2 || (len(chord) == 2 && strings.HasPrefix(action, "mod_")) {
+			invalid(key, "binding %q must be a single key chord or a prefix followed by a continuation key", binding)
+			continue
+		}
+		if len(chord) == 2 && strings.Contains(chord[1], "-") {
+			invalid(key, "continuation %q of binding %q must be a plain key symbol", chord[1], binding)
+			continue
+		}
+		parts := strings.Split(chord[0], "-")
+		for i, part := range parts {
+			modifier := IsInList(strings.ToLower(part), modifiers)
+			if len(part) == 0 {
+				invalid(key, "binding %q contains an empty key", binding)
+				break
+			}
+			if strings.HasPrefix(action, "mod_") && !modifier {
+				invalid(key, "binding %q contains %q, which is not a modifier (%s)", binding, part, strings.Join(modifiers, ", "))
+				break
+			}
+			if !strings.HasPrefix(action, "mod_") && modifier == (i == len(parts)-1) {
+				invalid(key, "binding %q must be modifiers followed by a single key symbol", binding)
+				break
+			}
+		}
+	}
+
+	// Sort by line number
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Line < errs[j].Line
+	})
+
+	return errs
+}
+
+func configFragments(configFilePath string, includes []string) []string {
+	folder := filepath.Dir(configFilePath)
+	fragments := []string{}
+
+	// Include fragments from config key
+	patterns := []string{}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(folder, include)
+		}
+		patterns = append(patterns, include)
+	}
+
+	// Include fragments from config folder
+	patterns = append(patterns, filepath.Join(folder, "config.d", "*.toml"))
+	overrides := filepath.Join(folder, "config.d", configOverrides)
+
+	// Expand fragment patterns
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Warn("Error including config files ", pattern, ": ", err)
+			continue
+		}
+		for _, match := range matches {
+			if match != configFilePath && match != overrides && !IsInList(match, fragments) {
+				fragments = append(fragments, match)
+			}
+		}
+	}
+
+	// Include runtime overrides last
+	if _, err := os.Stat(overrides); err == nil {
+		fragments = append(fragments, overrides)
+	}
+
+	return fragments
+}
+
+func configLines(data string) map[string]int {
+	lines := map[string]int{}
+
+	// Map key and table names to line numbers
+	table := ""
+	depth := 0
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Skip multi-line values
+		if depth > 0 {
+			depth += strings.Count(line, "[") - strings.Count(line, "]")
+			continue
+		}
+
+		// Map table names
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(strings.SplitN(line, "]", 2)[0], "[ ")
+			if _, ok := lines["["+table+"]"]; !ok {
+				lines["["+table+"]"] = i + 1
+			}
+			continue
+		}
+		if !strings.Contains(line, "=") {
+			continue
+		}
+
+		// Map key names
+		parts := strings.SplitN(line, "=", 2)
+		key := strings.Trim(strings.TrimSpace(parts[0]), "\"")
+		if len(table) > 0 {
+			key = table + "." + key
+		}
+		if _, ok := lines[key]; !ok {
+			lines[key] = i + 1
+		}
+		depth = strings.Count(parts[1], "[") - strings.Count(parts[1], "]")
+	}
+
+	return lines
 }
 
 func watchConfig(configFilePath string) {
@@ -128,18 +1241,25 @@ func watchConfig(configFilePath string) {
 		log.Error(err)
 	} else {
 		watcher.Add(configFilePath)
+		watcher.Add(filepath.Join(filepath.Dir(configFilePath), "config.d"))
+		for _, fragment := range configFragments(configFilePath, Config.Include) {
+			watcher.Add(fragment)
+		}
 	}
 
 	// Listen for events
 	go func() {
+		defer Recover()
+
 		for {
 			select {
 			case event, ok := <-watcher.Events:
 				if !ok {
 					return
 				}
-				if event.Has(fsnotify.Write) {
-					readConfig(configFilePath, false)
+				fragment := filepath.Base(filepath.Dir(event.Name)) == "config.d"
+				if event.Has(fsnotify.Write) || (fragment && (event.Has(fsnotify.Create) || event.Has(fsnotify.Remove))) {
+					configCallbacks()
 				}
 			case err, ok := <-watcher.Errors:
 				if !ok {
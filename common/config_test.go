@@ -0,0 +1,128 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testConfigFile(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		keys []string
+	}{
+		{"empty", "", []string{}},
+		{"valid", "tiling_layout = \"vertical-left\"\nwindow_gap_size = 4\n", []string{}},
+		{"syntax error", "tiling_layout = \n", []string{"tiling_layout"}},
+		{"unknown key", "tiling_layuot = \"maximized\"\n", []string{"tiling_layuot"}},
+		{"unknown layout", "tiling_layout = \"spiral\"\n", []string{"tiling_layout"}},
+		{"unknown cycle layout", "tiling_cycle = [\"maximized\", \"spiral\"]\n", []string{"tiling_cycle"}},
+		{"out of range", "window_gap_size = 101\nproportion_min = 2.0\n", []string{"window_gap_size", "proportion_min"}},
+		{"out of range opacity", "window_opacity = 0.0\n", []string{"window_opacity"}},
+		{"invalid desktop layout", "[layouts]\n0 = \"maximized\"\n", []string{"layouts.0"}},
+		{"invalid limits", "[limits.maximized]\nslaves_allowed = 0\n", []string{"limits.maximized"}},
+		{"unknown quirk", "[quirks]\nunknown = true\n", []string{"quirks.unknown"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys := []string{}
+			for _, err := range ValidateConfig(testConfigFile(t, tt.data)) {
+				keys = append(keys, err.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("ValidateConfig(%q) keys = %v, want %v", tt.data, keys, tt.keys)
+			}
+		})
+	}
+}
+
+func TestValidateConfigFragments(t *testing.T) {
+	config := testConfigFile(t, "window_gap_size = 4\n")
+	fragment := filepath.Join(filepath.Dir(config), "config.d", "layout.toml")
+
+	// Write invalid fragment next to config
+	if err := os.MkdirAll(filepath.Dir(fragment), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fragment, []byte("\n\ntiling_layout = \"spiral\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	errs := ValidateConfig(config)
+	if len(errs) != 1 {
+		t.Fatalf("ValidateConfig() = %v, want one error", errs)
+	}
+	if errs[0].File != fragment || errs[0].Key != "tiling_layout" || errs[0].Line != 3 {
+		t.Errorf("ValidateConfig() = %+v, want error in %s:3 for key tiling_layout", errs[0], fragment)
+	}
+}
+
+func TestParseWorkspaceScope(t *testing.T) {
+	tests := []struct {
+		value  string
+		scopes []WorkspaceScope
+		err    bool
+	}{
+		{"", []WorkspaceScope{}, false},
+		{"  ", []WorkspaceScope{}, false},
+		{"1", []WorkspaceScope{{Desktop: 1}}, false},
+		{"*", []WorkspaceScope{{}}, false},
+		{"2:1", []WorkspaceScope{{Desktop: 2, Screen: "1"}}, false},
+		{"*:HDMI-1", []WorkspaceScope{{Screen: "HDMI-1"}}, false},
+		{"3:*", []WorkspaceScope{{Desktop: 3}}, false},
+		{"1, 4:eDP-1", []WorkspaceScope{{Desktop: 1}, {Desktop: 4, Screen: "eDP-1"}}, false},
+		{"0", nil, true},
+		{"-1:HDMI-1", nil, true},
+		{"one", nil, true},
+		{"1,two", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			scopes, err := ParseWorkspaceScope(tt.value)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseWorkspaceScope(%q) error = %v, want error %t", tt.value, err, tt.err)
+			}
+			if !reflect.DeepEqual(scopes, tt.scopes) {
+				t.Errorf("ParseWorkspaceScope(%q) = %v, want %v", tt.value, scopes, tt.scopes)
+			}
+		})
+	}
+}
+
+func TestWriteConfig(t *testing.T) {
+	path := testConfigFile(t, "window_gap_size = 4\n")
+
+	// Persist values into override fragment
+	if err := writeConfig(path, "window_gap_size", 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeConfig(path, "window_ignore", [][]string{{"^kitty$", ""}}); err != nil {
+		t.Fatal(err)
+	}
+
+	state, _, err := decodeConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.config.WindowGapSize != 8 {
+		t.Errorf("decodeConfig() window_gap_size = %d, want %d", state.config.WindowGapSize, 8)
+	}
+	if want := [][]string{{"^kitty$", ""}}; !reflect.DeepEqual(state.config.WindowIgnore, want) {
+		t.Errorf("decodeConfig() window_ignore = %v, want %v", state.config.WindowIgnore, want)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "window_gap_size = 4\n" {
+		t.Errorf("writeConfig() modified config file to %q", data)
+	}
+}
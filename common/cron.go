@@ -0,0 +1,146 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Cron struct {
+	Minutes  map[int]bool // Matching minutes (0 - 59)
+	Hours    map[int]bool // Matching hours (0 - 23)
+	Days     map[int]bool // Matching days of month (1 - 31)
+	Months   map[int]bool // Matching months (1 - 12)
+	Weekdays map[int]bool // Matching days of week (0 - 6, sunday = 0)
+	AnyDay   bool         // Day of month is unrestricted
+	AnyWeek  bool         // Day of week is unrestricted
+}
+
+var (
+	cronNames map[string]string = map[string]string{ // Names of months and weekdays
+		"jan": "1", "feb": "2", "mar": "3", "apr": "4", "may": "5", "jun": "6",
+		"jul": "7", "aug": "8", "sep": "9", "oct": "10", "nov": "11", "dec": "12",
+		"sun": "0", "mon": "1", "tue": "2", "wed": "3", "thu": "4", "fri": "5", "sat": "6",
+	}
+)
+
+func ParseCron(expr string) (*Cron, error) {
+	fields := strings.Fields(strings.ToLower(expr))
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 fields [minute hour day month weekday], got %d", len(fields))
+	}
+
+	// Parse cron fields
+	bounds := [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	sets := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("field %q is invalid (%s)", field, err)
+		}
+		sets[i] = set
+	}
+
+	// Map sunday 7 to 0
+	if sets[4][7] {
+		sets[4][0] = true
+		delete(sets[4], 7)
+	}
+
+	return &Cron{
+		Minutes:  sets[0],
+		Hours:    sets[1],
+		Days:     sets[2],
+		Months:   sets[3],
+		Weekdays: sets[4],
+		AnyDay:   strings.HasPrefix(fields[2], "*"),
+		AnyWeek:  strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+func (c *Cron) Matches(t time.Time) bool {
+	if !c.Minutes[t.Minute()] || !c.Hours[t.Hour()] || !c.Months[int(t.Month())] {
+		return false
+	}
+
+	// Match either day of month or day of week if both are restricted
+	day, week := c.Days[t.Day()], c.Weekdays[int(t.Weekday())]
+	if !c.AnyDay && !c.AnyWeek {
+		return day || week
+	}
+
+	return day && week
+}
+
+func (c *Cron) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+
+	// Search matching minute within one year
+	for limit := next.AddDate(1, 0, 0); next.Before(limit); next = next.Add(time.Minute) {
+		if c.Matches(next) {
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+func parseCronField(field string, min int, max int) (map[int]bool, error) {
+	set := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+
+		// Parse step values
+		step := 1
+		if base, s, found := strings.Cut(part, "/"); found {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", s)
+			}
+			part, step = base, n
+		}
+
+		// Parse value ranges
+		from, to := min, max
+		if part != "*" {
+			first, last, found := strings.Cut(part, "-")
+			a, err := parseCronValue(first)
+			if err != nil {
+				return nil, err
+			}
+			from, to = a, a
+			if found {
+				b, err := parseCronValue(last)
+				if err != nil {
+					return nil, err
+				}
+				to = b
+			} else if step > 1 {
+				to = max
+			}
+		}
+		if from < min || to > max || from > to {
+			return nil, fmt.Errorf("range %d-%d is out of bounds (%d - %d)", from, to, min, max)
+		}
+
+		// Collect matching values
+		for v := from; v <= to; v += step {
+			set[v] = true
+		}
+	}
+
+	return set, nil
+}
+
+func parseCronValue(value string) (int, error) {
+	if name, ok := cronNames[value]; ok {
+		value = name
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	return n, nil
+}
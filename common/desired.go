@@ -0,0 +1,75 @@
+package common
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+type DesiredState struct {
+	Workspaces []DesiredWorkspace `toml:"workspace"` // Desired state per workspace
+}
+
+type DesiredWorkspace struct {
+	Desktop    int             `toml:"desktop"`    // Desktop number (starting at 1)
+	Screen     string          `toml:"screen"`     // Screen index or output name (empty = all screens)
+	Tiling     *bool           `toml:"tiling"`     // Tiling enablement (empty = unchanged)
+	Layout     string          `toml:"layout"`     // Active tiling layout (empty = unchanged)
+	Masters    int             `toml:"masters"`    // Number of master windows (0 = unchanged)
+	Proportion float64         `toml:"proportion"` // Master-slave area proportion (0 = unchanged)
+	Windows    []DesiredWindow `toml:"windows"`    // Windows placed on this workspace
+}
+
+type DesiredWindow struct {
+	Class string `toml:"class"` // Regex to match windows by class
+	Role  string `toml:"role"`  // Target stack of windows (master, slave)
+	Index int    `toml:"index"` // Target position within stack (starting at 1)
+}
+
+func DesiredStatePath() string {
+	return filepath.Join(filepath.Dir(Args.Config), "layouts.toml")
+}
+
+func ReadDesiredState() (DesiredState, error) {
+	var state DesiredState
+	path := DesiredStatePath()
+
+	// Decode desired state file
+	meta, err := toml.DecodeFile(path, &state)
+	if err != nil {
+		return state, err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return state, fmt.Errorf("%s: unknown key %s", path, undecoded[0])
+	}
+
+	// Validate desired state values
+	layouts := []string{"vertical-left", "vertical-right", "horizontal-top", "horizontal-bottom", "maximized", "fullscreen"}
+	for i, ws := range state.Workspaces {
+		ref := fmt.Sprintf("%s: workspace %d", path, i+1)
+		if ws.Desktop < 1 {
+			return state, fmt.Errorf("%s: desktop must be a number starting at 1", ref)
+		}
+		if len(ws.Layout) > 0 && !IsInList(ws.Layout, layouts) {
+			return state, fmt.Errorf("%s: unknown layout %q, expected one of %s", ref, ws.Layout, strings.Join(layouts, ", "))
+		}
+		if ws.Masters < 0 {
+			return state, fmt.Errorf("%s: masters must not be negative", ref)
+		}
+		if ws.Proportion < 0 || ws.Proportion >= 1 {
+			return state, fmt.Errorf("%s: proportion %v is out of range (0 - 1)", ref, ws.Proportion)
+		}
+		for j, w := range ws.Windows {
+			if len(w.Class) == 0 {
+				return state, fmt.Errorf("%s: window %d has no class", ref, j+1)
+			}
+			if len(w.Role) > 0 && !IsInList(w.Role, []string{"master", "slave"}) {
+				return state, fmt.Errorf("%s: window %d has unknown role %q, expected master or slave", ref, j+1, w.Role)
+			}
+		}
+	}
+
+	return state, nil
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"sort"
+)
+
+type ExtentsProfile struct {
+	Position *bool // Adjust positions by window extents (nil = heuristic)
+	Size     *bool // Adjust sizes by window extents (nil = heuristic)
+	Offsets  []int // Corrections of move/resize requests (x, y, width, height)
+}
+
+var (
+	extentsOn  bool = true  // Enabled extents adjustment
+	extentsOff bool = false // Disabled extents adjustment
+)
+
+var (
+	ExtentsProfiles map[string]ExtentsProfile = map[string]ExtentsProfile{ // Built-in extents profiles
+		"heuristic": {},
+		"gtk-csd": {
+			Position: &extentsOn,
+			Size:     &extentsOn,
+		},
+		"kde": {
+			Position: &extentsOff,
+			Size:     &extentsOn,
+		},
+		"picom": {
+			Size: &extentsOn,
+		},
+	}
+)
+
+func ExtentsProfileNames() []string {
+	names := []string{}
+	for name := range ExtentsProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -308,11 +308,10 @@ func (i *Info) Unseen() bool {
 		return false
 	}
 
-	// Check info cache file
+	// Check info cache entry
 	cache := i.Cache()
-	_, err := os.Stat(filepath.Join(cache.Folder, cache.Name))
 
-	return os.IsNotExist(err)
+	return !cache.Exists()
 }
 
 func (i *Info) Seen() bool {
@@ -330,8 +329,7 @@ func (i *Info) Seen() bool {
 	}
 
 	// Write info cache
-	path := filepath.Join(cache.Folder, cache.Name)
-	err = os.WriteFile(path, data, 0644)
+	err = cache.Write(data)
 
 	return err == nil
 }
@@ -340,16 +338,10 @@ func (i *Info) Cache() Cache[*Info] {
 	subfolder := strings.ToLower(i.Type)
 	filename := fmt.Sprintf("%s-%s-%d", subfolder, i.Name, i.Id)
 
-	// Create info cache folder
-	folder := filepath.Join(Args.Cache, "infos", subfolder)
-	if _, err := os.Stat(folder); os.IsNotExist(err) {
-		os.MkdirAll(folder, 0755)
-	}
-
 	// Create info cache object
 	cache := Cache[*Info]{
-		Folder: folder,
-		Name:   HashString(filename, 20) + ".json",
+		Bucket: filepath.Join("infos", subfolder),
+		Key:    filename,
 		Data:   i,
 	}
 
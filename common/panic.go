@@ -0,0 +1,36 @@
+package common
+
+import (
+	"fmt"
+
+	"runtime/debug"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	panicCallbacksFun []func() // Panic callback functions
+)
+
+func OnPanic(fun func()) {
+	panicCallbacksFun = append(panicCallbacksFun, fun)
+}
+
+func Recover() {
+	err := recover()
+	if err == nil {
+		return
+	}
+	log.Error(fmt.Errorf("%s\n%s", err, debug.Stack()))
+
+	// Restore state before exit
+	panicCallbacks()
+
+	log.Fatal("Exit after panic")
+}
+
+func panicCallbacks() {
+	for _, fun := range panicCallbacksFun {
+		fun()
+	}
+}
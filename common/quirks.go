@@ -0,0 +1,46 @@
+package common
+
+import (
+	"sort"
+)
+
+type Quirk struct {
+	Description string   // Deviation of window manager behavior
+	Managers    []string // Window manager names with quirk (lowercase substrings)
+	Wayland     bool     // Quirk applies to XWayland sessions
+}
+
+var (
+	Quirks map[string]Quirk = map[string]Quirk{ // Known window manager quirks
+		"ignore_min_size": {
+			Description: "minimum size hints are ignored, windows may overlap when resized",
+			Managers:    []string{"mutter", "muffin", "budgie"},
+		},
+		"no_query_pointer": {
+			Description: "pointer position can't be queried, hot corners are disabled",
+			Wayland:     true,
+		},
+		"late_frame_extents": {
+			Description: "frame extents are set after mapping, new windows are tiled once extents are known",
+			Managers:    []string{"xfwm"},
+		},
+		"async_unmaximize": {
+			Description: "unmaximize requests are applied asynchronously, windows are moved once unmaximized",
+			Managers:    []string{"kwin"},
+		},
+		"workarea_margins": {
+			Description: "workarea margins are not announced as struts, tiling area is limited to the workarea",
+			Managers:    []string{"openbox", "marco", "budgie"},
+		},
+	}
+)
+
+func QuirkNames() []string {
+	names := []string{}
+	for name := range Quirks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
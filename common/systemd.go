@@ -0,0 +1,39 @@
+package common
+
+import (
+	"net"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func SystemdNotify(state string) bool {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if len(socket) == 0 {
+		return false
+	}
+
+	// Use abstract socket namespace
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	// Send service state to systemd
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		log.Warn("Error connecting to systemd notify socket: ", err)
+		return false
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	if err != nil {
+		log.Warn("Error notifying systemd: ", err)
+		return false
+	}
+
+	log.Debug("Notify systemd ", state)
+
+	return true
+}
@@ -0,0 +1,163 @@
+package common
+
+import (
+	"sort"
+	"strings"
+)
+
+type Theme struct {
+	Preset       string   `toml:"preset"`        // Built-in preset the theme values are merged over
+	Text         []int    `toml:"text"`          // Text color of gui elements
+	Background   []int    `toml:"background"`    // Background color of gui elements
+	ClientSlave  []int    `toml:"client_slave"`  // Slave client color of gui elements
+	ClientMaster []int    `toml:"client_master"` // Master client color of gui elements
+	Radius       *int     `toml:"radius"`        // Corner radius of gui windows
+	Opacity      *float64 `toml:"opacity"`       // Opacity of gui windows
+	Padding      *int     `toml:"padding"`       // Inner padding of gui text
+	FontPath     string   `toml:"font_path"`     // Font file path of gui text
+	FontSize     int      `toml:"font_size"`     // Font size of gui text
+}
+
+type Style struct {
+	Colors   map[string][]int // Color values of gui elements
+	Radius   int              // Corner radius of gui windows
+	Opacity  float64          // Opacity of gui windows
+	Padding  int              // Inner padding of gui text
+	FontPath string           // Font file path of gui text
+	FontSize int              // Font size of gui text
+}
+
+var (
+	LargePrintScale float64 = 2.5 // Scale factor of large print overlays
+)
+
+var (
+	ThemePresets map[string]Style = map[string]Style{ // Built-in theme presets
+		"default": {
+			Colors: map[string][]int{
+				"text":          {255, 255, 255, 255},
+				"background":    {30, 30, 40, 255},
+				"client_slave":  {58, 58, 78, 255},
+				"client_master": {98, 98, 128, 255},
+			},
+			Radius: 0, Opacity: 1.0, Padding: 4, FontSize: 16,
+		},
+		"light": {
+			Colors: map[string][]int{
+				"text":          {40, 40, 48, 255},
+				"background":    {240, 240, 244, 255},
+				"client_slave":  {200, 200, 212, 255},
+				"client_master": {150, 150, 180, 255},
+			},
+			Radius: 0, Opacity: 1.0, Padding: 4, FontSize: 16,
+		},
+		"nord": {
+			Colors: map[string][]int{
+				"text":          {236, 239, 244, 255},
+				"background":    {46, 52, 64, 255},
+				"client_slave":  {67, 76, 94, 255},
+				"client_master": {94, 129, 172, 255},
+			},
+			Radius: 8, Opacity: 0.95, Padding: 6, FontSize: 16,
+		},
+		"solarized": {
+			Colors: map[string][]int{
+				"text":          {238, 232, 213, 255},
+				"background":    {0, 43, 54, 255},
+				"client_slave":  {7, 54, 66, 255},
+				"client_master": {38, 139, 210, 255},
+			},
+			Radius: 4, Opacity: 1.0, Padding: 4, FontSize: 16,
+		},
+		"contrast": {
+			Colors: map[string][]int{
+				"text":          {255, 255, 255, 255},
+				"background":    {0, 0, 0, 255},
+				"client_slave":  {90, 90, 90, 255},
+				"client_master": {255, 190, 0, 255},
+			},
+			Radius: 0, Opacity: 1.0, Padding: 6, FontSize: 20,
+		},
+	}
+)
+
+func ThemePresetNames() []string {
+	names := []string{}
+	for name := range ThemePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func ActiveTheme() Style {
+	theme := Config.Theme
+
+	// Start from built-in preset
+	preset, ok := ThemePresets[theme.Preset]
+	if !ok {
+		preset = ThemePresets["default"]
+	}
+	style := preset
+	style.Colors = map[string][]int{}
+	for name, color := range preset.Colors {
+		style.Colors[name] = color
+	}
+
+	// Merge legacy gui colors and fonts without preset
+	if len(theme.Preset) == 0 {
+		for name, color := range Config.Colors {
+			if strings.HasPrefix(name, "gui_") {
+				style.Colors[strings.TrimPrefix(name, "gui_")] = color
+			}
+		}
+		if len(Config.GuiFontPath) > 0 {
+			style.FontPath = Config.GuiFontPath
+		}
+		if Config.GuiFontSize > 0 {
+			style.FontSize = Config.GuiFontSize
+		}
+	}
+
+	// Merge theme values over preset
+	for name, color := range map[string][]int{"text": theme.Text, "background": theme.Background, "client_slave": theme.ClientSlave, "client_master": theme.ClientMaster} {
+		if color != nil {
+			style.Colors[name] = color
+		}
+	}
+	if theme.Radius != nil {
+		style.Radius = *theme.Radius
+	}
+	if theme.Opacity != nil {
+		style.Opacity = *theme.Opacity
+	}
+	if theme.Padding != nil {
+		style.Padding = *theme.Padding
+	}
+	if len(theme.FontPath) > 0 {
+		style.FontPath = theme.FontPath
+	}
+	if theme.FontSize > 0 {
+		style.FontSize = theme.FontSize
+	}
+
+	// Enlarge fonts and use high contrast colors in large print mode
+	if Config.GuiLargePrint {
+		style.Colors = map[string][]int{}
+		for name, color := range ThemePresets["contrast"].Colors {
+			style.Colors[name] = color
+		}
+		style.FontSize = int(float64(style.FontSize) * LargePrintScale)
+		style.Padding = int(float64(style.Padding) * LargePrintScale)
+	}
+
+	return style
+}
+
+func ThemeColor(name string) []int {
+	if color, ok := ActiveTheme().Colors[name]; ok {
+		return color
+	}
+	return Config.Colors[name]
+}
@@ -96,6 +96,28 @@ func IsInsideRect(p Point, g Geometry) bool {
 	return p.X >= x && p.X <= (x+w) && p.Y >= y && p.Y <= (y+h)
 }
 
+func SplitChain(chain string) []string {
+	actions := []string{}
+
+	// Split comma separated actions, the last exec action keeps its commas
+	for chain != "" {
+		action, rest, found := strings.Cut(chain, ",")
+		if strings.HasPrefix(strings.TrimSpace(action), "exec:") {
+			action, found = chain, false
+		}
+		actions = append(actions, strings.TrimSpace(action))
+		if !found {
+			break
+		}
+		chain = rest
+		if chain == "" {
+			actions = append(actions, "")
+		}
+	}
+
+	return actions
+}
+
 func IsInList(item string, items []string) bool {
 	for i := 0; i < len(items); i++ {
 		if items[i] == item {
@@ -105,6 +127,15 @@ func IsInList(item string, items []string) bool {
 	return false
 }
 
+func IsInIntList(item int, items []int) bool {
+	for i := 0; i < len(items); i++ {
+		if items[i] == item {
+			return true
+		}
+	}
+	return false
+}
+
 func IsInMap(m Map, keys []string) bool {
 	exists := true
 	for _, key := range keys {
@@ -135,6 +166,10 @@ func StringsToInts(items []string) []int {
 	return result
 }
 
+func AbsInt(a int) int {
+	return int(math.Abs(float64(a)))
+}
+
 func MinInt(a int, b int) int {
 	return int(math.Min(float64(a), float64(b)))
 }
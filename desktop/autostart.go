@@ -0,0 +1,145 @@
+package desktop
+
+import (
+	"time"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Autostart struct {
+	Command     string         // Command line of launched application
+	Class       string         // Regex to match windows instead of process
+	Session     uint           // Session id of launched command
+	Location    store.Location // Target workspace location
+	Role        string         // Target stack of windows
+	Index       int            // Target position within stack
+	Expires     time.Time      // Expiration time of window matching
+	Placeholder *store.Client  // Placeholder client reserving the tile
+}
+
+var (
+	autostartTimeout time.Duration = 60 * time.Second // Maximum time until autostarted windows appear
+)
+
+func (tr *Tracker) AddAutostart(entry common.Autostart, pid uint) *Autostart {
+	location := store.Location{Desktop: store.Workplace.CurrentDesktop, Screen: store.Workplace.CurrentScreen}
+
+	// Obtain target desktop and screen
+	if entry.Desktop > 0 && uint(entry.Desktop) <= store.Workplace.DesktopCount {
+		location.Desktop = uint(entry.Desktop - 1)
+	}
+	if screen, ok := store.Workplace.Displays.ScreenIndex(entry.Screen); ok && len(entry.Screen) > 0 {
+		location.Screen = screen
+	}
+
+	// Add pending autostart window
+	a := &Autostart{
+		Command:  entry.Command,
+		Class:    entry.Class,
+		Session:  pid,
+		Location: location,
+		Role:     entry.Role,
+		Index:    common.MaxInt(entry.Index-1, 0),
+		Expires:  time.Now().Add(autostartTimeout),
+	}
+	tr.Autostarts = append(tr.Autostarts, a)
+
+	return a
+}
+
+func (tr *Tracker) AddPlaceholder(a *Autostart, c *store.Client) bool {
+	ws := tr.WorkspaceAt(a.Location.Desktop, a.Location.Screen)
+	if ws == nil || ws.TilingDisabled() || a.Expired() {
+		return false
+	}
+
+	c.Log().Info("Reserve tile of autostarted client [", a.Command, "]")
+
+	// Add placeholder at target position
+	c.Latest.Location = a.Location
+	a.Placeholder = c
+	ws.AddClient(c)
+	if len(a.Role) > 0 {
+		ws.PlaceClient(c, a.Role == "master", a.Index)
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func (tr *Tracker) ReleasePlaceholder(a *Autostart) bool {
+	ws := tr.removePlaceholder(a)
+	if ws == nil {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func (tr *Tracker) removePlaceholder(a *Autostart) *Workspace {
+	c := a.Placeholder
+	if c == nil {
+		return nil
+	}
+	a.Placeholder = nil
+
+	// Remove placeholder from workspace
+	ws := tr.ClientWorkspace(c)
+	if ws != nil {
+		ws.RemoveClient(c)
+	}
+
+	return ws
+}
+
+func (tr *Tracker) matchAutostart(c *store.Client) *Autostart {
+	if len(tr.Autostarts) == 0 {
+		return nil
+	}
+
+	// Find autostart entry of client
+	var match *Autostart
+	pending := []*Autostart{}
+	for _, a := range tr.Autostarts {
+		if a.Expired() {
+			log.Info("Autostart window did not appear [", a.Command, "]")
+			tr.ReleasePlaceholder(a)
+			continue
+		}
+		if match == nil && a.matches(c) {
+			match = a
+			continue
+		}
+		pending = append(pending, a)
+	}
+	tr.Autostarts = pending
+	if match == nil {
+		return nil
+	}
+	c.Log().Info("Move autostarted client to workspace-", match.Location.Desktop, "-", match.Location.Screen)
+
+	// Move client to target desktop
+	if c.Latest.Location.Desktop != match.Location.Desktop {
+		c.MoveToDesktop(uint32(match.Location.Desktop))
+	}
+
+	// Move client to target screen on next tiling
+	c.Latest.Location = match.Location
+
+	return match
+}
+
+func (a *Autostart) Expired() bool {
+	return time.Now().After(a.Expires)
+}
+
+func (a *Autostart) matches(c *store.Client) bool {
+	if len(a.Class) > 0 {
+		return store.IsMatching(a.Class, c.Latest)
+	}
+	return a.Session > 0 && c.Latest.Process.Session == a.Session
+}
@@ -0,0 +1,105 @@
+package desktop
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+func (tr *Tracker) updateDecisions(infos map[xproto.Window]*store.Info) {
+
+	// Skip decisions without window or config changes
+	state := tr.decisionState(infos)
+	if state == tr.Decided {
+		return
+	}
+	tr.Decided = state
+	decisions := make(map[xproto.Window]store.Decision)
+
+	// Obtain decisions of stacked windows
+	for _, w := range store.Windows.Stacked {
+		if info, ok := infos[w.Id]; ok {
+			decisions[w.Id] = tr.Decision(w.Id, info)
+		}
+	}
+	if reflect.DeepEqual(decisions, tr.Decisions) {
+		return
+	}
+	tr.Decisions = decisions
+
+	// Communicate decisions change
+	tr.Communicate("decisions_change")
+}
+
+func (tr *Tracker) decisionState(infos map[xproto.Window]*store.Info) string {
+	state := fmt.Sprint(common.Revision, store.Presenting, store.Workplace.Displays.Name)
+
+	// Combine frozen workspaces
+	for _, ws := range tr.Workspaces {
+		if ws.Frozen {
+			state += fmt.Sprint(ws.Location)
+		}
+	}
+
+	// Combine stacked windows and their tracking state
+	for _, w := range store.Windows.Stacked {
+		if info, ok := infos[w.Id]; ok {
+			state += fmt.Sprint(w.Id, info.Class, info.Name, info.Types, info.States, info.Location,
+				tr.isTracked(w.Id), tr.isPinned(w.Id), tr.isSpanned(w.Id), tr.isFloating(w.Id), tr.isIgnored(w.Id))
+		}
+	}
+
+	return state
+}
+
+func (tr *Tracker) Decision(w xproto.Window, info *store.Info) store.Decision {
+	return store.Decision{
+		Window: w,
+		Class:  info.Class,
+		Name:   info.Name,
+		Tiled:  tr.isTracked(w),
+		Reason: tr.untrackedReason(w, info),
+		Rules:  store.MatchingRules(info),
+	}
+}
+
+func (tr *Tracker) untrackedReason(w xproto.Window, info *store.Info) string {
+	if tr.isTracked(w) {
+		return ""
+	}
+
+	// Obtain reason of untracked windows
+	if reason := store.SpecialReason(info); len(reason) > 0 {
+		return reason
+	}
+	if reason := store.IgnoredReason(info); len(reason) > 0 {
+		return reason
+	}
+	if !store.IsManaged(info.Location) {
+		return "window on unmanaged desktop or screen"
+	}
+	if tr.isPinned(w) {
+		return "pinned window"
+	}
+	if tr.isSpanned(w) {
+		return "spanned window"
+	}
+	if tr.isFloating(w) {
+		return "floating window"
+	}
+	if tr.isIgnored(w) {
+		return "ignored window until closed"
+	}
+	if store.Presenting {
+		return "presentation mode"
+	}
+	if ws := tr.WorkspaceAt(info.Location.Desktop, info.Location.Screen); ws != nil && ws.Frozen {
+		return "frozen workspace"
+	}
+
+	return "pending window"
+}
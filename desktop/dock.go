@@ -0,0 +1,67 @@
+package desktop
+
+import (
+	"sort"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (tr *Tracker) Dock(screen uint, desktops []int, classes []string) int {
+	changed := map[*Workspace]bool{}
+
+	log.Info("Move docked windows to screen ", screen)
+
+	// Obtain clients in stable order
+	clients := []*store.Client{}
+	for _, c := range tr.Clients {
+		clients = append(clients, c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Window.Id < clients[j].Window.Id
+	})
+
+	for _, c := range clients {
+		ws := tr.ClientWorkspace(c)
+		if ws == nil || ws.Location.Screen == screen {
+			continue
+		}
+
+		// Check docked desktops and classes
+		docked := common.IsInIntList(int(ws.Location.Desktop+1), desktops)
+		for _, class := range classes {
+			docked = docked || store.IsMatching(class, c.Latest)
+		}
+		if !docked {
+			continue
+		}
+
+		// Move client to external screen
+		target := tr.WorkspaceAt(ws.Location.Desktop, screen)
+		if target == nil || target.TilingDisabled() {
+			continue
+		}
+		c.Log().Info("Move client to docked workspace-", target.Location.Desktop, "-", target.Location.Screen)
+		ws.RemoveClient(c)
+		c.Latest.Location = target.Location
+		target.AddClient(c)
+		changed[ws] = true
+		changed[target] = true
+	}
+
+	// Tile changed workspaces
+	for ws := range changed {
+		if ws.TilingEnabled() {
+			tr.Tile(ws)
+		}
+	}
+
+	// Communicate workspaces change
+	if len(changed) > 0 {
+		tr.Communicate("workspaces_change")
+	}
+
+	return len(changed)
+}
@@ -0,0 +1,116 @@
+package desktop
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (tr *Tracker) AddDesktop() bool {
+	count := store.Workplace.DesktopCount
+
+	log.Info("Add desktop ", count)
+
+	// Request additional desktop
+	return store.Server.NumberOfDesktopsReq(count+1) == nil
+}
+
+func (tr *Tracker) RemoveDesktop() bool {
+	count := store.Workplace.DesktopCount
+	if count <= 1 {
+		return false
+	}
+	last := count - 1
+
+	log.Info("Remove desktop ", last)
+
+	// Migrate windows to previous desktop
+	for _, w := range desktopWindows(last) {
+		store.Server.WmDesktopSet(w, last-1)
+	}
+
+	// Leave removed desktop
+	if store.Workplace.CurrentDesktop == last {
+		store.CurrentDesktopSet(store.X, last-1)
+	}
+
+	// Request removal of last desktop
+	return store.Server.NumberOfDesktopsReq(last) == nil
+}
+
+func (tr *Tracker) balanceDesktops() {
+	if !common.Config.TilingDesktopAuto {
+		return
+	}
+	count := store.Workplace.DesktopCount
+	if count == 0 {
+		return
+	}
+
+	// Add trailing empty desktop
+	if !tr.isEmptyDesktop(count - 1) {
+		tr.AddDesktop()
+		return
+	}
+
+	// Remove surplus empty desktops
+	if count > 1 && tr.isEmptyDesktop(count-2) && store.Workplace.CurrentDesktop < count-1 {
+		tr.RemoveDesktop()
+	}
+}
+
+func desktopWindows(desktop uint) []xproto.Window {
+	windows := []xproto.Window{}
+
+	// Collect stacked windows on desktop (sticky, desktop and dock windows excluded)
+	for _, w := range store.Windows.Stacked {
+		if d, err := store.Server.WmDesktopGet(w.Id); err != nil || d != desktop {
+			continue
+		}
+		types, _ := store.Server.WmWindowTypeGet(w.Id)
+		if common.IsInList("_NET_WM_WINDOW_TYPE_DESKTOP", types) || common.IsInList("_NET_WM_WINDOW_TYPE_DOCK", types) {
+			continue
+		}
+		windows = append(windows, w.Id)
+	}
+
+	return windows
+}
+
+func (tr *Tracker) adoptWorkspaces() bool {
+	screens := uint(0)
+	for location := range tr.Workspaces {
+		if location.Desktop == 0 {
+			screens++
+		}
+	}
+	if screens != store.Workplace.ScreenCount {
+		return false
+	}
+
+	log.Debug("Adopt workspaces of ", store.Workplace.DesktopCount, " desktops")
+
+	// Untrack clients of removed desktops
+	for w, c := range tr.Clients {
+		if c.Latest.Location.Desktop >= store.Workplace.DesktopCount {
+			tr.untrackWindow(w)
+		}
+	}
+
+	// Keep workspaces of remaining desktops
+	workspaces := CreateWorkspaces()
+	for location, ws := range tr.Workspaces {
+		if _, ok := workspaces[location]; ok {
+			workspaces[location] = ws
+		}
+	}
+	tr.Workspaces = workspaces
+
+	// Communicate workplace change
+	tr.Communicate("workplace_change")
+
+	return true
+}
@@ -0,0 +1,178 @@
+package desktop
+
+import (
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+type Group struct {
+	Rule    string          // Matching window group rule
+	Members []*store.Client // Grouped clients in tab order
+	Active  *store.Client   // Raised client occupying the tile
+	Tabs    common.Geometry // Tab strip geometry on top of the tile
+}
+
+func (ws *Workspace) ClientGroup(c *store.Client) *Group {
+	if c == nil {
+		return nil
+	}
+
+	// Find group containing client
+	for _, g := range ws.Groups {
+		if g.Index(c) >= 0 {
+			return g
+		}
+	}
+
+	return nil
+}
+
+func (ws *Workspace) RaiseMember(c *store.Client) bool {
+	g := ws.ClientGroup(c)
+	if g == nil || g.Active == c {
+		return false
+	}
+	c.Log().WithField("group", g.Rule).Info("Raise grouped client")
+
+	// Replace raised client in all layouts
+	for _, l := range ws.Layouts {
+		l.GetManager().ReplaceClient(g.Active, c)
+	}
+	if ws.Zoomed == g.Active {
+		ws.Zoomed = c
+	}
+	g.Active = c
+
+	return c.Raise()
+}
+
+func (ws *Workspace) CycleGroup(c *store.Client, dir int) *store.Client {
+	g := ws.ClientGroup(c)
+	if g == nil || len(g.Members) < 2 {
+		return nil
+	}
+
+	// Raise next or previous group member
+	n := len(g.Members)
+	next := g.Members[((g.Index(g.Active)+dir)%n+n)%n]
+	ws.RaiseMember(next)
+
+	return next
+}
+
+func (ws *Workspace) joinGroup(c *store.Client) bool {
+	if g := ws.ClientGroup(c); g != nil {
+		return g.Active != c
+	}
+	rule := store.GroupRule(c.Latest)
+	if len(rule) == 0 {
+		return false
+	}
+
+	// Add client to existing group and raise it
+	for _, g := range ws.Groups {
+		if g.Rule == rule {
+			c.Log().WithField("group", rule).Info("Add client to window group")
+			g.Members = append(g.Members, c)
+			ws.RaiseMember(c)
+			return true
+		}
+	}
+
+	// Create new group with client occupying the tile
+	ws.Groups = append(ws.Groups, &Group{Rule: rule, Members: []*store.Client{c}, Active: c})
+
+	return false
+}
+
+func (ws *Workspace) leaveGroup(c *store.Client) {
+	g := ws.ClientGroup(c)
+	if g == nil {
+		return
+	}
+
+	// Raise neighbour of removed tile owner
+	i := g.Index(c)
+	c.Tabbed = 0
+	if g.Active == c && len(g.Members) > 1 {
+		ws.RaiseMember(g.Members[(i+1)%len(g.Members)])
+	}
+	g.Members = append(g.Members[:i], g.Members[i+1:]...)
+
+	// Remove empty group
+	if len(g.Members) > 0 {
+		return
+	}
+	for j, wg := range ws.Groups {
+		if wg == g {
+			ws.Groups = append(ws.Groups[:j], ws.Groups[j+1:]...)
+			break
+		}
+	}
+}
+
+func (ws *Workspace) reserveTabs(mg *store.Manager) {
+	for _, c := range mg.Clients(store.Stacked) {
+		if c != nil {
+			c.Tabbed = 0
+		}
+	}
+
+	// Shrink tiles of grouped clients below the tab strip
+	for _, g := range ws.Groups {
+		for _, c := range g.Members {
+			c.Tabbed = 0
+			if len(g.Members) > 1 {
+				c.Tabbed = common.MaxInt(common.Config.WindowGroupTabs, 0)
+			}
+		}
+	}
+}
+
+func (ws *Workspace) tileGroups(mg *store.Manager) {
+	for _, g := range ws.Groups {
+		g.Tabs = common.Geometry{}
+		if len(g.Members) < 2 || g.Active.Tile.Width <= 0 {
+			continue
+		}
+
+		// Place tab strip on top of the shared tile
+		x, y, w, h := g.Active.Tile.Pieces()
+		if size := g.Active.Tabbed; size > 0 && h > 2*size {
+			g.Tabs = common.Geometry{X: x, Y: y, Width: w, Height: size}
+		}
+
+		// Stack hidden members into the shared tile
+		for _, c := range g.Members {
+			if c == g.Active {
+				continue
+			}
+			decorateClient(mg, c)
+			c.MoveWindow(x, y, w, h)
+		}
+	}
+}
+
+func (ws *Workspace) hiddenMembers() []*store.Client {
+	clients := []*store.Client{}
+
+	// Collect grouped clients without tile
+	for _, g := range ws.Groups {
+		for _, c := range g.Members {
+			if c != g.Active {
+				clients = append(clients, c)
+			}
+		}
+	}
+
+	return clients
+}
+
+func (g *Group) Index(c *store.Client) int {
+	for i, m := range g.Members {
+		if m.Window.Id == c.Window.Id {
+			return i
+		}
+	}
+	return -1
+}
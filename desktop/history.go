@@ -0,0 +1,179 @@
+package desktop
+
+import (
+	"reflect"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+type History struct {
+	Undo []*Snapshot // Workspace states to undo (most recent last)
+	Redo []*Snapshot // Workspace states to redo (most recent last)
+}
+
+type Snapshot struct {
+	Layout  uint           // Active layout index
+	Layouts []*LayoutState // States of all layouts
+}
+
+type LayoutState struct {
+	Masters     []xproto.Window   // Window ids of master clients
+	Slaves      []xproto.Window   // Window ids of slave clients
+	MastersMax  int               // Maximum number of masters
+	SlavesMax   int               // Maximum number of slaves
+	Proportions store.Proportions // Proportions of window clients
+	Reversed    bool              // Window order is mirrored
+	Stacking    bool              // Masters are stacked
+}
+
+var (
+	historySize int = 32 // Maximum number of remembered workspace states
+)
+
+func (ws *Workspace) Snapshot() *Snapshot {
+	s := &Snapshot{
+		Layout:  ws.Layout,
+		Layouts: make([]*LayoutState, len(ws.Layouts)),
+	}
+
+	// Copy layout states
+	for i, l := range ws.Layouts {
+		mg := l.GetManager()
+		s.Layouts[i] = &LayoutState{
+			Masters:     windowIds(mg.Masters.Stacked),
+			Slaves:      windowIds(mg.Slaves.Stacked),
+			MastersMax:  mg.Masters.Maximum,
+			SlavesMax:   mg.Slaves.Maximum,
+			Proportions: copyProportions(mg.Proportions),
+			Reversed:    mg.Reversed,
+			Stacking:    mg.Stacking,
+		}
+	}
+
+	return s
+}
+
+func (ws *Workspace) Remember(s *Snapshot) bool {
+	if s == nil || reflect.DeepEqual(s, ws.Snapshot()) {
+		return false
+	}
+
+	// Add state to undo history
+	ws.History.Undo = append(ws.History.Undo, s)
+	if len(ws.History.Undo) > historySize {
+		ws.History.Undo = ws.History.Undo[len(ws.History.Undo)-historySize:]
+	}
+	ws.History.Redo = nil
+
+	return true
+}
+
+func (ws *Workspace) Undo() bool {
+	n := len(ws.History.Undo)
+	if n == 0 {
+		return false
+	}
+
+	// Revert to previous state
+	ws.History.Redo = append(ws.History.Redo, ws.Snapshot())
+	ws.Revert(ws.History.Undo[n-1])
+	ws.History.Undo = ws.History.Undo[:n-1]
+
+	return true
+}
+
+func (ws *Workspace) Redo() bool {
+	n := len(ws.History.Redo)
+	if n == 0 {
+		return false
+	}
+
+	// Revert to next state
+	ws.History.Undo = append(ws.History.Undo, ws.Snapshot())
+	ws.Revert(ws.History.Redo[n-1])
+	ws.History.Redo = ws.History.Redo[:n-1]
+
+	return true
+}
+
+func (ws *Workspace) Revert(s *Snapshot) {
+	ws.SetLayout(s.Layout)
+
+	// Revert layout states
+	for i, l := range ws.Layouts {
+		if i >= len(s.Layouts) {
+			break
+		}
+		state := s.Layouts[i]
+		mg := l.GetManager()
+
+		// Map current clients
+		clients := make(map[xproto.Window]*store.Client)
+		for _, c := range mg.Clients(store.Stacked) {
+			clients[c.Window.Id] = c
+		}
+
+		// Restore client order of remaining clients
+		masters, slaves := []*store.Client{}, []*store.Client{}
+		for _, w := range state.Masters {
+			if c, ok := clients[w]; ok {
+				masters = append(masters, c)
+				delete(clients, w)
+			}
+		}
+		for _, w := range state.Slaves {
+			if c, ok := clients[w]; ok {
+				slaves = append(slaves, c)
+				delete(clients, w)
+			}
+		}
+
+		// Append clients added in the meantime
+		for _, c := range mg.Clients(store.Stacked) {
+			if _, ok := clients[c.Window.Id]; ok {
+				slaves = append(slaves, c)
+			}
+		}
+		for len(masters) > state.MastersMax {
+			slaves = append([]*store.Client{masters[len(masters)-1]}, slaves...)
+			masters = masters[:len(masters)-1]
+		}
+
+		// Restore layout values
+		mg.Masters.Stacked = masters
+		mg.Slaves.Stacked = slaves
+		mg.Masters.Maximum = state.MastersMax
+		mg.Slaves.Maximum = common.MaxInt(state.SlavesMax, 1)
+		proportions := copyProportions(&state.Proportions)
+		mg.Proportions = &proportions
+		mg.Reversed = state.Reversed
+		mg.SetAllowed(mg.Masters.Allowed, mg.Slaves.Allowed)
+		mg.Stacking = state.Stacking
+	}
+}
+
+func windowIds(clients []*store.Client) []xproto.Window {
+	ids := make([]xproto.Window, len(clients))
+	for i, c := range clients {
+		ids[i] = c.Window.Id
+	}
+	return ids
+}
+
+func copyProportions(p *store.Proportions) store.Proportions {
+	cp := func(m map[int][]float64) map[int][]float64 {
+		copied := make(map[int][]float64, len(m))
+		for k, v := range m {
+			copied[k] = append([]float64{}, v...)
+		}
+		return copied
+	}
+	return store.Proportions{
+		MasterSlave:  cp(p.MasterSlave),
+		MasterMaster: cp(p.MasterMaster),
+		SlaveSlave:   cp(p.SlaveSlave),
+	}
+}
@@ -0,0 +1,44 @@
+package desktop
+
+import (
+	"time"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (tr *Tracker) deferTile(ws *Workspace) bool {
+	if !common.Config.IdleDeferTiling || !store.IsIdle() {
+		return false
+	}
+
+	// Wait for user activity
+	if len(tr.Deferred) == 0 {
+		store.AfterFunc(time.Second, tr.resumeTile)
+	}
+	tr.Deferred[ws.Location] = true
+
+	ws.Log().Debug("Defer tiling while idle")
+
+	return true
+}
+
+func (tr *Tracker) resumeTile() {
+	if common.Config.IdleDeferTiling && store.IsIdle() {
+		store.AfterFunc(time.Second, tr.resumeTile)
+		return
+	}
+	deferred := tr.Deferred
+	tr.Deferred = make(map[store.Location]bool)
+
+	log.Info("Resume ", len(deferred), " deferred retiles")
+
+	// Tile deferred workspaces
+	for location := range deferred {
+		if ws, ok := tr.Workspaces[location]; ok {
+			tr.Tile(ws)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package desktop
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+var (
+	preplacing map[xproto.Window]bool = make(map[xproto.Window]bool) // Created windows until first map
+)
+
+func (tr *Tracker) attachPreplaceHandlers() {
+	root := store.X.RootWin()
+
+	// Attach window creation events
+	xevent.CreateNotifyFun(func(X *xgbutil.XUtil, ev xevent.CreateNotifyEvent) {
+		if !common.Config.WindowPreplace || ev.OverrideRedirect || ev.Parent != root {
+			return
+		}
+		w := ev.Window
+		preplacing[w] = true
+
+		// Attach structure and property events of unmapped window
+		xwindow.New(X, w).Listen(xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange)
+		xevent.MapNotifyFun(func(X *xgbutil.XUtil, ev xevent.MapNotifyEvent) {
+			preplacing[w] = false
+			store.RestorePreplaced(w, true)
+		}).Connect(X, w)
+		xevent.DestroyNotifyFun(func(X *xgbutil.XUtil, ev xevent.DestroyNotifyEvent) {
+			delete(preplacing, w)
+			store.RestorePreplaced(w, false)
+			if !tr.isTracked(w) {
+				xevent.Detach(X, w)
+			}
+		}).Connect(X, w)
+		xevent.PropertyNotifyFun(func(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
+			aname, _ := xprop.AtomName(X, ev.Atom)
+			if preplacing[w] && (aname == "WM_CLASS" || aname == "_NET_WM_WINDOW_TYPE") {
+				tr.preplaceWindow(w)
+			}
+		}).Connect(X, w)
+
+		tr.preplaceWindow(w)
+	}).Connect(store.X, root)
+}
+
+func (tr *Tracker) preplaceWindow(w xproto.Window) bool {
+	if tr.isTracked(w) {
+		return false
+	}
+
+	// Wait for class of new window
+	info := store.GetInfo(w)
+	if len(info.Class) == 0 || !tr.isTrackableInfo(info) {
+		return false
+	}
+
+	// New windows appear on the active screen
+	info.Location.Screen = store.Workplace.CurrentScreen
+	c := &store.Client{
+		Window:   store.CreateXWindow(w),
+		Original: info,
+		Cached:   info.Copy(),
+		Latest:   info.Copy(),
+	}
+
+	// Obtain reserved tile of autostarted client
+	for _, a := range tr.Autostarts {
+		if a.Placeholder != nil && !a.Expired() && a.matches(c) {
+			c.Preplace(a.Placeholder.Latest.Dimensions.Geometry)
+			return true
+		}
+	}
+
+	// Obtain predicted tile within active layout
+	ws := tr.WorkspaceAt(info.Location.Desktop, info.Location.Screen)
+	if ws == nil {
+		return false
+	}
+	geom, ok := ws.Predict(c)
+	if !ok {
+		return false
+	}
+	c.Preplace(geom)
+
+	return true
+}
@@ -0,0 +1,68 @@
+package desktop
+
+import (
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+
+	"github.com/leukipp/cortile/v2/store"
+)
+
+var (
+	framing      map[xproto.Window]*store.Timer = make(map[xproto.Window]*store.Timer) // New windows waiting for frame extents
+	frameTimeout time.Duration                  = 1 * time.Second                      // Maximum wait time for frame extents
+)
+
+func (tr *Tracker) awaitFrameExtents(w xproto.Window) bool {
+	if !store.HasQuirk("late_frame_extents") {
+		return false
+	}
+
+	// Check frame extents of window
+	if _, err := store.Server.PropertyNums(w, "_NET_FRAME_EXTENTS"); err == nil {
+		tr.framed(w, false)
+		return false
+	}
+	if timer, ok := framing[w]; ok {
+		return timer != nil
+	}
+
+	// Track window without frame extents after timeout
+	framing[w] = store.AfterFunc(frameTimeout, func() {
+		tr.framed(w, true)
+	})
+
+	// Attach property and destroy events
+	store.CreateXWindow(w).Instance.Listen(xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange)
+	xevent.PropertyNotifyFun(func(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
+		if aname, _ := xprop.AtomName(store.X, ev.Atom); aname == "_NET_FRAME_EXTENTS" {
+			tr.framed(w, true)
+		}
+	}).Connect(store.X, w)
+	xevent.DestroyNotifyFun(func(X *xgbutil.XUtil, ev xevent.DestroyNotifyEvent) {
+		tr.framed(w, false)
+	}).Connect(store.X, w)
+
+	return true
+}
+
+func (tr *Tracker) framed(w xproto.Window, track bool) {
+	timer, ok := framing[w]
+	if !ok || timer == nil {
+		return
+	}
+	timer.Stop()
+	delete(framing, w)
+	xevent.Detach(store.X, w)
+
+	// Track window with known or timed out frame extents
+	if track && tr.isTrackable(w) && !tr.isPinned(w) && !tr.isSpanned(w) && !tr.isFloating(w) && !store.Presenting {
+		framing[w] = nil
+		tr.trackWindow(w)
+		delete(framing, w)
+	}
+}
@@ -0,0 +1,168 @@
+package desktop
+
+import (
+	"sort"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (tr *Tracker) Reconcile(state common.DesiredState) int {
+	changed := map[*Workspace]bool{}
+
+	log.Info("Reconcile desired state of ", len(state.Workspaces), " workspaces")
+
+	for _, desired := range state.Workspaces {
+		targets := tr.desiredWorkspaces(desired)
+		if len(targets) == 0 {
+			log.Warn("Desired workspace not found [desktop-", desired.Desktop, "-", desired.Screen, "]")
+			continue
+		}
+
+		// Apply workspace properties
+		for _, ws := range targets {
+			if tr.reconcileWorkspace(ws, desired) {
+				changed[ws] = true
+			}
+		}
+
+		// Place matching windows on first workspace
+		for ws := range tr.reconcileWindows(targets[0], desired.Windows) {
+			changed[ws] = true
+		}
+	}
+
+	// Tile changed workspaces
+	for ws := range changed {
+		if ws.TilingEnabled() {
+			tr.Tile(ws)
+		}
+	}
+
+	// Communicate workspaces change
+	if len(changed) > 0 {
+		tr.Communicate("workspaces_change")
+	}
+
+	return len(changed)
+}
+
+func (tr *Tracker) desiredWorkspaces(desired common.DesiredWorkspace) []*Workspace {
+	workspaces := []*Workspace{}
+	desktop := uint(desired.Desktop - 1)
+
+	// Obtain workspaces of all or referenced screens
+	screen, ok := store.Workplace.Displays.ScreenIndex(desired.Screen)
+	for location, ws := range tr.Workspaces {
+		if location.Desktop != desktop {
+			continue
+		}
+		if len(desired.Screen) > 0 && (!ok || location.Screen != screen) {
+			continue
+		}
+		workspaces = append(workspaces, ws)
+	}
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Location.Screen < workspaces[j].Location.Screen
+	})
+
+	return workspaces
+}
+
+func (tr *Tracker) reconcileWorkspace(ws *Workspace, desired common.DesiredWorkspace) bool {
+	changed := false
+
+	// Reconcile tiling enablement
+	if desired.Tiling != nil && *desired.Tiling != ws.TilingEnabled() {
+		if *desired.Tiling {
+			ws.EnableTiling()
+		} else {
+			ws.DisableTiling()
+			tr.Restore(ws, store.Latest)
+		}
+		changed = true
+	}
+
+	// Reconcile active layout
+	if len(desired.Layout) > 0 && desired.Layout != ws.ActiveLayout().GetName() {
+		for i, l := range ws.Layouts {
+			if l.GetName() == desired.Layout {
+				ws.SetLayout(uint(i))
+				changed = true
+			}
+		}
+	}
+	mg := ws.ActiveLayout().GetManager()
+
+	// Reconcile number of masters
+	for desired.Masters > 0 && mg.Masters.Maximum != desired.Masters {
+		maximum := mg.Masters.Maximum
+		if maximum < desired.Masters {
+			mg.IncreaseMaster()
+		} else {
+			mg.DecreaseMaster()
+		}
+		if mg.Masters.Maximum == maximum {
+			break
+		}
+		changed = true
+	}
+
+	// Reconcile master-slave proportion
+	if desired.Proportion > 0 && mg.Proportions.MasterSlave[2][0] != desired.Proportion {
+		changed = mg.SetProportions(mg.Proportions.MasterSlave[2], desired.Proportion, 0, 1) || changed
+	}
+
+	if changed {
+		ws.Log().Info("Reconcile workspace to desired state")
+	}
+
+	return changed
+}
+
+func (tr *Tracker) reconcileWindows(target *Workspace, windows []common.DesiredWindow) map[*Workspace]bool {
+	changed := map[*Workspace]bool{}
+
+	// Obtain clients in stable order
+	clients := []*store.Client{}
+	for _, c := range tr.Clients {
+		clients = append(clients, c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Window.Id < clients[j].Window.Id
+	})
+
+	for _, w := range windows {
+		for _, c := range clients {
+			if !store.IsMatching(w.Class, c.Latest) {
+				continue
+			}
+
+			// Move client to target workspace
+			ws := tr.ClientWorkspace(c)
+			if ws != target {
+				c.Log().Info("Move client to desired workspace-", target.Location.Desktop, "-", target.Location.Screen)
+				if ws != nil {
+					ws.RemoveClient(c)
+					changed[ws] = true
+				}
+				if c.Latest.Location.Desktop != target.Location.Desktop {
+					c.MoveToDesktop(uint32(target.Location.Desktop))
+				}
+				c.Latest.Location = target.Location
+				target.AddClient(c)
+				changed[target] = true
+			}
+
+			// Place client within target stack
+			if len(w.Role) > 0 {
+				target.PlaceClient(c, w.Role == "master", common.MaxInt(w.Index-1, 0))
+				changed[target] = true
+			}
+		}
+	}
+
+	return changed
+}
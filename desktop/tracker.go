@@ -1,11 +1,14 @@
 package desktop
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/jezek/xgb/xproto"
 
 	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/ewmh"
 	"github.com/jezek/xgbutil/xevent"
 	"github.com/jezek/xgbutil/xprop"
 
@@ -15,12 +18,34 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-type Tracker struct {
-	Clients    map[xproto.Window]*store.Client // List of tracked clients
-	Workspaces map[store.Location]*Workspace   // List of workspaces per location
-	Channels   *Channels                       // Helper for channel communication
-	Handlers   *Handlers                       // Helper for event handlers
+var (
+	closeTimeout time.Duration = 2 * time.Second // Maximum time until closed windows are destroyed
+)
+
+var (
+	groupCallbacksFun     []func() // Window group events callback functions
+	workplaceCallbacksFun []func() // Workplace events callback functions
+)
 
+type Tracker struct {
+	Clients    map[xproto.Window]*store.Client  // List of tracked clients
+	Workspaces map[store.Location]*Workspace    // List of workspaces per location
+	Channels   *Channels                        // Helper for channel communication
+	Handlers   *Handlers                        // Helper for event handlers
+	Pinned     map[xproto.Window]*store.Client  // List of picture-in-picture clients
+	Spanned    map[xproto.Window]*store.Client  // List of clients fullscreen across monitors
+	Floating   map[xproto.Window]bool           // List of floating windows excluded from tiling (false = unfloated)
+	Ignored    map[xproto.Window]bool           // List of windows excluded from tracking until closed
+	Closing    map[xproto.Window]*Closing       // List of closed windows awaiting destroy
+	Titled     map[xproto.Window]bool           // List of checked windows (true = awaiting title changes)
+	Transients map[xproto.Window]bool           // List of placed transient windows
+	History    []xproto.Window                  // Focus history of windows (most recent first)
+	Urgent     []xproto.Window                  // Urgent windows (most recent last)
+	Autostarts []*Autostart                     // Pending windows of autostarted commands
+	Restoring  bool                             // Restore tile positions after workplace changes
+	Deferred   map[store.Location]bool          // Workspaces with retiles deferred while idle
+	Decisions  map[xproto.Window]store.Decision // Tiling decisions of stacked windows
+	Decided    string                           // Windows and config state of last decisions
 }
 type Channels struct {
 	Event  chan string // Channel for events
@@ -28,11 +53,12 @@ type Channels struct {
 }
 
 type Handlers struct {
-	Timer        *time.Timer // Timer to handle delayed structure events
-	ResizeClient *Handler    // Stores client for proportion change
-	MoveClient   *Handler    // Stores client for tiling after move
-	SwapClient   *Handler    // Stores clients for window swap
-	SwapScreen   *Handler    // Stores client for screen swap
+	Timer        *time.Timer  // Timer to handle delayed structure events
+	Promote      *store.Timer // Timer to handle delayed master promotion
+	ResizeClient *Handler     // Stores client for proportion change
+	MoveClient   *Handler     // Stores client for tiling after move
+	SwapClient   *Handler     // Stores clients for window swap
+	SwapScreen   *Handler     // Stores client for screen swap
 }
 
 func (h *Handlers) Active() bool {
@@ -46,6 +72,13 @@ func (h *Handlers) Reset() {
 	h.SwapScreen.Reset()
 }
 
+type Closing struct {
+	Workspace *Workspace   // Workspace of the closed client
+	Master    bool         // Closed client was a master
+	Index     int          // Stack index of the closed client
+	Timer     *store.Timer // Timer to re-insert clients that stay open
+}
+
 type Handler struct {
 	Dragging bool        // Indicates pointer dragging event
 	Source   interface{} // Stores moved/resized client
@@ -64,8 +97,19 @@ func CreateTracker() *Tracker {
 	tr := Tracker{
 		Clients:    make(map[xproto.Window]*store.Client),
 		Workspaces: CreateWorkspaces(),
+		Pinned:     make(map[xproto.Window]*store.Client),
+		Spanned:    make(map[xproto.Window]*store.Client),
+		Floating:   make(map[xproto.Window]bool),
+		Ignored:    make(map[xproto.Window]bool),
+		Closing:    make(map[xproto.Window]*Closing),
+		Titled:     make(map[xproto.Window]bool),
+		Deferred:   make(map[store.Location]bool),
+		Decisions:  make(map[xproto.Window]store.Decision),
+		Transients: make(map[xproto.Window]bool),
+		History:    make([]xproto.Window, 0),
+		Urgent:     make([]xproto.Window, 0),
 		Channels: &Channels{
-			Event:  make(chan string),
+			Event:  make(chan string, 32),
 			Action: make(chan string),
 		},
 		Handlers: &Handlers{
@@ -79,6 +123,7 @@ func CreateTracker() *Tracker {
 	// Attach to root events
 	store.OnStateUpdate(tr.onStateUpdate)
 	store.OnPointerUpdate(tr.onPointerUpdate)
+	tr.attachPreplaceHandlers()
 
 	return &tr
 }
@@ -91,9 +136,64 @@ func (tr *Tracker) Update() {
 	log.Debug("Update trackable clients [", len(tr.Clients), "/", len(store.Windows.Stacked), "]")
 
 	// Map trackable windows
+	infos := store.GetInfos(store.Windows.Stacked)
 	trackable := make(map[xproto.Window]bool)
 	for _, w := range store.Windows.Stacked {
-		trackable[w.Id] = tr.isTrackable(w.Id)
+		tr.handleAboveClient(w.Id, infos[w.Id])
+		trackable[w.Id] = tr.isTrackableInfo(infos[w.Id]) && !tr.isPinned(w.Id) && !tr.isSpanned(w.Id) && !tr.isFloating(w.Id) && !tr.isIgnored(w.Id) && !tr.isClosing(w.Id)
+	}
+
+	// Remove closed pinned, spanned, floating, ignored, closing and transient windows
+	for w := range tr.Pinned {
+		if _, ok := infos[w]; !ok {
+			delete(tr.Pinned, w)
+		}
+	}
+	for w := range tr.Spanned {
+		if _, ok := infos[w]; !ok {
+			delete(tr.Spanned, w)
+		}
+	}
+	for w := range tr.Floating {
+		if _, ok := infos[w]; !ok {
+			delete(tr.Floating, w)
+		}
+	}
+	for w := range tr.Ignored {
+		if _, ok := infos[w]; !ok {
+			delete(tr.Ignored, w)
+		}
+	}
+	for w, cl := range tr.Closing {
+		if _, ok := infos[w]; !ok {
+			cl.Timer.Stop()
+			delete(tr.Closing, w)
+		}
+	}
+	for w, titled := range tr.Titled {
+		if _, ok := infos[w]; !ok {
+			if titled && !tr.isTracked(w) {
+				xevent.Detach(store.X, w)
+			}
+			delete(tr.Titled, w)
+		}
+	}
+	for w := range tr.Transients {
+		if _, ok := infos[w]; !ok {
+			delete(tr.Transients, w)
+		}
+	}
+
+	// Wait for title changes of new ignored windows
+	for _, w := range store.Windows.Stacked {
+		if _, ok := tr.Titled[w.Id]; ok || trackable[w.Id] || tr.isPinned(w.Id) || tr.isSpanned(w.Id) || tr.isFloating(w.Id) || tr.isIgnored(w.Id) {
+			continue
+		}
+		tr.Titled[w.Id] = false
+		if info := infos[w.Id]; store.IsManaged(info.Location) && !store.IsSpecial(info) && store.IsIgnoredTitle(info) {
+			store.CreateXWindow(w.Id).Instance.Listen(xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange)
+			tr.attachTitleHandlers(w.Id)
+		}
 	}
 
 	// Remove untrackable windows
@@ -103,12 +203,26 @@ func (tr *Tracker) Update() {
 		}
 	}
 
-	// Add trackable windows
+	// Add trackable windows from collected infos (ignored in presentation mode)
 	for _, w := range store.Windows.Stacked {
-		if trackable[w.Id] {
-			tr.trackWindow(w.Id)
+		if trackable[w.Id] && !store.Presenting {
+			tr.trackWindowInfo(w.Id, infos[w.Id].Copy())
 		}
 	}
+
+	// Place transient windows
+	for _, w := range store.Windows.Stacked {
+		tr.handleTransientClient(w.Id, infos[w.Id])
+	}
+
+	// Restore cached tile positions
+	if tr.Restoring {
+		tr.Restoring = false
+		tr.restoreSlots()
+	}
+
+	// Record tiling decisions
+	tr.updateDecisions(infos)
 }
 
 func (tr *Tracker) Reset() {
@@ -121,14 +235,16 @@ func (tr *Tracker) Reset() {
 
 	// Reset workspaces
 	tr.Workspaces = CreateWorkspaces()
+	tr.Restoring = true
 
 	// Communicate workplace change
-	tr.Channels.Event <- "workplace_change"
+	tr.Communicate("workplace_change")
 }
 
 func (tr *Tracker) Write() {
 
 	// Write client cache
+	tr.updateSlots()
 	for _, c := range tr.Clients {
 		c.Write()
 	}
@@ -139,22 +255,61 @@ func (tr *Tracker) Write() {
 	}
 
 	// Communicate windows change
-	tr.Channels.Event <- "windows_change"
+	tr.Communicate("windows_change")
+}
+
+func (tr *Tracker) Shutdown() {
+	log.Debug("Shutdown trackable clients [", len(tr.Clients), "/", len(store.Windows.Stacked), "]")
+
+	// Write client and workspace cache
+	tr.Write()
+	store.FlushClients()
+
+	// Detach root events
+	xevent.Detach(store.X, store.X.RootWin())
+
+	// Restore windows of tiled workspaces
+	for _, ws := range tr.Workspaces {
+		if ws.TilingDisabled() {
+			continue
+		}
+		ws.DisableTiling()
+		tr.Restore(ws, store.Latest)
+	}
+}
+
+func (tr *Tracker) Communicate(event string) {
+
+	// Drop events if no listener keeps up
+	select {
+	case tr.Channels.Event <- event:
+	default:
+		log.Debug("Drop tracker event ", event)
+	}
 }
 
 func (tr *Tracker) Tile(ws *Workspace) {
-	if ws.TilingDisabled() {
+	if ws.TilingDisabled() || ws.Frozen || store.Presenting || tr.deferTile(ws) {
 		return
 	}
 
 	// Tile workspace
 	ws.Tile()
 
+	// Release clients exceeding the layout
+	tr.releaseOverflow(ws)
+
+	// Update window opacities
+	tr.updateOpacity()
+
+	// Update window group tabs
+	groupCallbacks()
+
 	// Communicate clients change
-	tr.Channels.Event <- "clients_change"
+	tr.Communicate("clients_change")
 
 	// Communicate workspaces change
-	tr.Channels.Event <- "workspaces_change"
+	tr.Communicate("workspaces_change")
 }
 
 func (tr *Tracker) Restore(ws *Workspace, flag uint8) {
@@ -162,11 +317,219 @@ func (tr *Tracker) Restore(ws *Workspace, flag uint8) {
 	// Restore workspace
 	ws.Restore(flag)
 
+	// Update window group tabs
+	groupCallbacks()
+
 	// Communicate clients change
-	tr.Channels.Event <- "clients_change"
+	tr.Communicate("clients_change")
 
 	// Communicate workspaces change
-	tr.Channels.Event <- "workspaces_change"
+	tr.Communicate("workspaces_change")
+}
+
+func (tr *Tracker) Recover() {
+
+	// Re-add window decorations (process exits afterwards)
+	common.Config.WindowDecoration = true
+
+	// Restore original dimensions of tracked, pinned and spanned clients
+	for _, clients := range []map[xproto.Window]*store.Client{tr.Clients, tr.Pinned, tr.Spanned} {
+		for _, c := range clients {
+			c.Restore(store.Original)
+		}
+	}
+	store.X.Sync()
+}
+
+func (tr *Tracker) Pin(c *store.Client) bool {
+	if !tr.isTracked(c.Window.Id) {
+		return false
+	}
+	c.Log().Info("Pin client")
+
+	// Untrack and pin client
+	tr.untrackWindow(c.Window.Id)
+	tr.Pinned[c.Window.Id] = c
+
+	return c.Pin()
+}
+
+func (tr *Tracker) UnPin(w xproto.Window) bool {
+	if !tr.isPinned(w) {
+		return false
+	}
+	c := tr.Pinned[w]
+	c.Log().Info("Unpin client")
+
+	// Unpin and track client
+	delete(tr.Pinned, w)
+	c.UnPin()
+
+	return tr.trackWindow(w)
+}
+
+func (tr *Tracker) Span(c *store.Client, edges ewmh.WmFullscreenMonitors) bool {
+	if !tr.isTracked(c.Window.Id) {
+		return false
+	}
+	c.Log().Info("Span client across monitors ", edges)
+
+	// Untrack and span client
+	tr.untrackWindow(c.Window.Id)
+	tr.Spanned[c.Window.Id] = c
+
+	return c.Span(edges)
+}
+
+func (tr *Tracker) UnSpan(w xproto.Window) bool {
+	if !tr.isSpanned(w) {
+		return false
+	}
+	c := tr.Spanned[w]
+	c.Log().Info("Unspan client")
+
+	// Unspan and track client
+	delete(tr.Spanned, w)
+	c.UnSpan()
+
+	return tr.trackWindow(w)
+}
+
+func (tr *Tracker) Float(c *store.Client) bool {
+	if !tr.isTracked(c.Window.Id) {
+		return false
+	}
+	c.Log().Info("Float client")
+
+	// Untrack and float client
+	tr.untrackWindow(c.Window.Id)
+	tr.Floating[c.Window.Id] = true
+
+	// Restore remembered floating geometry
+	c.Float()
+	tr.attachFloatHandlers(c)
+
+	return true
+}
+
+func (tr *Tracker) Ignore(c *store.Client) bool {
+	if !tr.isTracked(c.Window.Id) {
+		return false
+	}
+	c.Log().Info("Ignore client until closed")
+
+	// Untrack and ignore client
+	tr.untrackWindow(c.Window.Id)
+	tr.Ignored[c.Window.Id] = true
+
+	return true
+}
+
+func (tr *Tracker) Unfreeze(ws *Workspace) {
+	ws.Frozen = false
+
+	// Unfloat windows floated while frozen
+	for _, w := range ws.Floated {
+		if tr.isFloating(w) {
+			delete(tr.Floating, w)
+		}
+	}
+	ws.Floated = nil
+
+	// Retile unfrozen workspace
+	tr.Update()
+	tr.Tile(ws)
+}
+
+func (tr *Tracker) Close(c *store.Client) bool {
+	if !tr.isTracked(c.Window.Id) {
+		return false
+	}
+	c.Log().Info("Close client")
+
+	// Remember tile position of client
+	w := c.Window.Id
+	ws := tr.ClientWorkspace(c)
+	mg := ws.ActiveLayout().GetManager()
+	cl := &Closing{Workspace: ws, Master: mg.IsMaster(c), Index: mg.Index(mg.Slaves, c)}
+	if cl.Master {
+		cl.Index = mg.Index(mg.Masters, c)
+	}
+
+	// Request graceful window close
+	c.Close()
+
+	// Re-insert client at its tile position if the close was canceled
+	cl.Timer = store.AfterFunc(closeTimeout, func() {
+		tr.reopenWindow(w)
+	})
+	tr.Closing[w] = cl
+
+	// Retile without client ahead of destroy notify
+	return tr.untrackWindow(w)
+}
+
+func (tr *Tracker) reopenWindow(w xproto.Window) {
+	cl, ok := tr.Closing[w]
+	if !ok {
+		return
+	}
+	delete(tr.Closing, w)
+
+	// Track window that is still open
+	if !tr.isTrackable(w) || !tr.trackWindow(w) {
+		return
+	}
+	c := tr.Clients[w]
+	c.Log().Info("Reopen client after canceled close")
+
+	// Restore tile position of client
+	if tr.ClientWorkspace(c) == cl.Workspace {
+		cl.Workspace.PlaceClient(c, cl.Master, cl.Index)
+		tr.Tile(cl.Workspace)
+	}
+}
+
+func (tr *Tracker) UnFloat(w xproto.Window) bool {
+	if !tr.isFloating(w) {
+		return false
+	}
+	log.WithField("client", w).Info("Unfloat client")
+
+	// Remember floating geometry
+	xevent.Detach(store.X, w)
+	store.CreateClient(w).RememberFloat(true)
+
+	// Unfloat and track client
+	tr.Floating[w] = false
+
+	return tr.trackWindow(w)
+}
+
+func (tr *Tracker) FocusHistory(ws *Workspace) []*store.Client {
+	clients := []*store.Client{}
+
+	// Obtain tracked clients of workspace (or all workspaces)
+	for _, w := range tr.History {
+		c, ok := tr.Clients[w]
+		if ok && (ws == nil || tr.ClientWorkspace(c) == ws) {
+			clients = append(clients, c)
+		}
+	}
+
+	return clients
+}
+
+func (tr *Tracker) UrgentClient() *store.Client {
+
+	// Obtain most recent urgent client
+	for i := len(tr.Urgent) - 1; i >= 0; i-- {
+		if c, ok := tr.Clients[tr.Urgent[i]]; ok {
+			return c
+		}
+	}
+
+	return nil
 }
 
 func (tr *Tracker) ActiveWorkspace() *Workspace {
@@ -224,6 +587,58 @@ func (tr *Tracker) ActiveClient() *store.Client {
 	return c
 }
 
+func (tr *Tracker) updateSlots() {
+	for _, ws := range tr.Workspaces {
+		mg := ws.ActiveLayout().GetManager()
+
+		// Store tile positions
+		for i, c := range mg.Clients(store.Stacked) {
+			c.Slot = i
+		}
+	}
+}
+
+func (tr *Tracker) placeOutput(c *store.Client) {
+	screens := store.Workplace.Displays.Screens
+	if int(c.Latest.Location.Screen) >= len(screens) {
+		return
+	}
+
+	// Keep output names of disconnected screens to return to them later
+	if _, ok := store.Workplace.Displays.ScreenIndex(c.Output); ok || len(c.Output) == 0 {
+		c.Output = screens[c.Latest.Location.Screen].Name
+	}
+}
+
+func (tr *Tracker) restoreSlots() {
+	for _, ws := range tr.Workspaces {
+		for _, l := range ws.Layouts {
+			mg := l.GetManager()
+
+			// Order clients by cached tile positions
+			clients := append([]*store.Client{}, mg.Clients(store.Stacked)...)
+			sort.SliceStable(clients, func(i, j int) bool {
+				return clients[i].Slot < clients[j].Slot
+			})
+			mg.OrderClients(clients)
+		}
+		tr.Tile(ws)
+	}
+}
+
+func (tr *Tracker) updateOpacity() {
+	active := tr.ActiveClient()
+
+	// Dim unfocused clients of tiled workspaces
+	for _, c := range tr.Clients {
+		opacity := 1.0
+		if ws := tr.ClientWorkspace(c); ws != nil && ws.TilingEnabled() && c != active && !store.IsAlwaysOpaque(c.Latest) {
+			opacity = common.Config.WindowOpacity
+		}
+		c.SetOpacity(opacity)
+	}
+}
+
 func (tr *Tracker) unlockClients() {
 	ws := tr.ActiveWorkspace()
 	if ws == nil {
@@ -241,20 +656,63 @@ func (tr *Tracker) unlockClients() {
 }
 
 func (tr *Tracker) trackWindow(w xproto.Window) bool {
-	if tr.isTracked(w) {
+	return tr.trackWindowInfo(w, nil)
+}
+
+func (tr *Tracker) trackWindowInfo(w xproto.Window, info *store.Info) bool {
+	if tr.isTracked(w) || tr.isIgnored(w) {
 		return false
 	}
 
+	// Wait for frame extents of new windows
+	if tr.awaitFrameExtents(w) {
+		return false
+	}
+
+	// Request window info if not collected beforehand
+	if info == nil {
+		info = store.GetInfo(w)
+	}
+
 	// Client and workspace
-	c := store.CreateClient(w)
+	c := store.CreateClientInfo(w, info)
+	a := tr.matchAutostart(c)
 	ws := tr.ClientWorkspace(c)
 	if ws == nil {
 		return false
 	}
 
+	// Float new clients on frozen workspaces
+	if ws.Frozen && ws.TilingEnabled() {
+		c.Log().Info("Float client on frozen workspace")
+		tr.Floating[c.Window.Id] = true
+		ws.Floated = append(ws.Floated, c.Window.Id)
+		return false
+	}
+
+	// Move new clients of crowded workspaces to an empty desktop
+	if a == nil && c.IsNew() && ws.TilingEnabled() {
+		if ws = tr.spillClient(c, ws); ws == nil {
+			return false
+		}
+	}
+
+	// Swap placeholder of autostarted client
+	if a != nil {
+		if pws := tr.removePlaceholder(a); pws != nil && pws != ws {
+			tr.Tile(pws)
+		}
+	}
+
 	// Add new client
 	tr.Clients[c.Window.Id] = c
 	ws.AddClient(c)
+	tr.placeOutput(c)
+
+	// Place autostarted client
+	if a != nil && len(a.Role) > 0 {
+		ws.PlaceClient(c, a.Role == "master", a.Index)
+	}
 
 	// Attach handlers
 	tr.attachHandlers(c)
@@ -263,6 +721,38 @@ func (tr *Tracker) trackWindow(w xproto.Window) bool {
 	return true
 }
 
+func (tr *Tracker) spillClient(c *store.Client, ws *Workspace) *Workspace {
+	limit := common.Config.WindowTiledMax
+	if limit <= 0 || len(ws.ActiveLayout().GetManager().Clients(store.Stacked)) < limit {
+		return ws
+	}
+
+	// Find next desktop without any windows (tracked or not)
+	desktop := ws.Location.Desktop + 1
+	for desktop < store.Workplace.DesktopCount && !tr.isEmptyDesktop(desktop) {
+		desktop++
+	}
+
+	// Request additional desktop
+	added := desktop >= store.Workplace.DesktopCount
+	if added {
+		store.Server.NumberOfDesktopsReq(desktop + 1)
+	}
+
+	c.Log().Info("Move client of crowded workspace to desktop ", desktop)
+
+	// Move client to empty desktop
+	c.MoveToDesktop(uint32(desktop))
+	c.Latest.Location.Desktop = desktop
+
+	// Track client after workplace update
+	if added {
+		return nil
+	}
+
+	return tr.ClientWorkspace(c)
+}
+
 func (tr *Tracker) untrackWindow(w xproto.Window) bool {
 	if !tr.isTracked(w) {
 		return false
@@ -278,6 +768,10 @@ func (tr *Tracker) untrackWindow(w xproto.Window) bool {
 	// Detach events
 	xevent.Detach(store.X, w)
 
+	// Reset icon and sync cache
+	store.IconReset(w)
+	store.SyncReset(w)
+
 	// Restore client
 	c.Restore(store.Latest)
 
@@ -285,12 +779,44 @@ func (tr *Tracker) untrackWindow(w xproto.Window) bool {
 	ws.RemoveClient(c)
 	delete(tr.Clients, w)
 
+	// Remove focus history
+	tr.History = removeWindow(tr.History, w)
+	tr.Urgent = removeWindow(tr.Urgent, w)
+
 	// Tile workspace
 	tr.Tile(ws)
 
 	return true
 }
 
+func (tr *Tracker) handleFocusClient(c *store.Client) {
+	if c == nil {
+		return
+	}
+
+	// Move client to front of focus history
+	history := removeWindow(tr.History, c.Window.Id)
+	tr.History = append([]xproto.Window{c.Window.Id}, history...)
+
+	// Reset urgent client
+	tr.Urgent = removeWindow(tr.Urgent, c.Window.Id)
+}
+
+func (tr *Tracker) handleUrgentClient(c *store.Client) {
+	if !tr.isTracked(c.Window.Id) {
+		return
+	}
+
+	// Client demands attention
+	urgent := store.IsUrgent(store.GetInfo(c.Window.Id))
+	tr.Urgent = removeWindow(tr.Urgent, c.Window.Id)
+	if urgent && c.Window.Id != store.Windows.Active.Id {
+		c.Log().Debug("Client urgent handler fired")
+		c.Trace("Handler", "urgent")
+		tr.Urgent = append(tr.Urgent, c.Window.Id)
+	}
+}
+
 func (tr *Tracker) handleMaximizedClient(c *store.Client) {
 	if !tr.isTracked(c.Window.Id) {
 		return
@@ -302,7 +828,8 @@ func (tr *Tracker) handleMaximizedClient(c *store.Client) {
 		if ws.TilingDisabled() {
 			return
 		}
-		log.Debug("Client maximized handler fired [", c.Latest.Class, "]")
+		c.Log().Debug("Client maximized handler fired")
+		c.Trace("Handler", "maximized")
 
 		// Update client states
 		c.Update()
@@ -329,7 +856,8 @@ func (tr *Tracker) handleMinimizedClient(c *store.Client) {
 		if ws.TilingDisabled() {
 			return
 		}
-		log.Debug("Client minimized handler fired [", c.Latest.Class, "]")
+		c.Log().Debug("Client minimized handler fired")
+		c.Trace("Handler", "minimized")
 
 		// Untrack client
 		tr.untrackWindow(c.Window.Id)
@@ -347,7 +875,7 @@ func (tr *Tracker) handleResizeClient(c *store.Client) {
 	px, py, pw, ph := pGeom.Pieces()
 
 	// Current dimensions
-	cGeom, err := c.Window.Instance.DecorGeometry()
+	cGeom, err := store.Server.DecorGeometry(c.Window.Id)
 	if err != nil {
 		return
 	}
@@ -362,24 +890,26 @@ func (tr *Tracker) handleResizeClient(c *store.Client) {
 
 		// Set client resize event
 		if !c.IsNew() && !tr.Handlers.ResizeClient.Active() {
-			tr.Handlers.ResizeClient = &Handler{Dragging: pt.Dragging(500), Source: c}
+			tr.Handlers.ResizeClient = &Handler{Dragging: pt.Dragging(500) && pt.Resizing(), Source: c}
 		}
-		log.Debug("Client resize handler fired [", c.Latest.Class, "]")
+		c.Log().Debug("Client resize handler fired")
+		c.Trace("Handler", "resize")
 
 		if tr.Handlers.ResizeClient.Dragging {
 
 			// Set client resize lock
 			if tr.Handlers.ResizeClient.Active() {
 				tr.Handlers.ResizeClient.Source.(*store.Client).Lock()
-				log.Debug("Client resize handler active [", c.Latest.Class, "]")
+				c.Log().Debug("Client resize handler active")
 			}
 
 			// Update proportions
+			edges := common.Config.ResizeEdges
 			dir := &store.Directions{
-				Top:    cy != py,
-				Right:  cx == px && cw != pw,
-				Bottom: cy == py && ch != ph,
-				Left:   cx != px,
+				Top:    cy != py && common.IsInList("top", edges),
+				Right:  cx == px && cw != pw && common.IsInList("right", edges),
+				Bottom: cy == py && ch != ph && common.IsInList("bottom", edges),
+				Left:   cx != px && common.IsInList("left", edges),
 			}
 			ws.ActiveLayout().UpdateProportions(c, dir)
 		}
@@ -400,7 +930,7 @@ func (tr *Tracker) handleMoveClient(c *store.Client) {
 	px, py, pw, ph := pGeom.Pieces()
 
 	// Current dimensions
-	cGeom, err := c.Window.Instance.DecorGeometry()
+	cGeom, err := store.Server.DecorGeometry(c.Window.Id)
 	if err != nil {
 		return
 	}
@@ -417,7 +947,8 @@ func (tr *Tracker) handleMoveClient(c *store.Client) {
 		if !c.IsNew() && !tr.Handlers.MoveClient.Active() {
 			tr.Handlers.MoveClient = &Handler{Dragging: pt.Dragging(500), Source: c}
 		}
-		log.Debug("Client move handler fired [", c.Latest.Class, "]")
+		c.Log().Debug("Client move handler fired")
+		c.Trace("Handler", "move")
 
 		// Obtain targets based on dragging indicator
 		targetPoint := *common.CreatePoint(cx, cy)
@@ -431,25 +962,139 @@ func (tr *Tracker) handleMoveClient(c *store.Client) {
 		tr.Handlers.SwapClient.Reset()
 		if co := tr.ClientAt(ws, targetPoint); co != nil && co != c {
 			tr.Handlers.SwapClient = &Handler{Source: c, Target: co}
-			log.Debug("Client swap handler active [", c.Latest.Class, "-", co.Latest.Class, "]")
+			c.Log().WithField("target", co.Latest.Class).Debug("Client swap handler active")
 		}
 
 		// Check if target point moves to another screen
 		tr.Handlers.SwapScreen.Reset()
 		if c.Latest.Location.Screen != targetScreen {
 			tr.Handlers.SwapScreen = &Handler{Source: c, Target: tr.WorkspaceAt(targetDesktop, targetScreen)}
-			log.Debug("Screen swap handler active [", c.Latest.Class, "]")
+			c.Log().Debug("Screen swap handler active")
 		}
 	}
 }
 
+func (tr *Tracker) handlePromoteClient(c *store.Client) {
+
+	// Reset timer
+	tr.Handlers.Promote.Stop()
+
+	// Validate promotion mode
+	ws := tr.ClientWorkspace(c)
+	if ws.TilingDisabled() || !ws.Promote || common.Config.WindowFocusMaster <= 0 {
+		return
+	}
+	if !ws.ActiveLayout().GetManager().IsSlave(c) {
+		return
+	}
+
+	// Wait for focus duration
+	tr.Handlers.Promote = store.AfterFunc(time.Duration(common.Config.WindowFocusMaster)*time.Millisecond, func() {
+		if store.Windows.Active.Id != c.Window.Id || !ws.ActiveLayout().GetManager().IsSlave(c) {
+			return
+		}
+		c.Log().Info("Promote focused client")
+
+		// Make client master
+		ws.ActiveLayout().MakeMaster(c)
+		tr.Tile(ws)
+	})
+}
+
+func (tr *Tracker) handleGroupClient(c *store.Client) {
+	ws := tr.ClientWorkspace(c)
+	if ws == nil || ws.TilingDisabled() {
+		return
+	}
+
+	// Move focused group member into the shared tile
+	if ws.RaiseMember(c) {
+		tr.Tile(ws)
+	}
+}
+
+func (tr *Tracker) handleAboveClient(w xproto.Window, info *store.Info) {
+	if common.Config.WindowAbove != "float" || !store.IsAbove(info) {
+		return
+	}
+
+	// Float always-on-top windows once
+	if _, ok := tr.Floating[w]; !ok && tr.isTrackableInfo(info) {
+		log.WithField("class", info.Class).Info("Float always-on-top client")
+		tr.Floating[w] = true
+	}
+}
+
+func (tr *Tracker) handleTransientClient(w xproto.Window, info *store.Info) {
+	if info.Transient == 0 || tr.Transients[w] {
+		return
+	}
+
+	// Validate parent client
+	parent, ok := tr.Clients[info.Transient]
+	if !ok {
+		return
+	}
+	tr.Transients[w] = true
+
+	// Center transient window over parent tile
+	px, py, pw, ph := parent.Latest.Dimensions.Geometry.Pieces()
+	_, _, dw, dh := info.Dimensions.Geometry.Pieces()
+	log.WithField("class", info.Class).Info("Center transient window")
+
+	store.Server.MoveWindow(w, px+(pw-dw)/2, py+(ph-dh)/2)
+}
+
+func (tr *Tracker) handleTitleChange(c *store.Client) {
+	if !tr.isTracked(c.Window.Id) {
+		return
+	}
+
+	// Update client title
+	c.Update()
+	if tr.isTrackableInfo(c.Latest) {
+		groupCallbacks()
+		return
+	}
+	c.Log().WithField("name", c.Latest.Name).Info("Untrack client with ignored title")
+
+	// Untrack client and wait for title changes
+	tr.untrackWindow(c.Window.Id)
+	tr.attachTitleHandlers(c.Window.Id)
+}
+
+func (tr *Tracker) handleExtentsChange(c *store.Client) {
+	if !tr.isTracked(c.Window.Id) {
+		return
+	}
+
+	// Compare client extents and adjustments
+	info := store.GetInfo(c.Window.Id)
+	previous, current := c.Latest.Dimensions, info.Dimensions
+	if previous.Extents == current.Extents && previous.AdjPos == current.AdjPos && previous.AdjSize == current.AdjSize {
+		return
+	}
+	c.Log().Info("Update client extents ", current.Extents)
+
+	// Update client extents
+	c.Update()
+
+	// Tile workspace with corrected extents
+	ws := tr.ClientWorkspace(c)
+	if ws == nil || ws.TilingDisabled() || tr.Handlers.MoveClient.Active() || tr.Handlers.ResizeClient.Active() {
+		return
+	}
+	tr.Tile(ws)
+}
+
 func (tr *Tracker) handleSwapClient(h *Handler) {
 	c, target := h.Source.(*store.Client), h.Target.(*store.Client)
 	ws := tr.ClientWorkspace(c)
 	if !tr.isTracked(c.Window.Id) {
 		return
 	}
-	log.Debug("Client swap handler fired [", c.Latest.Class, "-", target.Latest.Class, "]")
+	c.Log().WithField("target", target.Latest.Class).Debug("Client swap handler fired")
+	c.Trace("Handler", "swap")
 
 	// Swap clients on same desktop and screen
 	mg := ws.ActiveLayout().GetManager()
@@ -467,7 +1112,8 @@ func (tr *Tracker) handleWorkspaceChange(h *Handler) {
 	if !tr.isTracked(c.Window.Id) {
 		return
 	}
-	log.Debug("Client workspace handler fired [", c.Latest.Class, "]")
+	c.Log().Debug("Client workspace handler fired")
+	c.Trace("Handler", "workspace")
 
 	// Remove client from current workspace
 	ws := tr.ClientWorkspace(c)
@@ -480,8 +1126,9 @@ func (tr *Tracker) handleWorkspaceChange(h *Handler) {
 		tr.Tile(ws)
 	}
 
-	// Update client desktop and screen
+	// Update client desktop and screen (untrack clients ignored on new workspace)
 	if !tr.isTrackable(c.Window.Id) {
+		tr.untrackWindow(c.Window.Id)
 		return
 	}
 	c.Update()
@@ -493,6 +1140,7 @@ func (tr *Tracker) handleWorkspaceChange(h *Handler) {
 	}
 	mg = ws.ActiveLayout().GetManager()
 	ws.AddClient(c)
+	tr.placeOutput(c)
 	if master {
 		mg.MakeMaster(c)
 	}
@@ -512,14 +1160,16 @@ func (tr *Tracker) onStateUpdate(state string, desktop uint, screen uint) {
 	workplaceChanged := store.Workplace.DesktopCount*store.Workplace.ScreenCount != uint(len(tr.Workspaces))
 	workspaceChanged := common.IsInList(state, []string{"_NET_CURRENT_DESKTOP"})
 
-	viewportChanged := common.IsInList(state, []string{"_NET_NUMBER_OF_DESKTOPS", "_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA"})
+	viewportChanged := common.IsInList(state, []string{"_NET_NUMBER_OF_DESKTOPS", "_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA", "_NET_WM_STRUT_PARTIAL"})
 	clientsChanged := common.IsInList(state, []string{"_NET_CLIENT_LIST_STACKING"})
 	focusChanged := common.IsInList(state, []string{"_NET_ACTIVE_WINDOW"})
 
 	if workplaceChanged {
 
-		// Reset clients and workspaces
-		tr.Reset()
+		// Adopt or reset clients and workspaces
+		if !tr.adoptWorkspaces() {
+			tr.Reset()
+		}
 	}
 
 	if workspaceChanged {
@@ -544,8 +1194,48 @@ func (tr *Tracker) onStateUpdate(state string, desktop uint, screen uint) {
 		tr.Update()
 	}
 
+	if workspaceChanged || viewportChanged || clientsChanged {
+
+		// Keep one trailing empty desktop
+		tr.balanceDesktops()
+	}
+
+	if viewportChanged {
+
+		// Tile workspaces with changed dimensions
+		for _, ws := range tr.Workspaces {
+			tr.Tile(ws)
+		}
+	}
+
+	if workplaceChanged || viewportChanged {
+
+		// Notify about changed screens
+		workplaceCallbacks()
+	}
+
 	if focusChanged {
 
+		// Update focus history
+		tr.handleFocusClient(tr.ActiveClient())
+
+		// Raise focused client of window group
+		tr.handleGroupClient(tr.ActiveClient())
+
+		// Promote focused slave client
+		tr.handlePromoteClient(tr.ActiveClient())
+
+		// Update window opacities
+		tr.updateOpacity()
+
+		// Reset temporarily maximized clients
+		for _, ws := range tr.Workspaces {
+			if ws.Zoomed != nil && ws.Zoomed.Window.Id != store.Windows.Active.Id {
+				ws.ToggleZoom(nil)
+				tr.Tile(ws)
+			}
+		}
+
 		// Write client and workspace cache
 		tr.Write()
 	}
@@ -599,7 +1289,8 @@ func (tr *Tracker) attachHandlers(c *store.Client) {
 
 	// Attach structure events
 	xevent.ConfigureNotifyFun(func(X *xgbutil.XUtil, ev xevent.ConfigureNotifyEvent) {
-		log.Trace("Client structure event [", c.Latest.Class, "]")
+		c.Log().Trace("Client structure event")
+		c.Trace("ConfigureNotify", fmt.Sprintf("%d %d %d %d", ev.X, ev.Y, ev.Width, ev.Height))
 
 		// Handle structure events
 		tr.handleResizeClient(c)
@@ -612,24 +1303,159 @@ func (tr *Tracker) attachHandlers(c *store.Client) {
 	// Attach property events
 	xevent.PropertyNotifyFun(func(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
 		aname, _ := xprop.AtomName(store.X, ev.Atom)
-		log.Trace("Client property event ", aname, " [", c.Latest.Class, "]")
+		c.Log().Trace("Client property event ", aname)
+		c.Trace("PropertyNotify", aname)
 
 		// Handle property events
 		if aname == "_NET_WM_STATE" {
+			tr.handleUrgentClient(c)
 			tr.handleMaximizedClient(c)
 			tr.handleMinimizedClient(c)
 		} else if aname == "_NET_WM_DESKTOP" {
 			tr.handleWorkspaceChange(&Handler{Source: c, Target: tr.ActiveWorkspace()})
+		} else if aname == "_NET_WM_ICON" {
+			store.IconReset(c.Window.Id)
+		} else if aname == "_NET_WM_NAME" || aname == "WM_NAME" {
+			tr.handleTitleChange(c)
+		} else if aname == "_NET_FRAME_EXTENTS" || aname == "_GTK_FRAME_EXTENTS" {
+			tr.handleExtentsChange(c)
 		}
 	}).Connect(store.X, c.Window.Id)
 }
 
+func (tr *Tracker) attachTitleHandlers(w xproto.Window) {
+	if tr.Titled[w] {
+		return
+	}
+	tr.Titled[w] = true
+
+	// Attach property events
+	xevent.PropertyNotifyFun(func(X *xgbutil.XUtil, ev xevent.PropertyNotifyEvent) {
+		aname, _ := xprop.AtomName(store.X, ev.Atom)
+		if tr.isTracked(w) || (aname != "_NET_WM_NAME" && aname != "WM_NAME") {
+			return
+		}
+
+		// Track window with allowed title
+		if tr.isTrackable(w) && !store.Presenting {
+			xevent.Detach(store.X, w)
+			tr.Titled[w] = false
+			tr.trackWindow(w)
+		}
+	}).Connect(store.X, w)
+}
+
+func (tr *Tracker) attachFloatHandlers(c *store.Client) {
+	c.Window.Instance.Listen(xproto.EventMaskStructureNotify)
+
+	// Attach structure events
+	xevent.ConfigureNotifyFun(func(X *xgbutil.XUtil, ev xevent.ConfigureNotifyEvent) {
+		if tr.isFloating(c.Window.Id) {
+			c.RememberFloat(false)
+		}
+	}).Connect(store.X, c.Window.Id)
+	xevent.UnmapNotifyFun(func(X *xgbutil.XUtil, ev xevent.UnmapNotifyEvent) {
+		if tr.isFloating(c.Window.Id) {
+			c.RememberFloat(true)
+		}
+	}).Connect(store.X, c.Window.Id)
+}
+
+func (tr *Tracker) releaseOverflow(ws *Workspace) {
+	policy := common.Config.WindowOverflow
+	if policy != "minimize" && policy != "next" {
+		return
+	}
+
+	// Ignore layouts without slave slots
+	l := ws.ActiveLayout()
+	if name := l.GetName(); name == "maximized" || name == "fullscreen" {
+		return
+	}
+
+	// Minimize clients or move them to the next desktop
+	next := ws.Location.Desktop + 1
+	for _, c := range l.GetManager().Overflow() {
+		switch policy {
+		case "minimize":
+			c.Log().Info("Minimize overflow client")
+			c.Minimize()
+		case "next":
+			if next >= store.Workplace.DesktopCount {
+				continue
+			}
+			c.Log().Info("Move overflow client to desktop ", next)
+			c.MoveToDesktop(uint32(next))
+		}
+	}
+}
+
 func (tr *Tracker) isTracked(w xproto.Window) bool {
 	_, ok := tr.Clients[w]
 	return ok
 }
 
+func (tr *Tracker) isEmptyDesktop(desktop uint) bool {
+	return len(desktopWindows(desktop)) == 0
+}
+
+func (tr *Tracker) isPinned(w xproto.Window) bool {
+	_, ok := tr.Pinned[w]
+	return ok
+}
+
+func (tr *Tracker) isSpanned(w xproto.Window) bool {
+	_, ok := tr.Spanned[w]
+	return ok
+}
+
+func (tr *Tracker) isFloating(w xproto.Window) bool {
+	return tr.Floating[w]
+}
+
+func (tr *Tracker) isIgnored(w xproto.Window) bool {
+	return tr.Ignored[w]
+}
+
+func (tr *Tracker) isClosing(w xproto.Window) bool {
+	_, ok := tr.Closing[w]
+	return ok
+}
+
 func (tr *Tracker) isTrackable(w xproto.Window) bool {
-	info := store.GetInfo(w)
-	return !store.IsSpecial(info) && !store.IsIgnored(info)
+	return tr.isTrackableInfo(store.GetInfo(w))
+}
+
+func (tr *Tracker) isTrackableInfo(info *store.Info) bool {
+	return store.IsManaged(info.Location) && !store.IsSpecial(info) && !store.IsIgnored(info)
+}
+
+func removeWindow(ws []xproto.Window, w xproto.Window) []xproto.Window {
+	windows := []xproto.Window{}
+	for _, window := range ws {
+		if window != w {
+			windows = append(windows, window)
+		}
+	}
+	return windows
+}
+
+func OnGroupUpdate(fun func()) {
+	groupCallbacksFun = append(groupCallbacksFun, fun)
+}
+
+func OnWorkplaceUpdate(fun func()) {
+	workplaceCallbacksFun = append(workplaceCallbacksFun, fun)
+}
+
+func workplaceCallbacks() {
+	for _, fun := range workplaceCallbacksFun {
+		fun()
+	}
+}
+
+func groupCallbacks() {
+	for _, fun := range groupCallbacksFun {
+		fun()
+	}
 }
@@ -2,11 +2,14 @@ package desktop
 
 import (
 	"fmt"
-	"os"
+	"strings"
+	"time"
 
 	"encoding/json"
 	"path/filepath"
 
+	"github.com/jezek/xgb/xproto"
+
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/layout"
 	"github.com/leukipp/cortile/v2/store"
@@ -15,11 +18,19 @@ import (
 )
 
 type Workspace struct {
-	Name     string         // Workspace location name
-	Location store.Location // Desktop and screen location
-	Layouts  []Layout       // List of available layouts
-	Layout   uint           // Active layout index
-	Tiling   bool           // Tiling is enabled
+	Name     string          // Workspace location name
+	Location store.Location  // Desktop and screen location
+	Layouts  []Layout        // List of available layouts
+	Layout   uint            // Active layout index
+	Tiling   bool            // Tiling is enabled
+	Promote  bool            // Focused slaves are promoted to master
+	Frozen   bool            `json:"-"` // Tile positions are kept and new windows float
+	Floated  []xproto.Window `json:"-"` // New windows floated while frozen
+	Zoomed   *store.Client   `json:"-"` // Temporarily maximized client
+	Groups   []*Group        `json:"-"` // Window groups sharing a single tile
+	History  *History        `json:"-"` // Undo and redo history of layout states
+	Paused   *store.Timer    `json:"-"` // Timer to re-enable paused tiling
+	Resume   time.Time       `json:"-"` // Time when paused tiling is re-enabled
 }
 
 func CreateWorkspaces() map[store.Location]*Workspace {
@@ -36,32 +47,34 @@ func CreateWorkspaces() map[store.Location]*Workspace {
 				Layouts:  CreateLayouts(location),
 				Layout:   0,
 				Tiling:   common.Config.TilingEnabled,
+				History:  &History{},
 			}
 
 			// Set default layout
-			for i, l := range ws.Layouts {
-				if l.GetName() == common.Config.TilingLayout {
-					ws.SetLayout(uint(i))
-				}
-			}
+			ws.SetDefaultLayout()
 
 			// Read workspace from cache
 			cached := ws.Read()
 
-			// Overwrite default layout, proportions, decoration and tiling state
+			// Overwrite default layout, proportions, decoration, gap and tiling state
 			ws.SetLayout(cached.Layout)
 			for _, l := range ws.Layouts {
 				for _, cl := range cached.Layouts {
 					if l.GetName() == cl.GetName() {
 						mg, cmg := l.GetManager(), cl.GetManager()
-						mg.Masters.Maximum = common.MinInt(cmg.Masters.Maximum, common.Config.WindowMastersMax)
-						mg.Slaves.Maximum = common.MinInt(cmg.Slaves.Maximum, common.Config.WindowSlavesMax)
+						mg.Masters.Maximum = cmg.Masters.Maximum
+						mg.Slaves.Maximum = cmg.Slaves.Maximum
+						mg.SetAllowed(mg.Masters.Allowed, mg.Slaves.Allowed)
 						mg.Proportions = cmg.Proportions
 						mg.Decoration = cmg.Decoration
+						mg.Gap = cmg.Gap
+						mg.Reversed = cmg.Reversed
+						mg.Stacking = cmg.Stacking
 					}
 				}
 			}
 			ws.Tiling = cached.Tiling
+			ws.Promote = cached.Promote
 
 			// Map location to workspace
 			workspaces[location] = ws
@@ -84,26 +97,60 @@ func CreateLayouts(loc store.Location) []Layout {
 
 func (ws *Workspace) EnableTiling() {
 	ws.Tiling = true
+
+	// Cancel paused tiling
+	ws.Paused.Stop()
+	ws.Paused = nil
 }
 
 func (ws *Workspace) DisableTiling() {
 	ws.Tiling = false
+
+	// Cancel paused tiling
+	ws.Paused.Stop()
+	ws.Paused = nil
+}
+
+func (ws *Workspace) PauseTiling(duration time.Duration, resume func()) {
+	ws.DisableTiling()
+
+	// Re-enable tiling after duration
+	ws.Paused = store.AfterFunc(duration, func() {
+		ws.Paused = nil
+		resume()
+	})
+	ws.Resume = time.Now().Add(duration)
+}
+
+func (ws *Workspace) PauseRemaining() time.Duration {
+	if ws.Paused == nil {
+		return 0
+	}
+	return max(time.Until(ws.Resume), 0)
 }
 
 func (ws *Workspace) TilingEnabled() bool {
-	if ws == nil {
+	if ws == nil || !store.IsManaged(ws.Location) {
 		return false
 	}
 	return ws.Tiling
 }
 
 func (ws *Workspace) TilingDisabled() bool {
-	if ws == nil {
+	if ws == nil || !store.IsManaged(ws.Location) {
 		return true
 	}
 	return !ws.Tiling
 }
 
+func (ws *Workspace) EnablePromote() {
+	ws.Promote = true
+}
+
+func (ws *Workspace) DisablePromote() {
+	ws.Promote = false
+}
+
 func (ws *Workspace) ActiveLayout() Layout {
 	return ws.Layouts[ws.Layout]
 }
@@ -112,6 +159,27 @@ func (ws *Workspace) SetLayout(layout uint) {
 	ws.Layout = layout
 }
 
+func (ws *Workspace) SetDefaultLayout() {
+	name := ws.DefaultLayout()
+
+	for i, l := range ws.Layouts {
+		if l.GetName() == name {
+			ws.SetLayout(uint(i))
+		}
+	}
+}
+
+func (ws *Workspace) DefaultLayout() string {
+	name := common.Config.TilingLayout
+
+	// Obtain layout of desktop
+	if layout, ok := common.Config.Layouts[fmt.Sprint(ws.Location.Desktop+1)]; ok {
+		name = layout
+	}
+
+	return name
+}
+
 func (ws *Workspace) ResetLayouts() {
 
 	// Reset layouts
@@ -120,12 +188,23 @@ func (ws *Workspace) ResetLayouts() {
 		// Reset client decorations
 		mg := l.GetManager()
 		mg.Decoration = common.Config.WindowDecoration
+		mg.Gap = common.Config.WindowGapSize
+		mg.Reversed = false
+		mg.Stacking = common.Config.WindowMasterStack
 
 		// Reset layout proportions
 		l.Reset()
 	}
 }
 
+func (ws *Workspace) UpdateLimits() {
+
+	// Apply allowed number of clients
+	for _, l := range ws.Layouts {
+		l.GetManager().SetAllowed(common.ClientLimits(ws.Location.Desktop, l.GetName()))
+	}
+}
+
 func (ws *Workspace) CycleLayout(dir int) {
 	cycle := common.Config.TilingCycle
 	if len(cycle) == 0 {
@@ -161,22 +240,119 @@ func (ws *Workspace) CycleLayout(dir int) {
 	ws.SetLayout(uint(target))
 }
 
+func (ws *Workspace) Swap(target *Workspace) {
+	ws.Log().WithField("target", target.Name).Info("Swap workspace")
+
+	// Swap layout state
+	ws.Layout, target.Layout = target.Layout, ws.Layout
+	ws.Tiling, target.Tiling = target.Tiling, ws.Tiling
+	ws.Groups, target.Groups = target.Groups, ws.Groups
+
+	// Swap layout clients
+	for i, l := range ws.Layouts {
+		l.GetManager().Swap(target.Layouts[i].GetManager())
+	}
+}
+
+func (ws *Workspace) MirrorLayout(horizontal bool) bool {
+	mirrors := map[string]string{
+		"vertical-left":     "vertical-right",
+		"vertical-right":    "vertical-left",
+		"horizontal-top":    "horizontal-bottom",
+		"horizontal-bottom": "horizontal-top",
+	}
+	name := ws.ActiveLayout().GetName()
+	if _, ok := mirrors[name]; !ok {
+		return false
+	}
+
+	// Flip window order along the stacking axis
+	vertical := strings.HasPrefix(name, "vertical")
+	if horizontal != vertical {
+		mg := ws.ActiveLayout().GetManager()
+		mg.Reversed = !mg.Reversed
+		return true
+	}
+
+	// Switch to mirrored layout
+	for i, l := range ws.Layouts {
+		if l.GetName() == mirrors[name] {
+			ws.SetLayout(uint(i))
+		}
+	}
+
+	return true
+}
+
+func (ws *Workspace) ToggleMasterOrientation() bool {
+	name := ws.ActiveLayout().GetName()
+	if !strings.HasPrefix(name, "horizontal") {
+		return false
+	}
+
+	// Switch between side-by-side and stacked masters
+	mg := ws.ActiveLayout().GetManager()
+	mg.Stacking = !mg.Stacking
+
+	return true
+}
+
 func (ws *Workspace) AddClient(c *store.Client) {
-	log.Info("Add client for each layout [", c.Latest.Class, "]")
+	c.Log().Info("Add client for each layout")
+
+	// Add client to existing window group
+	if ws.joinGroup(c) {
+		return
+	}
 
 	// Add client to all layouts
 	for _, l := range ws.Layouts {
 		l.AddClient(c)
 	}
+
+	// Reset one-shot insertion marker
+	for _, mc := range ws.ActiveLayout().GetManager().Clients(store.Stacked) {
+		if mc.Window.Id == store.InsertMarker {
+			store.InsertMarker = 0
+			break
+		}
+	}
+}
+
+func (ws *Workspace) PlaceClient(c *store.Client, master bool, index int) {
+	c.Log().Info("Place client for each layout")
+
+	// Place raised client of window group
+	if g := ws.ClientGroup(c); g != nil {
+		c = g.Active
+	}
+
+	// Place client in all layouts
+	for _, l := range ws.Layouts {
+		l.GetManager().PlaceClient(c, master, index)
+	}
 }
 
 func (ws *Workspace) RemoveClient(c *store.Client) {
-	log.Info("Remove client from each layout [", c.Latest.Class, "]")
+	c.Log().Info("Remove client from each layout")
+
+	// Remove client from window group
+	ws.leaveGroup(c)
 
 	// Remove client from all layouts
 	for _, l := range ws.Layouts {
 		l.RemoveClient(c)
 	}
+
+	// Reset one-shot insertion marker
+	if store.InsertMarker == c.Window.Id {
+		store.InsertMarker = 0
+	}
+
+	// Reset temporarily maximized client
+	if ws.Zoomed == c {
+		ws.Zoomed = nil
+	}
 }
 
 func (ws *Workspace) VisibleClients() []*store.Client {
@@ -204,26 +380,82 @@ func (ws *Workspace) Tile() {
 		if c == nil {
 			continue
 		}
-		if mg.DecorationEnabled() {
-			if c.Decorate() {
-				c.Update()
-			}
-		} else {
-			if c.UnDecorate() {
-				c.Update()
-			}
-		}
+		decorateClient(mg, c)
 	}
 
 	// Apply active layout
+	store.BeginTransitions()
+	defer store.EndTransitions()
+	ws.reserveTabs(mg)
 	ws.ActiveLayout().Apply()
+
+	// Stack grouped clients into shared tiles
+	ws.tileGroups(mg)
+
+	// Expand temporarily maximized client
+	if ws.Zoomed != nil {
+		x, y, w, h := mg.Geometry().Pieces()
+		gap := mg.Gap
+
+		ws.Zoomed.MoveWindow(x+gap, y+gap, w-2*gap, h-2*gap)
+		ws.Zoomed.Raise()
+	}
 }
 
-func (ws *Workspace) Restore(flag uint8) {
+func (ws *Workspace) Predict(c *store.Client) (common.Geometry, bool) {
+	if ws.TilingDisabled() || ws.Frozen || ws.Zoomed != nil {
+		return common.Geometry{}, false
+	}
 	mg := ws.ActiveLayout().GetManager()
 	clients := mg.Clients(store.Stacked)
 
-	log.Info("Untile ", len(clients), " windows [", ws.Name, "]")
+	// Skip layouts without individual tiles and crowded workspaces
+	if !strings.HasPrefix(mg.Name, "vertical") && !strings.HasPrefix(mg.Name, "horizontal") {
+		return common.Geometry{}, false
+	}
+	if limit := common.Config.WindowTiledMax; limit > 0 && len(clients) >= limit {
+		return common.Geometry{}, false
+	}
+
+	// Add client to copies of the layout stacks
+	masters, slaves := mg.Masters.Stacked, mg.Slaves.Stacked
+	mg.Masters.Stacked = append([]*store.Client{}, masters...)
+	mg.Slaves.Stacked = append([]*store.Client{}, slaves...)
+	mg.AddClient(c)
+
+	// Apply active layout without moving windows
+	store.BeginPrediction()
+	ws.ActiveLayout().Apply()
+	predictions := store.EndPrediction()
+
+	// Restore layout stacks
+	mg.Masters.Stacked, mg.Slaves.Stacked = masters, slaves
+
+	geom, ok := predictions[c.Window.Id]
+
+	return geom, ok
+}
+
+func (ws *Workspace) ToggleZoom(c *store.Client) bool {
+	if ws.Zoomed != nil {
+		ws.Zoomed = nil
+		return true
+	}
+	if c == nil {
+		return false
+	}
+
+	// Set temporarily maximized client
+	ws.Zoomed = c
+
+	return true
+}
+
+func (ws *Workspace) Restore(flag uint8) {
+	mg := ws.ActiveLayout().GetManager()
+	clients := append(append([]*store.Client{}, mg.Clients(store.Stacked)...), ws.hiddenMembers()...)
+
+	ws.Log().Info("Untile ", len(clients), " windows")
 
 	// Restore client dimensions
 	for _, c := range clients {
@@ -245,19 +477,18 @@ func (ws *Workspace) Write() {
 	// Parse workspace cache
 	data, err := json.MarshalIndent(cache.Data, "", "  ")
 	if err != nil {
-		log.Warn("Error parsing workspace cache [", ws.Name, "]")
+		ws.Log().Warn("Error parsing workspace cache")
 		return
 	}
 
 	// Write workspace cache
-	path := filepath.Join(cache.Folder, cache.Name)
-	err = os.WriteFile(path, data, 0644)
+	err = cache.Write(data)
 	if err != nil {
-		log.Warn("Error writing workspace cache [", ws.Name, "]")
+		ws.Log().Warn("Error writing workspace cache")
 		return
 	}
 
-	log.Trace("Write workspace cache data ", cache.Name, " [", ws.Name, "]")
+	ws.Log().Trace("Write workspace cache data ", cache.Key)
 }
 
 func (ws *Workspace) Read() *Workspace {
@@ -269,22 +500,21 @@ func (ws *Workspace) Read() *Workspace {
 	cache := ws.Cache()
 
 	// Read workspace cache
-	path := filepath.Join(cache.Folder, cache.Name)
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		log.Info("No workspace cache found [", ws.Name, "]")
+	data, err := cache.Read()
+	if err != nil {
+		ws.Log().Info("No workspace cache found")
 		return ws
 	}
 
 	// Parse workspace cache
 	cached := &Workspace{Layouts: CreateLayouts(ws.Location)}
-	err = json.Unmarshal([]byte(data), &cached)
+	err = json.Unmarshal(data, &cached)
 	if err != nil {
-		log.Warn("Error reading workspace cache [", ws.Name, "]")
+		ws.Log().Warn("Error reading workspace cache")
 		return ws
 	}
 
-	log.Debug("Read workspace cache data ", cache.Name, " [", ws.Name, "]")
+	ws.Log().Debug("Read workspace cache data ", cache.Key)
 
 	return cached
 }
@@ -293,18 +523,31 @@ func (ws *Workspace) Cache() common.Cache[*Workspace] {
 	subfolder := fmt.Sprintf("workspace-%d", ws.Location.Desktop)
 	filename := fmt.Sprintf("%s-%d", subfolder, ws.Location.Screen)
 
-	// Create workspace cache folder
-	folder := filepath.Join(common.Args.Cache, "workplaces", store.Workplace.Displays.Name, "workspaces", subfolder)
-	if _, err := os.Stat(folder); os.IsNotExist(err) {
-		os.MkdirAll(folder, 0755)
-	}
-
 	// Create workspace cache object
+	folder := filepath.Join("workplaces", store.Workplace.Displays.Name, "workspaces")
 	cache := common.Cache[*Workspace]{
-		Folder: folder,
-		Name:   common.HashString(filename, 20) + ".json",
+		Bucket: folder,
+		Key:    filename,
 		Data:   ws,
 	}
 
 	return cache
 }
+
+func decorateClient(mg *store.Manager, c *store.Client) {
+	if mg.DecorationEnabled() {
+		if c.Decorate() {
+			c.Update()
+		}
+	} else {
+		if c.UnDecorate() {
+			c.Update()
+		}
+	}
+}
+
+func (ws *Workspace) Log() *log.Entry {
+	return log.WithFields(log.Fields{
+		"workspace": ws.Name,
+	})
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"path/filepath"
+
+	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/keybind"
+	"github.com/jezek/xgbutil/xprop"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+type finding struct {
+	Level   string // Finding severity level
+	Topic   string // Finding topic name
+	Message string // Finding description
+}
+
+var (
+	findings []finding // Collected doctor findings
+)
+
+func runDoctor() {
+	if !common.Args.Doctor {
+		return
+	}
+
+	// Run diagnostic checks
+	running := checkProcess()
+	checkConfig()
+	checkCache()
+	if X := checkServer(); X != nil {
+		checkDisplays(X)
+		checkKeys(X, running)
+	}
+
+	// Print diagnostic findings
+	errors, warnings := 0, 0
+	fmt.Println("DOCTOR: ")
+	for _, f := range findings {
+		fmt.Printf("  %-7s %s: %s\n", f.Level, f.Topic, f.Message)
+		switch f.Level {
+		case "error":
+			errors++
+		case "warning":
+			warnings++
+		}
+	}
+	fmt.Printf("\nSUMMARY: \n  errors: %d\n  warnings: %d\n", errors, warnings)
+
+	// Prevent main instance start
+	if errors > 0 {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func checkProcess() bool {
+
+	// Check lock file
+	file, err := createLockFile(common.Args.Lock)
+	if err != nil {
+		report("ok", "process", fmt.Sprintf("%s is running (%s)", common.Build.Name, common.Args.Lock))
+		return true
+	}
+	if file != nil {
+		file.Close()
+	}
+	report("ok", "process", fmt.Sprintf("%s is not running", common.Build.Name))
+
+	return false
+}
+
+func checkConfig() {
+	path := common.Args.Config
+
+	// Check config file
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		report("ok", "config", fmt.Sprintf("%s does not exist, default config is written on first start", path))
+	}
+
+	// Decode config file
+	_, err := common.DecodeConfig(path)
+	if err != nil {
+		report("error", "config", fmt.Sprintf("%s can't be parsed, run '%s check-config' (%s)", path, common.Build.Name, err))
+		return
+	}
+
+	// Validate config values
+	errs := common.ValidateConfig(path)
+	for _, err := range errs {
+		report("warning", "config", err.Error())
+	}
+	if len(errs) == 0 {
+		report("ok", "config", fmt.Sprintf("%s is valid", path))
+	}
+}
+
+func checkCache() {
+	path := common.Args.Cache
+
+	// Check cache disabled
+	if common.CacheDisabled() || common.HasFlag("disable-cache-folder") {
+		report("ok", "cache", "cache is disabled")
+		return
+	}
+
+	// Check cache folder
+	if err := os.MkdirAll(path, 0755); err != nil {
+		report("error", "cache", fmt.Sprintf("%s can't be created, check folder permissions (%s)", path, err))
+		return
+	}
+
+	// Check cache folder permissions
+	file, err := os.CreateTemp(path, ".doctor-*")
+	if err != nil {
+		report("error", "cache", fmt.Sprintf("%s is not writable, check folder permissions (%s)", path, err))
+		return
+	}
+	file.Close()
+	os.Remove(file.Name())
+
+	// Check cache database permissions
+	database := filepath.Join(path, "cache.db")
+	if _, err := os.Stat(database); err == nil {
+		file, err := os.OpenFile(database, os.O_RDWR, 0644)
+		if err != nil {
+			report("error", "cache", fmt.Sprintf("%s is not writable, check file permissions (%s)", database, err))
+			return
+		}
+		file.Close()
+	}
+	report("ok", "cache", fmt.Sprintf("%s is writable", path))
+}
+
+func checkServer() *xgbutil.XUtil {
+
+	// Check X connection
+	X, err := xgbutil.NewConn()
+	if err != nil {
+		report("error", "server", fmt.Sprintf("connection to X server failed, check the DISPLAY variable (%s)", err))
+		return nil
+	}
+	report("ok", "server", fmt.Sprintf("connected to X server on %s", os.Getenv("DISPLAY")))
+
+	// Check window manager
+	store.X = X
+	store.Server = store.CreateX11Backend(X)
+	name, err := store.Server.GetEwmhWM()
+	if err != nil {
+		report("error", "server", fmt.Sprintf("window manager is not EWMH compliant, use a window manager that sets _NET_SUPPORTING_WM_CHECK (%s)", err))
+		return nil
+	}
+	store.WindowManager = &store.XWindowManager{Name: name, Wayland: store.Wayland(X)}
+	report("ok", "server", fmt.Sprintf("window manager is %s", name))
+
+	// Check window manager compatibility
+	if store.WindowManager.Wayland {
+		report("warning", "server", "running on XWayland, only X11 windows are managed and hot corners are disabled")
+	}
+	for _, quirk := range store.ActiveQuirks() {
+		report("warning", "server", fmt.Sprintf("%s quirk %s: %s", name, quirk, common.Quirks[quirk].Description))
+	}
+
+	// Check supported hints
+	supported, err := ewmh.SupportedGet(X)
+	if err != nil {
+		report("warning", "server", fmt.Sprintf("_NET_SUPPORTED can't be read, features may not work (%s)", err))
+	} else {
+		missing := []string{}
+		for _, atom := range []string{
+			"_NET_NUMBER_OF_DESKTOPS",
+			"_NET_CURRENT_DESKTOP",
+			"_NET_ACTIVE_WINDOW",
+			"_NET_CLIENT_LIST_STACKING",
+			"_NET_MOVERESIZE_WINDOW",
+			"_NET_WM_DESKTOP",
+			"_NET_WM_STATE",
+			"_NET_WM_STATE_FULLSCREEN",
+			"_NET_WM_STATE_MAXIMIZED_VERT",
+			"_NET_WM_STATE_MAXIMIZED_HORZ",
+			"_NET_WM_STRUT_PARTIAL",
+			"_NET_WM_WINDOW_TYPE",
+			"_NET_FRAME_EXTENTS",
+		} {
+			if !common.IsInList(atom, supported) {
+				missing = append(missing, atom)
+			}
+		}
+		if len(missing) > 0 {
+			report("warning", "server", fmt.Sprintf("window manager does not announce %s, related features may not work", strings.Join(missing, ", ")))
+		} else {
+			report("ok", "server", "all required EWMH hints are supported")
+		}
+	}
+
+	// Check compositor
+	atom, err := xprop.Atm(X, fmt.Sprintf("_NET_WM_CM_S%d", X.Conn().DefaultScreen))
+	if err == nil {
+		owner, err := xproto.GetSelectionOwner(X.Conn(), atom).Reply()
+		if err == nil && owner.Owner != 0 {
+			report("ok", "server", "compositor is running")
+		} else {
+			report("warning", "server", "no compositor is running, the layout overlay is drawn without transparency")
+		}
+	}
+
+	return X
+}
+
+func checkDisplays(X *xgbutil.XUtil) {
+
+	// Check randr extension
+	if err := randr.Init(X.Conn()); err != nil {
+		report("error", "displays", fmt.Sprintf("RandR extension is not available, screens can't be detected (%s)", err))
+		return
+	}
+
+	// Check physical heads
+	heads := store.PhysicalHeadsGet(X)
+	if len(heads) == 0 {
+		report("error", "displays", "no connected and active outputs found, check the RandR configuration with xrandr")
+		return
+	}
+	for i, head := range heads {
+		x, y, w, h := head.Geometry.Pieces()
+		primary := ""
+		if head.Primary {
+			primary = ", primary"
+		}
+		report("ok", "displays", fmt.Sprintf("screen %d is %s (%dx%d+%d+%d%s)", i, head.Name, w, h, x, y, primary))
+
+		// Check mirrored heads
+		for _, other := range heads[:i] {
+			if head.Geometry.X == other.Geometry.X && head.Geometry.Y == other.Geometry.Y {
+				report("warning", "displays", fmt.Sprintf("%s and %s are overlapping, mirrored screens are tiled as one", other.Name, head.Name))
+			}
+		}
+	}
+}
+
+func checkKeys(X *xgbutil.XUtil, running bool) {
+	keybind.Initialize(X)
+
+	actions := map[string]string{}
+	mods := map[string]string{"current": ""}
+
+	// Map actions and modifiers
+	for c, ck := range common.Config.Keys {
+		if len(strings.TrimSpace(ck)) == 0 {
+			continue
+		}
+		if !strings.HasPrefix(c, "mod_") {
+			actions[c] = strings.Fields(ck)[0]
+		} else {
+			mods[c[4:]] = ck
+		}
+	}
+
+	// Check keyboard shortcuts
+	conflicts := 0
+	for a, ak := range actions {
+		for _, mk := range mods {
+			key := ak
+			if len(mk) > 0 {
+				key = mk + "-" + ak
+			}
+
+			// Check key syntax
+			mod, codes, err := keybind.ParseString(X, key)
+			if err != nil {
+				report("error", "keys", fmt.Sprintf("'%s' of action '%s' is not a valid key, check the spelling with xev", key, a))
+				conflicts++
+				continue
+			}
+			if running {
+				continue
+			}
+
+			// Check key grabs
+			for _, code := range codes {
+				if err := keybind.GrabChecked(X, X.RootWin(), mod, code); err != nil {
+					report("warning", "keys", fmt.Sprintf("'%s' of action '%s' is already grabbed by another application, change one of the bindings", key, a))
+					conflicts++
+					break
+				}
+				keybind.Ungrab(X, X.RootWin(), mod, code)
+			}
+		}
+	}
+
+	// Check key conflicts
+	if running {
+		report("ok", "keys", fmt.Sprintf("conflict check skipped while %s is running", common.Build.Name))
+	} else if conflicts == 0 {
+		report("ok", "keys", "no keybinding conflicts found")
+	}
+}
+
+func report(level string, topic string, message string) {
+	findings = append(findings, finding{Level: level, Topic: topic, Message: message})
+}
@@ -1,14 +1,16 @@
 package input
 
 import (
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"os/exec"
 
-	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgb/xproto"
 
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
@@ -20,6 +22,12 @@ import (
 
 var (
 	executeCallbacksFun []func(string, uint, uint) // Execute events callback functions
+	killCandidate       *store.Client              // Unresponsive client offered to kill
+)
+
+var (
+	killDelay      time.Duration = 3 * time.Second  // Waiting time for closing clients until kill is offered
+	inspectTimeout time.Duration = 15 * time.Second // Waiting time for picked windows of dbus inspections
 )
 
 func Bind(tr *desktop.Tracker) {
@@ -29,6 +37,12 @@ func Bind(tr *desktop.Tracker) {
 	BindTray(tr)
 	BindDbus(tr)
 	BindAddons(tr)
+	BindGestures(tr)
+	BindButtons(tr)
+	BindTabs(tr)
+	BindIndicator(tr)
+	BindSchedule(tr)
+	BindDock(tr)
 }
 
 func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bool {
@@ -37,7 +51,17 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		return false
 	}
 
-	log.Info("Execute action ", action, " [", ws.Name, "]")
+	ws.Log().WithField("action", action).Info("Execute action")
+
+	// Trace executed action
+	if c := tr.ActiveClient(); c != nil {
+		c.Trace("Action", action)
+	} else {
+		store.TraceEvent(0, "", "Action", action)
+	}
+
+	// Remember workspace state
+	snapshot := ws.Snapshot()
 
 	// Choose action command
 	switch action {
@@ -49,6 +73,36 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		success = ToggleTiling(tr, ws)
 	case "decoration":
 		success = ToggleDecoration(tr, ws)
+	case "toggle_maximize":
+		success = ToggleMaximize(tr, ws)
+	case "toggle_span":
+		success = ToggleSpan(tr, ws)
+	case "toggle_pip":
+		success = TogglePip(tr, ws)
+	case "toggle_float":
+		success = ToggleFloat(tr, ws)
+	case "ignore_window":
+		success = IgnoreWindow(tr)
+	case "close_window":
+		success = CloseWindow(tr)
+	case "window_menu":
+		success = WindowMenu(tr, ws)
+	case "kill_window":
+		success = KillWindow(tr, ws)
+	case "kill_window_confirm":
+		success = KillWindowConfirm()
+	case "teleport":
+		success = Teleport(tr, ws)
+	case "toggle_freeze":
+		success = ToggleFreeze(tr, ws)
+	case "toggle_large_print":
+		success = ToggleLargePrint(tr, ws)
+	case "toggle_tiling_for_class":
+		success = ToggleClassTiling(tr, ws)
+	case "insert_here":
+		success = InsertHere(tr, ws)
+	case "promote":
+		success = TogglePromote(tr, ws)
 	case "restore":
 		success = Restore(tr, ws)
 	case "reset":
@@ -69,6 +123,12 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		success = MaximizedLayout(tr, ws)
 	case "layout_fullscreen":
 		success = FullscreenLayout(tr, ws)
+	case "mirror_horizontal":
+		success = MirrorHorizontal(tr, ws)
+	case "mirror_vertical":
+		success = MirrorVertical(tr, ws)
+	case "master_orientation":
+		success = ToggleMasterOrientation(tr, ws)
 	case "slave_increase":
 		success = IncreaseSlave(tr, ws)
 	case "slave_decrease":
@@ -81,10 +141,54 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		success = NextWindow(tr, ws)
 	case "window_previous":
 		success = PreviousWindow(tr, ws)
+	case "group_next":
+		success = NextGroupMember(tr, ws)
+	case "group_previous":
+		success = PreviousGroupMember(tr, ws)
+	case "focus_master":
+		success = FocusMaster(tr, ws, "1")
+	case "focus_slave":
+		success = FocusSlave(tr, ws, "1")
+	case "focus_previous_window":
+		success = FocusPreviousWindow(tr, ws)
+	case "focus_last_urgent":
+		success = FocusLastUrgent(tr, ws)
+	case "switcher_next":
+		success = ui.ShowSwitcher(tr, ws, 1)
+	case "switcher_previous":
+		success = ui.ShowSwitcher(tr, ws, -1)
 	case "screen_next":
 		success = NextScreen(tr, ws)
 	case "screen_previous":
 		success = PreviousScreen(tr, ws)
+	case "move_to_screen_left":
+		success = MoveToScreen(tr, "left")
+	case "move_to_screen_right":
+		success = MoveToScreen(tr, "right")
+	case "move_to_screen_up":
+		success = MoveToScreen(tr, "up")
+	case "move_to_screen_down":
+		success = MoveToScreen(tr, "down")
+	case "focus_screen_left":
+		success = FocusScreen(tr, ws, "left")
+	case "focus_screen_right":
+		success = FocusScreen(tr, ws, "right")
+	case "focus_screen_up":
+		success = FocusScreen(tr, ws, "up")
+	case "focus_screen_down":
+		success = FocusScreen(tr, ws, "down")
+	case "swap_screen_left":
+		success = SwapScreen(tr, ws, "left")
+	case "swap_screen_right":
+		success = SwapScreen(tr, ws, "right")
+	case "swap_screen_up":
+		success = SwapScreen(tr, ws, "up")
+	case "swap_screen_down":
+		success = SwapScreen(tr, ws, "down")
+	case "rotate_clients_forward":
+		success = RotateClientsForward(tr, ws)
+	case "rotate_clients_backward":
+		success = RotateClientsBackward(tr, ws)
 	case "master_make":
 		success = MakeMaster(tr, ws)
 	case "master_make_next":
@@ -95,12 +199,64 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		success = IncreaseProportion(tr, ws)
 	case "proportion_decrease":
 		success = DecreaseProportion(tr, ws)
+	case "expand_master":
+		success = ResizeMaster(tr, ws, common.Config.ProportionStep)
+	case "shrink_master":
+		success = ResizeMaster(tr, ws, -common.Config.ProportionStep)
+	case "fix_size":
+		success = FixSize(tr, ws)
+	case "grow_window":
+		success = ResizeWindow(tr, ws, common.Config.ProportionStep)
+	case "shrink_window":
+		success = ResizeWindow(tr, ws, -common.Config.ProportionStep)
+	case "gap_increase":
+		success = ChangeGap(tr, ws, common.Config.WindowGapStep)
+	case "gap_decrease":
+		success = ChangeGap(tr, ws, -common.Config.WindowGapStep)
+	case "gap_toggle":
+		success = ToggleGap(tr, ws)
+	case "undo_tiling":
+		success = UndoTiling(tr, ws)
+	case "redo_tiling":
+		success = RedoTiling(tr, ws)
+	case "profile_next":
+		success = NextProfile(tr)
+	case "profile_previous":
+		success = PreviousProfile(tr)
+	case "desktop_add":
+		success = AddDesktop(tr)
+	case "desktop_remove":
+		success = RemoveDesktop(tr)
+	case "apply":
+		success = ApplyDesiredState(tr)
+	case "schedule_pause":
+		success = PauseSchedule(!schedulePaused)
+	case "presentation_mode":
+		success = TogglePresentation(tr)
+	case "dump_events":
+		success = DumpEvents()
+	case "calibrate_extents":
+		success = CalibrateExtents(tr)
+	case "inspect":
+		success = InspectWindow(tr, nil)
 	case "restart":
 		success = Restart(tr)
 	case "exit":
 		success = Exit(tr)
 	default:
-		success = External(action)
+		if strings.HasPrefix(action, "move_to_workspace_") {
+			success = MoveToWorkspace(tr, strings.TrimPrefix(action, "move_to_workspace_"))
+		} else if strings.HasPrefix(action, "teleport_to_") {
+			success = TeleportTo(tr, ws, strings.TrimPrefix(action, "teleport_to_"))
+		} else if strings.HasPrefix(action, "pause_tiling") {
+			success = PauseTiling(tr, ws, strings.Trim(strings.TrimPrefix(action, "pause_tiling"), " _"))
+		} else if strings.HasPrefix(action, "focus_master_") {
+			success = FocusMaster(tr, ws, strings.TrimPrefix(action, "focus_master_"))
+		} else if strings.HasPrefix(action, "focus_slave_") {
+			success = FocusSlave(tr, ws, strings.TrimPrefix(action, "focus_slave_"))
+		} else {
+			success = External(action)
+		}
 	}
 	time.AfterFunc(100*time.Millisecond, tr.Handlers.Reset)
 
@@ -109,6 +265,11 @@ func ExecuteAction(action string, tr *desktop.Tracker, ws *desktop.Workspace) bo
 		return false
 	}
 
+	// Add changed state to history
+	if !common.IsInList(action, []string{"undo_tiling", "redo_tiling"}) {
+		ws.Remember(snapshot)
+	}
+
 	// Execute callbacks
 	executeCallbacks(action, ws.Location.Desktop, ws.Location.Screen)
 
@@ -157,6 +318,37 @@ func EnableTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return true
 }
 
+func PauseTiling(tr *desktop.Tracker, ws *desktop.Workspace, param string) bool {
+	if ws.TilingDisabled() && ws.Paused == nil {
+		return false
+	}
+
+	// Parse duration (e.g. 90, 30s, 5m)
+	duration, err := time.ParseDuration(param)
+	if seconds, e := strconv.Atoi(param); e == nil {
+		duration, err = time.Duration(seconds)*time.Second, nil
+	}
+	if err != nil || duration <= 0 {
+		log.Warn("Error parsing pause duration \"", param, "\"")
+		return false
+	}
+
+	// Untile windows until resumed
+	if ws.TilingEnabled() {
+		tr.Restore(ws, store.Latest)
+	}
+	ws.PauseTiling(duration, func() {
+		ExecuteAction("enable", tr, ws)
+	})
+
+	ws.Log().Info("Pause tiling for ", duration)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
 func DisableTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -217,6 +409,328 @@ func ToggleDecoration(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return DisableDecoration(tr, ws)
 }
 
+func TogglePromote(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if ws.Promote {
+		ws.DisablePromote()
+	} else {
+		ws.EnablePromote()
+	}
+	ws.Write()
+
+	return true
+}
+
+func ToggleMaximize(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.ToggleZoom(ws.ActiveLayout().ActiveClient()) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func TogglePip(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+
+	// Unpin active window
+	if tr.UnPin(store.Windows.Active.Id) {
+		return true
+	}
+	if ws.TilingDisabled() {
+		return false
+	}
+
+	// Pin active window
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	return tr.Pin(c)
+}
+
+func ToggleSpan(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+
+	// Unspan active window
+	if tr.UnSpan(store.Windows.Active.Id) {
+		return true
+	}
+	if ws.TilingDisabled() {
+		return false
+	}
+
+	// Span active window across monitors
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+	edges, ok := store.FullscreenMonitors(common.Config.WindowSpanScreens)
+	if !ok {
+		log.Warn("No monitors found to span window across ", common.Config.WindowSpanScreens)
+		return false
+	}
+
+	return tr.Span(c, edges)
+}
+
+func Teleport(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() || tr.ClientWorkspace(tr.ActiveClient()) != ws {
+		return false
+	}
+	clients := ws.ActiveLayout().GetManager().Clients(store.Visible)
+	if len(clients) < 2 {
+		return false
+	}
+
+	// Wait for slot number of visible tiles
+	continuations := map[string]string{}
+	for i := range clients[:common.MinInt(len(clients), 9)] {
+		continuations[strconv.Itoa(i+1)] = fmt.Sprintf("teleport_to_%d", i+1)
+	}
+	if !grabChord("teleport", continuations, "current", tr) {
+		return false
+	}
+	ui.ShowSlots(ws, clients[:len(continuations)])
+
+	return true
+}
+
+func TeleportTo(tr *desktop.Tracker, ws *desktop.Workspace, slot string) bool {
+	c := tr.ActiveClient()
+	if ws.TilingDisabled() || c == nil || tr.ClientWorkspace(c) != ws {
+		return false
+	}
+	mg := ws.ActiveLayout().GetManager()
+
+	// Swap active client with slot occupant
+	clients := mg.Clients(store.Visible)
+	index, err := strconv.Atoi(slot)
+	if err != nil || index < 1 || index > len(clients) {
+		return false
+	}
+	target := clients[index-1]
+	if target == nil || target.Window.Id == c.Window.Id {
+		return false
+	}
+	mg.SwapClient(c, target)
+	tr.Tile(ws)
+
+	return true
+}
+
+func ToggleFreeze(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	ws.Log().Info("Update layout freeze to ", !ws.Frozen)
+
+	// Freeze or retile unfrozen workspace
+	if ws.Frozen {
+		tr.Unfreeze(ws)
+	} else {
+		ws.Frozen = true
+	}
+
+	ui.ShowLayout(ws)
+
+	return true
+}
+
+func ApplyDesiredState(tr *desktop.Tracker) bool {
+	state, err := common.ReadDesiredState()
+	if err != nil {
+		log.Warn("Error reading desired state: ", err)
+		return false
+	}
+
+	// Reconcile workspaces to desired state
+	if tr.Reconcile(state) == 0 {
+		return false
+	}
+
+	ws := tr.ActiveWorkspace()
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func WindowMenu(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	// Collect window actions
+	entries := []ui.MenuEntry{{Action: "toggle_float", Label: "Float"}}
+	if ws.TilingEnabled() {
+		entries = append(entries, ui.MenuEntry{Action: "master_make", Label: "Make master"})
+	}
+	for d := uint(0); d < store.Workplace.DesktopCount; d++ {
+		if d != c.Latest.Location.Desktop {
+			entries = append(entries, ui.MenuEntry{Action: fmt.Sprintf("move_to_workspace_%d", d+1), Label: fmt.Sprintf("Move to workspace %d", d+1)})
+		}
+	}
+	if c.Latest.Location.Screen+1 < store.Workplace.ScreenCount {
+		entries = append(entries, ui.MenuEntry{Action: "screen_next", Label: "Move to next screen"})
+	}
+	if c.Latest.Location.Screen > 0 {
+		entries = append(entries, ui.MenuEntry{Action: "screen_previous", Label: "Move to previous screen"})
+	}
+	entries = append(entries, ui.MenuEntry{Action: "close_window", Label: "Close"})
+
+	// Show menu next to active window
+	return ui.ShowMenu(c, entries, func(action string) {
+		ExecuteAction(action, tr, tr.ActiveWorkspace())
+	})
+}
+
+func ToggleLargePrint(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	common.Config.GuiLargePrint = !common.Config.GuiLargePrint
+
+	log.Info("Update large print mode to ", common.Config.GuiLargePrint)
+
+	ui.ShowLayout(ws)
+
+	return true
+}
+
+func ToggleFloat(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+
+	// Unfloat active window
+	if tr.UnFloat(store.Windows.Active.Id) {
+		return true
+	}
+	if ws.TilingDisabled() {
+		return false
+	}
+
+	// Float active window
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	return tr.Float(c)
+}
+
+func IgnoreWindow(tr *desktop.Tracker) bool {
+
+	// Ignore tracked window until closed
+	if c := tr.ActiveClient(); c != nil {
+		return tr.Ignore(c)
+	}
+
+	return false
+}
+
+func CloseWindow(tr *desktop.Tracker) bool {
+
+	// Close tracked window and retile
+	if c := tr.ActiveClient(); c != nil {
+		return tr.Close(c)
+	}
+
+	// Close untracked window
+	w := store.Windows.Active.Id
+	if w == 0 {
+		return false
+	}
+
+	return store.Server.CloseWindow(w) == nil
+}
+
+func KillWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	// Close window and check responsiveness
+	pinged := c.Ping()
+	tr.Close(c)
+
+	// Offer to kill remaining window after delay
+	store.AfterFunc(killDelay, func() {
+		if _, err := store.Server.RawGeometry(c.Window.Id); err != nil {
+			return
+		}
+		if pinged && c.Responding() {
+			return
+		}
+		continuations := map[string]string{"y": "kill_window_confirm"}
+		if !grabChord("kill_window", continuations, "current", tr) {
+			return
+		}
+		killCandidate = c
+		ui.ShowPrompt(ws, []string{
+			fmt.Sprintf("%s is not responding", c.Latest.Class),
+			"y: kill window",
+			"other key: cancel",
+		})
+	})
+
+	return true
+}
+
+func KillWindowConfirm() bool {
+	c := killCandidate
+	killCandidate = nil
+	if c == nil {
+		return false
+	}
+
+	// Kill unresponsive client
+	return c.Kill()
+}
+
+func ToggleClassTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	info := store.GetInfo(store.Windows.Active.Id)
+	if len(info.Class) == 0 {
+		return false
+	}
+	return ExemptClass(tr, info.Class, !common.IsInList(info.Class, store.Exemptions))
+}
+
+func ExemptClass(tr *desktop.Tracker, class string, exempt bool) bool {
+	if exempt == common.IsInList(class, store.Exemptions) {
+		return false
+	}
+	store.ToggleExemption(class)
+
+	// Retile all workspaces
+	tr.Update()
+	for _, ws := range tr.Workspaces {
+		tr.Tile(ws)
+	}
+
+	return true
+}
+
+func InsertHere(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	// Toggle insertion marker
+	if store.InsertMarker == c.Window.Id {
+		store.InsertMarker = 0
+	} else {
+		store.InsertMarker = c.Window.Id
+	}
+
+	return true
+}
+
 func Restore(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -371,6 +885,50 @@ func FullscreenLayout(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return true
 }
 
+func MirrorHorizontal(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.MirrorLayout(true) {
+		return false
+	}
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func MirrorVertical(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.MirrorLayout(false) {
+		return false
+	}
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func ToggleMasterOrientation(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.ToggleMasterOrientation() {
+		return false
+	}
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+
+	return true
+}
+
 func IncreaseSlave(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -434,12 +992,97 @@ func NextWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return true
 }
 
-func PreviousWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
-	c := ws.ActiveLayout().PreviousClient()
-	if c == nil {
-		return false
-	}
+func NextGroupMember(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	return cycleGroup(tr, ws, 1)
+}
+
+func PreviousGroupMember(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	return cycleGroup(tr, ws, -1)
+}
+
+func cycleGroup(tr *desktop.Tracker, ws *desktop.Workspace, dir int) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+
+	// Raise next or previous tab of active window group
+	c := ws.CycleGroup(tr.ActiveClient(), dir)
+	if c == nil {
+		return false
+	}
+	tr.Tile(ws)
+
+	store.ActiveWindowSet(store.X, c.Window)
+
+	return true
+}
+
+func FocusMaster(tr *desktop.Tracker, ws *desktop.Workspace, target string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	mg := ws.ActiveLayout().GetManager()
+
+	return focusIndex(mg.Masters.Stacked, target)
+}
+
+func FocusSlave(tr *desktop.Tracker, ws *desktop.Workspace, target string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	mg := ws.ActiveLayout().GetManager()
+
+	return focusIndex(mg.Slaves.Stacked, target)
+}
+
+func focusIndex(clients []*store.Client, target string) bool {
+
+	// Obtain client by one based index
+	index, err := strconv.Atoi(target)
+	if err != nil || index < 1 || index > len(clients) {
+		return false
+	}
+	c := clients[index-1]
+
+	store.ActiveWindowSet(store.X, c.Window)
+
+	return true
+}
 
+func PreviousWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	c := ws.ActiveLayout().PreviousClient()
+	if c == nil {
+		return false
+	}
+
+	store.ActiveWindowSet(store.X, c.Window)
+
+	return true
+}
+
+func FocusPreviousWindow(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+
+	// Obtain most recently used window
+	for _, c := range tr.FocusHistory(ws) {
+		if c.Window.Id != store.Windows.Active.Id {
+			store.ActiveWindowSet(store.X, c.Window)
+			return true
+		}
+	}
+
+	return false
+}
+
+func FocusLastUrgent(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	c := tr.UrgentClient()
+	if c == nil {
+		return false
+	}
+
+	// Switch to desktop of urgent window
+	if c.Latest.Location.Desktop != store.Workplace.CurrentDesktop {
+		store.CurrentDesktopSet(store.X, c.Latest.Location.Desktop)
+	}
 	store.ActiveWindowSet(store.X, c.Window)
 
 	return true
@@ -473,6 +1116,103 @@ func PreviousScreen(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return c.MoveToScreen(uint32(screen))
 }
 
+func MoveToScreen(tr *desktop.Tracker, direction string) bool {
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	screen, ok := store.ScreenNeighbor(c.Latest.Location.Screen, direction)
+	if !ok {
+		return false
+	}
+
+	return c.MoveToScreen(uint32(screen))
+}
+
+func FocusScreen(tr *desktop.Tracker, ws *desktop.Workspace, direction string) bool {
+	screen, ok := store.ScreenNeighbor(ws.Location.Screen, direction)
+	if !ok {
+		return false
+	}
+
+	// Focus topmost client on target screen
+	stacked := store.Windows.Stacked
+	for i := len(stacked) - 1; i >= 0; i-- {
+		c, ok := tr.Clients[stacked[i].Id]
+		if !ok || c.Latest.Location.Desktop != ws.Location.Desktop || c.Latest.Location.Screen != screen {
+			continue
+		}
+		store.ActiveWindowSet(store.X, c.Window)
+		return true
+	}
+
+	return false
+}
+
+func SwapScreen(tr *desktop.Tracker, ws *desktop.Workspace, direction string) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	screen, ok := store.ScreenNeighbor(ws.Location.Screen, direction)
+	if !ok {
+		return false
+	}
+	target := tr.WorkspaceAt(ws.Location.Desktop, screen)
+	if target.TilingDisabled() {
+		return false
+	}
+
+	// Swap workspaces and tile both
+	ws.Swap(target)
+	tr.Tile(ws)
+	tr.Tile(target)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func MoveToWorkspace(tr *desktop.Tracker, target string) bool {
+	c := tr.ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	// Follow client to target desktop
+	follow := strings.HasSuffix(target, "_follow")
+	target = strings.TrimSuffix(target, "_follow")
+
+	// Calculate target desktop
+	count := int(store.Workplace.DesktopCount)
+	desktop := int(c.Latest.Location.Desktop)
+	switch target {
+	case "next":
+		desktop = (desktop + 1) % count
+	case "previous":
+		desktop = (desktop - 1 + count) % count
+	default:
+		desktop = common.StringsToInts([]string{target})[0] - 1
+	}
+	if desktop < 0 || desktop >= count || desktop == int(c.Latest.Location.Desktop) {
+		return false
+	}
+
+	// Move client to target desktop
+	if !c.MoveToDesktop(uint32(desktop)) {
+		return false
+	}
+
+	// Switch to target desktop
+	if follow {
+		store.CurrentDesktopSet(store.X, uint(desktop))
+		store.ActiveWindowSet(store.X, c.Window)
+	}
+
+	return true
+}
+
 func MakeMaster(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -518,6 +1258,30 @@ func MakeMasterPrevious(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return PreviousWindow(tr, ws)
 }
 
+func RotateClientsForward(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.ActiveLayout().GetManager().RotateClients(1) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func RotateClientsBackward(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	if !ws.ActiveLayout().GetManager().RotateClients(-1) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
 func IncreaseProportion(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	if ws.TilingDisabled() {
 		return false
@@ -538,44 +1302,293 @@ func DecreaseProportion(tr *desktop.Tracker, ws *desktop.Workspace) bool {
 	return true
 }
 
-func Restart(tr *desktop.Tracker) bool {
-	tr.Write()
+func ResizeMaster(tr *desktop.Tracker, ws *desktop.Workspace, step float64) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+	al := ws.ActiveLayout()
+
+	// Obtain master side index
+	index := 0
+	switch al.GetName() {
+	case "vertical-right", "horizontal-bottom":
+		index = 1
+	case "maximized", "fullscreen":
+		return false
+	}
+
+	if !al.GetManager().ResizeMaster(index, step) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func FixSize(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() || common.IsInList(ws.ActiveLayout().GetName(), []string{"maximized", "fullscreen"}) {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	if !ws.ActiveLayout().GetManager().ToggleFixed(c) {
+		return false
+	}
+	c.Write()
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+
+	return true
+}
+
+func ResizeWindow(tr *desktop.Tracker, ws *desktop.Workspace, step float64) bool {
+	if ws.TilingDisabled() || common.IsInList(ws.ActiveLayout().GetName(), []string{"maximized", "fullscreen"}) {
+		return false
+	}
+	c := ws.ActiveLayout().ActiveClient()
+	if c == nil {
+		return false
+	}
+
+	if !ws.ActiveLayout().GetManager().ResizeClient(c, step) {
+		return false
+	}
+	tr.Tile(ws)
+
+	return true
+}
+
+func UndoTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() || !ws.Undo() {
+		return false
+	}
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func RedoTiling(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	if ws.TilingDisabled() || !ws.Redo() {
+		return false
+	}
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
 
-	xevent.Detach(store.X, store.X.RootWin())
+func SetGap(tr *desktop.Tracker, ws *desktop.Workspace, gap int) bool {
+	if ws.TilingDisabled() {
+		return false
+	}
+
+	// Set gap size of all workspace layouts
+	for _, l := range ws.Layouts {
+		l.GetManager().SetGap(gap)
+	}
+	tr.Tile(ws)
+
+	ui.ShowLayout(ws)
+
+	return true
+}
+
+func ChangeGap(tr *desktop.Tracker, ws *desktop.Workspace, step int) bool {
+	mg := ws.ActiveLayout().GetManager()
+	gap := common.MaxInt(0, common.MinInt(mg.Gap+step, 100))
+	if gap == mg.Gap {
+		return false
+	}
+	return SetGap(tr, ws, gap)
+}
+
+func ToggleGap(tr *desktop.Tracker, ws *desktop.Workspace) bool {
+	mg := ws.ActiveLayout().GetManager()
+	if mg.Gap > 0 {
+		return SetGap(tr, ws, 0)
+	}
+
+	// Restore configured or default step gap size
+	gap := common.Config.WindowGapSize
+	if gap == 0 {
+		gap = common.Config.WindowGapStep
+	}
+	return SetGap(tr, ws, gap)
+}
+
+func NextProfile(tr *desktop.Tracker) bool {
+	names := common.ProfileNames()
+	for i, name := range names {
+		if name == common.Profile {
+			return SwitchProfile(tr, names[(i+1)%len(names)])
+		}
+	}
+	return SwitchProfile(tr, names[0])
+}
+
+func PreviousProfile(tr *desktop.Tracker) bool {
+	names := common.ProfileNames()
+	for i, name := range names {
+		if name == common.Profile {
+			return SwitchProfile(tr, names[(i-1+len(names))%len(names)])
+		}
+	}
+	return SwitchProfile(tr, names[0])
+}
 
+func SwitchProfile(tr *desktop.Tracker, name string) bool {
+	decoration, gap := common.Config.WindowDecoration, common.Config.WindowGapSize
+	layouts := map[*desktop.Workspace]string{}
 	for _, ws := range tr.Workspaces {
-		if ws.TilingDisabled() {
-			continue
+		layouts[ws] = ws.DefaultLayout()
+	}
+
+	if err := common.ProfileSet(name); err != nil {
+		log.Warn("Error switching config profile: ", err)
+		return false
+	}
+
+	// Rebind keyboard shortcuts
+	RebindKeys(tr)
+
+	// Apply changed profile layouts, decorations and gaps
+	for _, ws := range tr.Workspaces {
+		if layouts[ws] != ws.DefaultLayout() {
+			ws.SetDefaultLayout()
+		}
+		ws.UpdateLimits()
+		for _, l := range ws.Layouts {
+			if decoration != common.Config.WindowDecoration {
+				l.GetManager().Decoration = common.Config.WindowDecoration
+			}
+			if gap != common.Config.WindowGapSize {
+				l.GetManager().Gap = common.Config.WindowGapSize
+			}
 		}
-		ws.DisableTiling()
-		tr.Restore(ws, store.Latest)
 	}
 
+	// Retile all workspaces
+	tr.Update()
+	for _, ws := range tr.Workspaces {
+		tr.Tile(ws)
+	}
+
+	ws := tr.ActiveWorkspace()
+	ui.ShowLayout(ws)
+	ui.UpdateIcon(ws)
+
+	return true
+}
+
+func AddDesktop(tr *desktop.Tracker) bool {
+	return tr.AddDesktop()
+}
+
+func RemoveDesktop(tr *desktop.Tracker) bool {
+	return tr.RemoveDesktop()
+}
+
+func TogglePresentation(tr *desktop.Tracker) bool {
+	return SetPresentation(tr, !store.Presenting)
+}
+
+func SetPresentation(tr *desktop.Tracker, enabled bool) bool {
+	if store.Presenting == enabled {
+		return false
+	}
+	store.Presenting = enabled
+
+	log.Info("Update presentation mode to ", enabled)
+
+	// Resume tiling of all workspaces
+	if !enabled {
+		tr.Update()
+		for _, ws := range tr.Workspaces {
+			tr.Tile(ws)
+		}
+		ui.ShowLayout(tr.ActiveWorkspace())
+	}
+
+	// Hide or show workspace indicator
+	ui.UpdateIndicator(tr.ActiveWorkspace())
+
+	return true
+}
+
+func DumpEvents() bool {
+	path, err := store.DumpEvents()
+	if err != nil {
+		log.Warn("Error dumping events: ", err)
+		return false
+	}
+
+	log.Warn("Dump recent events to ", path)
+
+	return true
+}
+
+func CalibrateExtents(tr *desktop.Tracker) bool {
+	c := tr.Clients[store.Windows.Active.Id]
+	if ws := tr.ClientWorkspace(c); c == nil || ws == nil || !ws.TilingEnabled() {
+		c = nil
+	}
+
+	// Measure extents with the active tiled client or a temporary window
+	return store.CalibrateExtents(c, func() {
+
+		// Tile active workspace with calibrated corrections
+		if ws := tr.ActiveWorkspace(); ws.TilingEnabled() {
+			tr.Tile(ws)
+		}
+	})
+}
+
+func InspectWindow(tr *desktop.Tracker, done func(insp store.Inspection)) bool {
+
+	// Inspect window picked by click
+	return ui.PickWindow(func(w xproto.Window) {
+		insp := store.Inspect(w)
+		insp.Decision = tr.Decision(w, insp.Info)
+		log.WithField("client", w).Info("Inspect window ", insp.Lines())
+
+		// Show inspection overlay
+		ui.ShowInspection(tr.ActiveWorkspace(), insp.Lines())
+		if done != nil {
+			done(insp)
+		}
+	})
+}
+
+func Restart(tr *desktop.Tracker) bool {
+	tr.Shutdown()
+
 	log.Info("Restart")
+	common.SystemdNotify("RELOADING=1")
 
 	// Communicate application exit
 	Disconnect()
 
-	// Restart application
+	// Restart application without autostart
+	os.Setenv(strings.ToUpper(common.Build.Name)+"_RESTART", "1")
 	syscall.Exec(common.Process.Path, os.Args, os.Environ())
 
 	return true
 }
 
 func Exit(tr *desktop.Tracker) bool {
-	tr.Write()
-
-	xevent.Detach(store.X, store.X.RootWin())
-
-	for _, ws := range tr.Workspaces {
-		if ws.TilingDisabled() {
-			continue
-		}
-		ws.DisableTiling()
-		tr.Restore(ws, store.Latest)
-	}
+	tr.Shutdown()
 
 	log.Info("Exit")
+	common.SystemdNotify("STOPPING=1")
 
 	// Communicate application exit
 	Disconnect()
@@ -606,12 +1619,76 @@ func External(command string) bool {
 	return true
 }
 
+func Execute(command string, tr *desktop.Tracker) bool {
+	ws := tr.ActiveWorkspace()
+	if ws == nil || len(strings.TrimSpace(command)) == 0 {
+		return false
+	}
+
+	log.Info("Executing command \"", command, "\"")
+
+	// Obtain active client values
+	class, window := "", ""
+	if c := tr.ActiveClient(); c != nil {
+		class, window = c.Latest.Class, fmt.Sprint(c.Window.Id)
+	}
+
+	// Start command with state environment
+	prefix := strings.ToUpper(common.Build.Name)
+	_, err := start(command, append(os.Environ(),
+		fmt.Sprintf("%s_DESKTOP=%d", prefix, ws.Location.Desktop),
+		fmt.Sprintf("%s_SCREEN=%d", prefix, ws.Location.Screen),
+		fmt.Sprintf("%s_LAYOUT=%s", prefix, ws.ActiveLayout().GetName()),
+		fmt.Sprintf("%s_CLASS=%s", prefix, class),
+		fmt.Sprintf("%s_WINDOW=%s", prefix, window),
+	))
+	if err != nil {
+		log.Error("Command failed: ", err)
+		return false
+	}
+
+	return true
+}
+
+func Autostart(tr *desktop.Tracker) {
+	prefix := strings.ToUpper(common.Build.Name)
+	if common.Args.DryRun || len(os.Getenv(prefix+"_RESTART")) > 0 {
+		return
+	}
+
+	// Start applications and remember their sessions
+	for _, entry := range common.Config.Autostart {
+		log.Info("Autostart command \"", entry.Command, "\"")
+
+		pid, err := start(entry.Command, os.Environ())
+		if err != nil {
+			log.Error("Autostart failed: ", err)
+			continue
+		}
+		ui.ShowPlaceholder(tr, tr.AddAutostart(entry, uint(pid)))
+	}
+}
+
+func start(command string, env []string) (int, error) {
+	cmd := exec.Command("/bin/sh", "-c", command)
+	cmd.Env = env
+
+	// Detach command from process group
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	go cmd.Wait()
+
+	return cmd.Process.Pid, nil
+}
+
 func OnExecute(fun func(string, uint, uint)) {
 	executeCallbacksFun = append(executeCallbacksFun, fun)
 }
 
 func executeCallbacks(action string, desktop uint, screen uint) {
-	log.Info("Execute event ", action)
+	log.WithField("action", action).Info("Execute event")
 
 	for _, fun := range executeCallbacksFun {
 		fun(action, desktop, screen)
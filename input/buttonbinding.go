@@ -0,0 +1,36 @@
+package input
+
+import (
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
+)
+
+func BindButtons(tr *desktop.Tracker) {
+	poll(250, func() {
+		ui.UpdateButtons(tr, func(c *store.Client, name string) {
+			clickButton(tr, c, name)
+		})
+	})
+}
+
+func clickButton(tr *desktop.Tracker, c *store.Client, name string) bool {
+	ws := tr.ClientWorkspace(c)
+	if ws == nil || ws.TilingDisabled() {
+		return false
+	}
+
+	// Execute button action
+	switch name {
+	case "master":
+		ws.ActiveLayout().MakeMaster(c)
+		tr.Tile(ws)
+		return true
+	case "float":
+		return tr.Float(c)
+	case "close":
+		return tr.Close(c)
+	}
+
+	return false
+}
@@ -14,7 +14,6 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/jezek/xgb/xproto"
-	"github.com/jezek/xgbutil/ewmh"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/godbus/dbus/v5/introspect"
@@ -23,6 +22,7 @@ import (
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
 	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -75,7 +75,7 @@ func (m Methods) WindowToPosition(id int32, x int32, y int32) (string, *dbus.Err
 	// Move window to position
 	valid := x >= 0 && y >= 0
 	if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok && valid {
-		ewmh.MoveWindow(store.X, c.Window.Id, int(x), int(y))
+		store.Server.MoveWindow(c.Window.Id, int(x), int(y))
 		store.Pointer.Press()
 		success = true
 	}
@@ -116,6 +116,237 @@ func (m Methods) WindowToScreen(id int32, screen int32) (string, *dbus.Error) {
 	return dataMap("Result", "WindowToScreen", result), nil
 }
 
+func (m Methods) WindowIgnore(id int32) (string, *dbus.Error) {
+	success := false
+
+	// Ignore window until closed
+	if c, ok := m.Tracker.Clients[xproto.Window(id)]; ok {
+		success = m.Tracker.Ignore(c)
+	}
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "WindowIgnore", result), nil
+}
+
+func (m Methods) WindowHistory(desktop int32, screen int32) (string, *dbus.Error) {
+	success := false
+
+	// Obtain focus history
+	clients := []*store.Client{}
+	ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
+	if ws != nil {
+		clients = m.Tracker.FocusHistory(ws)
+		success = true
+	}
+
+	// Return result
+	result := common.Map{"Success": success, "Values": clients}
+
+	return dataMap("Result", "WindowHistory", result), nil
+}
+
+func (m Methods) WindowExplain(id int32) (string, *dbus.Error) {
+	w := xproto.Window(id)
+	if id == 0 {
+		w = store.Windows.Active.Id
+	}
+
+	// Obtain window info and tiling decision
+	info := store.GetInfo(w)
+	result := common.Map{
+		"Success":  len(info.Class) > 0,
+		"Info":     info,
+		"Decision": m.Tracker.Decision(w, info),
+	}
+
+	// Obtain workspace and role of tracked clients
+	if c, ok := m.Tracker.Clients[w]; ok {
+		if ws := m.Tracker.ClientWorkspace(c); ws != nil {
+			mg := ws.ActiveLayout().GetManager()
+			role, index := "slave", mg.Index(mg.Slaves, c)
+			if mg.IsMaster(c) {
+				role, index = "master", mg.Index(mg.Masters, c)
+			}
+			result["Workspace"] = ws.Name
+			result["Layout"] = ws.ActiveLayout().GetName()
+			result["Role"] = role
+			result["Index"] = index + 1
+			result["Tile"] = c.Tile
+		}
+	}
+
+	return dataMap("Result", "WindowExplain", result), nil
+}
+
+func (m Methods) WindowInspect() (string, *dbus.Error) {
+	ch := make(chan store.Inspection, 1)
+	result := common.Map{"Success": false}
+
+	// Start window picker on the event loop
+	picking := false
+	store.Await(func() {
+		picking = InspectWindow(m.Tracker, func(insp store.Inspection) { ch <- insp })
+	})
+
+	// Wait for the picked window (each call runs in its own goroutine)
+	if picking {
+		select {
+		case insp := <-ch:
+			result = common.Map{"Success": true, "Inspection": insp}
+		case <-time.After(inspectTimeout):
+		}
+	}
+
+	return dataMap("Result", "WindowInspect", result), nil
+}
+
+func (m Methods) WindowList() (string, *dbus.Error) {
+
+	// Obtain clients sorted by location and id
+	clients := maps.Values(m.Tracker.Clients)
+	sort.Slice(clients, func(i, j int) bool {
+		a, b := clients[i].Latest.Location, clients[j].Latest.Location
+		if a.Desktop != b.Desktop {
+			return a.Desktop < b.Desktop
+		}
+		if a.Screen != b.Screen {
+			return a.Screen < b.Screen
+		}
+		return clients[i].Window.Id < clients[j].Window.Id
+	})
+
+	// Obtain window values
+	active := store.Windows.Active.Id
+	values := []common.Map{}
+	for _, c := range clients {
+		values = append(values, common.Map{
+			"Id":      c.Window.Id,
+			"Desktop": c.Latest.Location.Desktop,
+			"Screen":  c.Latest.Location.Screen,
+			"Class":   c.Latest.Class,
+			"Name":    c.Latest.Name,
+			"Active":  c.Window.Id == active,
+		})
+	}
+
+	// Return result
+	result := common.Map{"Success": true, "Values": values}
+
+	return dataMap("Result", "WindowList", result), nil
+}
+
+func (m Methods) WorkspaceList() (string, *dbus.Error) {
+
+	// Obtain workspaces sorted by location
+	workspaces := maps.Values(m.Tracker.Workspaces)
+	sort.Slice(workspaces, func(i, j int) bool {
+		a, b := workspaces[i].Location, workspaces[j].Location
+		if a.Desktop != b.Desktop {
+			return a.Desktop < b.Desktop
+		}
+		return a.Screen < b.Screen
+	})
+
+	// Obtain workspace values
+	active := m.Tracker.ActiveWorkspace()
+	values := []common.Map{}
+	for _, ws := range workspaces {
+		values = append(values, common.Map{
+			"Name":    ws.Name,
+			"Desktop": ws.Location.Desktop,
+			"Screen":  ws.Location.Screen,
+			"Layout":  ws.ActiveLayout().GetName(),
+			"Tiling":  ws.TilingEnabled(),
+			"Active":  ws == active,
+		})
+	}
+
+	// Return result
+	result := common.Map{"Success": true, "Values": values}
+
+	return dataMap("Result", "WorkspaceList", result), nil
+}
+
+func (m Methods) LayoutList() (string, *dbus.Error) {
+
+	// Obtain layout values of active workspace
+	ws := m.Tracker.ActiveWorkspace()
+	values := []common.Map{}
+	for _, l := range ws.Layouts {
+		values = append(values, common.Map{
+			"Name":   l.GetName(),
+			"Active": l == ws.ActiveLayout(),
+		})
+	}
+
+	// Return result
+	result := common.Map{"Success": true, "Values": values}
+
+	return dataMap("Result", "LayoutList", result), nil
+}
+
+func (m Methods) LayoutSwitch(name string) (string, *dbus.Error) {
+	success := false
+
+	// Switch layout of active workspace
+	ws := m.Tracker.ActiveWorkspace()
+	for _, l := range ws.Layouts {
+		if l.GetName() == name {
+			success = ExecuteAction("layout_"+strings.Replace(name, "-", "_", -1), m.Tracker, ws)
+		}
+	}
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "LayoutSwitch", result), nil
+}
+
+func (m Methods) GapSet(size int32, desktop int32, screen int32) (string, *dbus.Error) {
+	success := false
+
+	// Set gap size of workspace
+	ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
+	if ws != nil && size >= 0 {
+		success = SetGap(m.Tracker, ws, int(size))
+	}
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "GapSet", result), nil
+}
+
+func (m Methods) PauseGet(desktop int32, screen int32) (string, *dbus.Error) {
+	success := false
+
+	// Get remaining pause of workspace
+	remaining := 0.0
+	ws := m.Tracker.WorkspaceAt(uint(desktop), uint(screen))
+	if ws != nil {
+		remaining = ws.PauseRemaining().Seconds()
+		success = true
+	}
+
+	// Return result
+	result := common.Map{"Success": success, "Paused": remaining > 0, "Remaining": remaining}
+
+	return dataMap("Result", "PauseGet", result), nil
+}
+
+func (m Methods) ClassExempt(class string, exempt int32) (string, *dbus.Error) {
+
+	// Exempt window class from tiling
+	success := len(class) > 0 && ExemptClass(m.Tracker, class, exempt > 0)
+
+	// Return result
+	result := common.Map{"Success": success, "Values": store.Exemptions}
+
+	return dataMap("Result", "ClassExempt", result), nil
+}
+
 func (m Methods) DesktopSwitch(desktop int32) (string, *dbus.Error) {
 	success := false
 
@@ -132,6 +363,179 @@ func (m Methods) DesktopSwitch(desktop int32) (string, *dbus.Error) {
 	return dataMap("Result", "DesktopSwitch", result), nil
 }
 
+func (m Methods) ConfigGet(name string) (string, *dbus.Error) {
+	success := false
+
+	// Get config value
+	value, err := common.ConfigGet(name)
+	if err == nil {
+		success = true
+	}
+
+	// Return result
+	result := common.Map{"Success": success, "Name": name, "Value": value}
+
+	return dataMap("Result", "ConfigGet", result), nil
+}
+
+func (m Methods) ConfigSet(name string, value string, persist int32) (string, *dbus.Error) {
+	success := false
+
+	// Set config value
+	err := common.ConfigSet(name, value, persist > 0)
+	if err == nil {
+		success = true
+		SetProperty("Configuration", common.Config)
+
+		// Apply config value
+		for _, ws := range m.Tracker.Workspaces {
+			if name == "window_decoration" && ws.TilingEnabled() {
+				if common.Config.WindowDecoration {
+					ws.ActiveLayout().GetManager().EnableDecoration()
+				} else {
+					ws.ActiveLayout().GetManager().DisableDecoration()
+				}
+			}
+			if name == "window_gap_size" {
+				for _, l := range ws.Layouts {
+					l.GetManager().SetGap(common.Config.WindowGapSize)
+				}
+			}
+		}
+		m.Tracker.Update()
+		for _, ws := range m.Tracker.Workspaces {
+			m.Tracker.Tile(ws)
+		}
+		ui.UpdateIcon(m.Tracker.ActiveWorkspace())
+	} else {
+		log.Warn("Error setting config value ", name, ": ", err)
+	}
+
+	// Return result
+	result := common.Map{"Success": success}
+	if err != nil {
+		result["Message"] = err.Error()
+	}
+
+	return dataMap("Result", "ConfigSet", result), nil
+}
+
+func (m Methods) IgnoreAdd(class string, name string, workspaces string, persist int32) (string, *dbus.Error) {
+
+	// Add window ignore entry
+	err := common.IgnoreAdd(class, name, workspaces, persist > 0)
+
+	return dataMap("Result", "IgnoreAdd", updateIgnore(m.Tracker, err)), nil
+}
+
+func (m Methods) IgnoreRemove(class string, name string, workspaces string, persist int32) (string, *dbus.Error) {
+
+	// Remove window ignore entry
+	err := common.IgnoreRemove(class, name, workspaces, persist > 0)
+
+	return dataMap("Result", "IgnoreRemove", updateIgnore(m.Tracker, err)), nil
+}
+
+func (m Methods) ProfileSwitch(name string) (string, *dbus.Error) {
+
+	// Switch config profile
+	success := SwitchProfile(m.Tracker, name)
+	if success {
+		SetProperty("Configuration", common.Config)
+	}
+
+	// Return result
+	result := common.Map{"Success": success, "Profile": common.Profile}
+
+	return dataMap("Result", "ProfileSwitch", result), nil
+}
+
+func (m Methods) PresentationSet(enabled int32) (string, *dbus.Error) {
+
+	// Set presentation mode
+	success := SetPresentation(m.Tracker, enabled > 0)
+
+	// Return result
+	result := common.Map{"Success": success, "Presenting": store.Presenting}
+
+	return dataMap("Result", "PresentationSet", result), nil
+}
+
+func (m Methods) DesktopAdd() (string, *dbus.Error) {
+
+	// Add desktop
+	success := AddDesktop(m.Tracker)
+
+	// Return result
+	result := common.Map{"Success": success, "Desktops": store.Workplace.DesktopCount}
+
+	return dataMap("Result", "DesktopAdd", result), nil
+}
+
+func (m Methods) DesktopRemove() (string, *dbus.Error) {
+
+	// Remove desktop
+	success := RemoveDesktop(m.Tracker)
+
+	// Return result
+	result := common.Map{"Success": success, "Desktops": store.Workplace.DesktopCount}
+
+	return dataMap("Result", "DesktopRemove", result), nil
+}
+
+func (m Methods) ScheduleList() (string, *dbus.Error) {
+
+	// Return result
+	result := common.Map{"Paused": schedulePaused, "Entries": ScheduleEntries()}
+
+	return dataMap("Result", "ScheduleList", result), nil
+}
+
+func (m Methods) SchedulePause(paused int32) (string, *dbus.Error) {
+
+	// Pause or resume schedule
+	success := PauseSchedule(paused > 0)
+
+	// Return result
+	result := common.Map{"Success": success, "Paused": schedulePaused}
+
+	return dataMap("Result", "SchedulePause", result), nil
+}
+
+func (m Methods) ScheduleRun(index int32) (string, *dbus.Error) {
+
+	// Run schedule entry
+	success := RunSchedule(m.Tracker, int(index))
+
+	// Return result
+	result := common.Map{"Success": success}
+
+	return dataMap("Result", "ScheduleRun", result), nil
+}
+
+func (m Methods) EventsDump() (string, *dbus.Error) {
+	success := false
+
+	// Dump recent events
+	path, err := store.DumpEvents()
+	if err == nil {
+		success = true
+	}
+
+	// Return result
+	result := common.Map{"Success": success, "Path": path}
+
+	return dataMap("Result", "EventsDump", result), nil
+}
+
+func (m Methods) CacheManage(command string) (string, *dbus.Error) {
+
+	// Return result
+	result := CacheCommand(command)
+
+	return dataMap("Result", "CacheManage", result), nil
+}
+
 func (m Methods) Introspection() []introspect.Method {
 	typ := reflect.TypeOf(m)
 	ims := make([]introspect.Method, 0, typ.NumMethod())
@@ -206,29 +610,37 @@ func BindDbus(tr *desktop.Tracker) {
 }
 
 func event(ch chan string, tr *desktop.Tracker) {
-	for {
-		switch <-ch {
-		case "clients_change":
-			SetProperty("Clients", common.Map{"Values": maps.Values(tr.Clients)})
-		case "workspaces_change":
-			SetProperty("Workspaces", common.Map{"Values": maps.Values(tr.Workspaces)})
-		case "workplace_change":
-			SetProperty("Workplace", *store.Workplace)
-		case "windows_change":
-			SetProperty("Windows", *store.Windows)
-		case "corner_change":
-			for _, hc := range store.Workplace.Displays.Corners {
-				if !hc.Active {
-					continue
-				}
-				SetProperty("Corner", struct {
-					Name     string
-					Location store.Location
-				}{
-					Name:     hc.Name,
-					Location: tr.ActiveWorkspace().Location,
-				})
+	for name := range ch {
+		store.Post(func() {
+			updateProperty(name, tr)
+		})
+	}
+}
+
+func updateProperty(name string, tr *desktop.Tracker) {
+	switch name {
+	case "clients_change":
+		SetProperty("Clients", common.Map{"Values": maps.Values(tr.Clients)})
+	case "workspaces_change":
+		SetProperty("Workspaces", common.Map{"Values": maps.Values(tr.Workspaces)})
+	case "decisions_change":
+		SetProperty("Decisions", common.Map{"Values": maps.Values(tr.Decisions)})
+	case "workplace_change":
+		SetProperty("Workplace", *store.Workplace)
+	case "windows_change":
+		SetProperty("Windows", *store.Windows)
+	case "corner_change":
+		for _, hc := range store.Workplace.Displays.Corners {
+			if !hc.Active {
+				continue
 			}
+			SetProperty("Corner", struct {
+				Name     string
+				Location store.Location
+			}{
+				Name:     hc.Name,
+				Location: tr.ActiveWorkspace().Location,
+			})
 		}
 	}
 }
@@ -239,6 +651,9 @@ func connect() (*dbus.Conn, error) {
 
 	// Init interface and path
 	iface = fmt.Sprintf("%s.%s", hostname, repository)
+	if len(common.Args.Instance) > 0 {
+		iface = fmt.Sprintf("%s.%s", iface, common.Args.Instance)
+	}
 	opath = dbus.ObjectPath(fmt.Sprintf("/%s", strings.Replace(iface, ".", "/", -1)))
 
 	// Init session bus
@@ -246,6 +661,8 @@ func connect() (*dbus.Conn, error) {
 }
 
 func export(tr *desktop.Tracker) {
+	defer common.Recover()
+
 	conn, err := connect()
 	if err != nil {
 		log.Warn("Error initializing dbus server: ", err)
@@ -271,6 +688,7 @@ func export(tr *desktop.Tracker) {
 		"Workplace":     common.Map{},
 		"Windows":       common.Map{},
 		"Clients":       common.Map{},
+		"Decisions":     common.Map{},
 		"Pointer":       common.Map{},
 		"Action":        common.Map{},
 		"Corner":        common.Map{},
@@ -298,11 +716,35 @@ func export(tr *desktop.Tracker) {
 			"WindowToPosition": {"id", "x", "y"},
 			"WindowToDesktop":  {"id", "desktop"},
 			"WindowToScreen":   {"id", "screen"},
+			"WindowHistory":    {"desktop", "screen"},
+			"WindowExplain":    {"id"},
+			"WindowInspect":    {},
+			"WindowIgnore":     {"id"},
+			"WindowList":       {},
+			"WorkspaceList":    {},
+			"LayoutList":       {},
+			"LayoutSwitch":     {"name"},
+			"GapSet":           {"size", "desktop", "screen"},
+			"PauseGet":         {"desktop", "screen"},
+			"ClassExempt":      {"class", "exempt"},
 			"DesktopSwitch":    {"desktop"},
+			"DesktopAdd":       {},
+			"DesktopRemove":    {},
+			"ConfigGet":        {"name"},
+			"ConfigSet":        {"name", "value", "persist"},
+			"IgnoreAdd":        {"class", "name", "workspaces", "persist"},
+			"IgnoreRemove":     {"class", "name", "workspaces", "persist"},
+			"ProfileSwitch":    {"name"},
+			"PresentationSet":  {"enabled"},
+			"ScheduleList":     {},
+			"SchedulePause":    {"paused"},
+			"ScheduleRun":      {"index"},
+			"EventsDump":       {},
+			"CacheManage":      {"command"},
 		},
 		Tracker: tr,
 	}
-	err = conn.Export(methods, opath, iface)
+	err = conn.ExportMethodTable(loopMethods(methods), opath, iface)
 	if err != nil {
 		log.Warn("Error exporting dbus methods: ", err)
 		return
@@ -330,6 +772,36 @@ func export(tr *desktop.Tracker) {
 	select {}
 }
 
+func loopMethods(m *Methods) map[string]interface{} {
+	table := map[string]interface{}{}
+
+	// Wrap dbus methods to run on the event loop
+	value := reflect.ValueOf(*m)
+	for i := 0; i < value.NumMethod(); i++ {
+		name := value.Type().Method(i).Name
+		if _, ok := m.Naming[name]; !ok {
+			continue
+		}
+		method := value.Method(i)
+
+		// Window inspection waits for a click off the event loop
+		if name == "WindowInspect" {
+			table[name] = method.Interface()
+			continue
+		}
+
+		table[name] = reflect.MakeFunc(method.Type(), func(args []reflect.Value) []reflect.Value {
+			var results []reflect.Value
+			store.Await(func() {
+				results = method.Call(args)
+			})
+			return results
+		}).Interface()
+	}
+
+	return table
+}
+
 func Introspect() map[string][]string {
 	conn, err := connect()
 	if err != nil {
@@ -389,11 +861,31 @@ func Method(name string, args []string) {
 	}
 	defer conn.Close()
 
+	// Get argument types
+	types := []string{}
+	node, err := introspect.Call(conn.Object(iface, opath))
+	if err == nil {
+		for _, item := range node.Interfaces {
+			for _, method := range item.Methods {
+				if item.Name != iface || method.Name != name {
+					continue
+				}
+				for _, arg := range method.Args {
+					if arg.Direction == "in" {
+						types = append(types, arg.Type)
+					}
+				}
+			}
+		}
+	}
+
 	// Convert arguments
 	variants := make([]interface{}, len(args))
 	for i, value := range args {
 		integer, err := strconv.Atoi(value)
-		if err == nil {
+		if i < len(types) && types[i] == "s" {
+			variants[i] = dbus.MakeVariant(value)
+		} else if err == nil {
 			variants[i] = dbus.MakeVariant(integer)
 		} else {
 			variants[i] = dbus.MakeVariant(value)
@@ -412,6 +904,178 @@ func Method(name string, args []string) {
 	fmt.Println(reply)
 }
 
+func List(kind string, format string) {
+	conn, err := connect()
+	if err != nil {
+		fatal("Error initializing dbus server", err)
+	}
+	defer conn.Close()
+
+	// Map list kind to method and columns
+	method, columns := "", []string{}
+	switch kind {
+	case "windows":
+		method, columns = "WindowList", []string{"Id", "Desktop", "Screen", "Class", "Name"}
+	case "workspaces":
+		method, columns = "WorkspaceList", []string{"Name", "Desktop", "Screen", "Layout", "Tiling"}
+	case "layouts":
+		method, columns = "LayoutList", []string{"Name", "Active"}
+	}
+
+	// Call dbus method
+	call := conn.Object(iface, opath).Call(fmt.Sprintf("%s.%s", iface, method), 0)
+	if call.Err != nil {
+		fatal("Error calling dbus method", call.Err)
+	}
+
+	// Decode reply values
+	var reply string
+	call.Store(&reply)
+	var result struct {
+		Data struct {
+			Values []common.Map
+		}
+	}
+	decoder := json.NewDecoder(strings.NewReader(reply))
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		fatal("Error decoding dbus reply", err)
+	}
+
+	// Print values as json
+	if format == "json" {
+		fmt.Println(mapToString(result.Data.Values))
+		return
+	}
+
+	// Print values as tab separated lines
+	for _, value := range result.Data.Values {
+		fields := make([]string, len(columns))
+		for i, column := range columns {
+			fields[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(fmt.Sprint(value[column]))
+		}
+		fmt.Println(strings.Join(fields, "\t"))
+	}
+}
+
+func updateIgnore(tr *desktop.Tracker, err error) common.Map {
+	if err != nil {
+		log.Warn("Error updating ignore entries: ", err)
+		return common.Map{"Success": false, "Message": err.Error()}
+	}
+	SetProperty("Configuration", common.Config)
+
+	// Retrack and retile windows
+	tr.Update()
+	for _, ws := range tr.Workspaces {
+		tr.Tile(ws)
+	}
+
+	return common.Map{"Success": true, "Values": common.Config.WindowIgnore}
+}
+
+func Explain(id string) {
+	conn, err := connect()
+	if err != nil {
+		fatal("Error initializing dbus server", err)
+	}
+	defer conn.Close()
+
+	// Parse decimal or hexadecimal window id
+	w, err := strconv.ParseUint(id, 0, 32)
+	if err != nil {
+		fatal("Error parsing window id", err)
+	}
+
+	// Call dbus method
+	call := conn.Object(iface, opath).Call(fmt.Sprintf("%s.%s", iface, "WindowExplain"), 0, int32(w))
+	if call.Err != nil {
+		fatal("Error calling dbus method", call.Err)
+	}
+
+	// Decode reply values
+	var reply string
+	call.Store(&reply)
+	var result struct {
+		Data struct {
+			Success   bool
+			Info      store.Info
+			Decision  store.Decision
+			Workspace string
+			Layout    string
+			Role      string
+			Index     int
+			Tile      common.Geometry
+		}
+	}
+	if err := json.Unmarshal([]byte(reply), &result); err != nil {
+		fatal("Error decoding dbus reply", err)
+	}
+	data := result.Data
+	if !data.Success {
+		fatal("Error explaining window", fmt.Errorf("window %s not found", id))
+	}
+
+	// Print detected window info
+	info := data.Info
+	fmt.Printf("WINDOW: \n  id: %d\n  class: %s\n  name: %s\n", data.Decision.Window, info.Class, info.Name)
+	fmt.Printf("  process: %s [%d]\n  app: %s\n", info.Process.Name, info.Process.Pid, info.Process.AppId)
+	fmt.Printf("  types: %s\n  states: %s\n", strings.Join(info.Types, ", "), strings.Join(info.States, ", "))
+	fmt.Printf("  location: desktop-%d-%d\n\n", info.Location.Desktop, info.Location.Screen)
+
+	// Print tiling decision
+	fmt.Printf("DECISION: \n  tiled: %t\n", data.Decision.Tiled)
+	if len(data.Decision.Reason) > 0 {
+		fmt.Printf("  reason: %s\n", data.Decision.Reason)
+	}
+	for _, rule := range data.Decision.Rules {
+		fmt.Printf("  rule: %s\n", rule)
+	}
+
+	// Print workspace placement
+	if data.Decision.Tiled {
+		fmt.Printf("\nPLACEMENT: \n  workspace: %s\n  layout: %s\n  role: %s %d\n", data.Workspace, data.Layout, data.Role, data.Index)
+		fmt.Printf("  tile: %d %d %d %d\n", data.Tile.X, data.Tile.Y, data.Tile.Width, data.Tile.Height)
+	}
+
+	// Print window dimensions
+	dim := info.Dimensions
+	fmt.Printf("\nDIMENSIONS: \n  geometry: %d %d %d %d\n", dim.Geometry.X, dim.Geometry.Y, dim.Geometry.Width, dim.Geometry.Height)
+	fmt.Printf("  extents: %d %d %d %d\n", dim.Extents.Left, dim.Extents.Right, dim.Extents.Top, dim.Extents.Bottom)
+	fmt.Printf("  adjust position: %t\n  adjust size: %t\n", dim.AdjPos, dim.AdjSize)
+}
+
+func Cache(command string) {
+
+	// Print cache command result
+	print("Result", "CacheManage", CacheCommand(command))
+}
+
+func CacheCommand(command string) common.Map {
+	success := false
+	result := common.Map{}
+
+	// Execute cache command
+	switch command {
+	case "gc":
+		removed, err := common.PruneCache()
+		success = err == nil
+		result["Removed"] = removed
+	case "clear":
+		success = common.CacheClear() == nil
+	}
+
+	// Append cache statistics
+	stats, err := common.CacheStatsGet()
+	if command == "stats" {
+		success = err == nil
+	}
+	result["Success"] = success
+	result["Stats"] = stats
+
+	return result
+}
+
 func Property(name string) {
 	conn, err := connect()
 	if err != nil {
@@ -0,0 +1,55 @@
+package input
+
+import (
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	dockDocked bool // External monitor is connected
+)
+
+func BindDock(tr *desktop.Tracker) {
+	_, dockDocked = store.ExternalScreen()
+
+	// Check dock state on screen changes
+	desktop.OnWorkplaceUpdate(func() {
+		screen, docked := store.ExternalScreen()
+		if docked == dockDocked {
+			return
+		}
+		dockDocked = docked
+		RunDock(tr, docked, screen)
+	})
+}
+
+func RunDock(tr *desktop.Tracker, docked bool, screen uint) bool {
+	dock := common.Config.Dock
+	success := false
+
+	log.Info("Run dock mode [docked=", docked, "]")
+
+	// Switch config profile
+	profile, actions := dock.UndockedProfile, dock.UndockedActions
+	if docked {
+		profile, actions = dock.Profile, dock.Actions
+	}
+	if len(profile) > 0 && profile != common.Profile {
+		success = SwitchProfile(tr, profile) || success
+	}
+
+	// Move windows to external screen
+	if docked && tr.Dock(screen, dock.Desktops, dock.Classes) > 0 {
+		success = true
+	}
+
+	// Execute dock actions
+	for _, action := range actions {
+		success = ExecuteAction(action, tr, tr.ActiveWorkspace()) || success
+	}
+
+	return success
+}
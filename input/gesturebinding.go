@@ -0,0 +1,163 @@
+package input
+
+import (
+	"bufio"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"os/exec"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	swipeThreshold float64 = 50  // Minimum swipe distance of gestures
+	pinchThreshold float64 = 0.2 // Minimum pinch scale change of gestures
+)
+
+var (
+	deltaSpacing = regexp.MustCompile(`/\s+`) // Padding of libinput delta values
+)
+
+type Gesture struct {
+	Type    string  // Gesture type (swipe or pinch)
+	Fingers int     // Number of fingers
+	Dx      float64 // Accumulated horizontal swipe distance
+	Dy      float64 // Accumulated vertical swipe distance
+	Scale   float64 // Latest pinch scale
+}
+
+func BindGestures(tr *desktop.Tracker) {
+
+	// Check configured gestures
+	enabled := false
+	for _, action := range common.Config.Gestures {
+		enabled = enabled || len(action) > 0
+	}
+	if !enabled {
+		return
+	}
+
+	// Check libinput availability
+	path, err := exec.LookPath("libinput")
+	if err != nil {
+		log.Warn("Error binding gestures: libinput not found")
+		return
+	}
+
+	// Start libinput event listener
+	cmd := exec.Command(path, "debug-events")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Pdeathsig: syscall.SIGTERM}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		log.Warn("Error binding gestures: ", err)
+		return
+	}
+	if err := cmd.Start(); err != nil {
+		log.Warn("Error binding gestures: ", err)
+		return
+	}
+
+	// Bind gesture events
+	go func() {
+		defer common.Recover()
+
+		scanner := bufio.NewScanner(stdout)
+		gesture := &Gesture{}
+		for scanner.Scan() {
+			if name := gesture.Parse(scanner.Text()); len(name) > 0 {
+				if action := common.Config.Gestures[name]; len(action) > 0 {
+					log.Info("Gesture ", name, " detected")
+					tr.Channels.Action <- action
+				}
+			}
+		}
+		if err := cmd.Wait(); err != nil {
+			log.Warn("Error listening to gestures, check libinput permissions (input group): ", err)
+		}
+	}()
+}
+
+func (g *Gesture) Parse(line string) string {
+	fields := strings.Fields(deltaSpacing.ReplaceAllString(line, "/"))
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "GESTURE_") {
+		return ""
+	}
+	event := strings.TrimPrefix(fields[1], "GESTURE_")
+
+	switch event {
+	case "SWIPE_BEGIN", "PINCH_BEGIN":
+
+		// Start new gesture
+		fingers, _ := strconv.Atoi(fields[3])
+		*g = Gesture{Type: strings.ToLower(strings.TrimSuffix(event, "_BEGIN")), Fingers: fingers, Scale: 1.0}
+	case "SWIPE_UPDATE":
+
+		// Accumulate swipe distance
+		if len(fields) > 4 {
+			delta := strings.Split(fields[4], "/")
+			if len(delta) == 2 {
+				dx, _ := strconv.ParseFloat(delta[0], 64)
+				dy, _ := strconv.ParseFloat(delta[1], 64)
+				g.Dx, g.Dy = g.Dx+dx, g.Dy+dy
+			}
+		}
+	case "PINCH_UPDATE":
+
+		// Store pinch scale
+		for i, field := range fields {
+			if field == "@" && i > 0 {
+				g.Scale, _ = strconv.ParseFloat(fields[i-1], 64)
+			}
+		}
+	case "SWIPE_END", "PINCH_END":
+		defer func() { *g = Gesture{} }()
+		if strings.Contains(line, "cancelled") {
+			return ""
+		}
+		return g.Name()
+	}
+
+	return ""
+}
+
+func (g *Gesture) Name() string {
+	switch g.Type {
+	case "swipe":
+
+		// Obtain swipe direction
+		if math.Max(math.Abs(g.Dx), math.Abs(g.Dy)) < swipeThreshold {
+			return ""
+		}
+		direction := "right"
+		if math.Abs(g.Dx) > math.Abs(g.Dy) {
+			if g.Dx < 0 {
+				direction = "left"
+			}
+		} else {
+			direction = "down"
+			if g.Dy < 0 {
+				direction = "up"
+			}
+		}
+		return "swipe_" + strconv.Itoa(g.Fingers) + "_" + direction
+	case "pinch":
+
+		// Obtain pinch direction
+		if math.Abs(g.Scale-1.0) < pinchThreshold {
+			return ""
+		}
+		if g.Scale < 1.0 {
+			return "pinch_in"
+		}
+		return "pinch_out"
+	}
+
+	return ""
+}
@@ -0,0 +1,45 @@
+package input
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
+)
+
+var (
+	indicatorEvents map[xproto.Button]string = map[xproto.Button]string{ // Systray events of indicator buttons
+		1: "click_left",
+		2: "click_middle",
+		3: "click_right",
+		4: "scroll_up",
+		5: "scroll_down",
+		6: "scroll_left",
+		7: "scroll_right",
+	}
+)
+
+func BindIndicator(tr *desktop.Tracker) {
+	ui.BindIndicator(func(button xproto.Button) {
+		clickIndicator(tr, button)
+	})
+
+	// Raise indicator above focused windows
+	store.OnStateUpdate(func(state string, desktop uint, screen uint) {
+		if state == "_NET_ACTIVE_WINDOW" {
+			ui.UpdateIndicator(tr.ActiveWorkspace())
+		}
+	})
+}
+
+func clickIndicator(tr *desktop.Tracker, button xproto.Button) bool {
+	event, ok := indicatorEvents[button]
+	if !ok {
+		return false
+	}
+
+	// Execute systray action
+	return ExecuteAction(common.Config.Systray[event], tr, tr.ActiveWorkspace())
+}
@@ -1,7 +1,13 @@
 package input
 
 import (
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
 
 	"github.com/jezek/xgbutil"
 	"github.com/jezek/xgbutil/keybind"
@@ -10,31 +16,89 @@ import (
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
 	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
 
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	chord      *Chord              // Active key chord
+	chordBound bool                // Key chord events are attached
+	keyFuns    map[string][]func() // Current callbacks of bound keys
+	keyGrabs   map[string]bool     // Bound keys grabbed on root window
+	count      int                 // Pending numeric action argument
+	countTimer *store.Timer        // Pending numeric argument timeout timer
+)
+
+type Chord struct {
+	Prefix        string            // Key chord prefix
+	Continuations map[string]string // Continuation keys mapped to actions
+	Mod           string            // Key chord modifier scope
+	Tracker       *desktop.Tracker  // Workspace tracker instance
+	Timer         *store.Timer      // Key chord timeout timer
+}
+
 func BindKeys(tr *desktop.Tracker) {
 	keybind.Initialize(store.X)
 
+	// Bind keyboard shortcuts
+	bindKeys(tr)
+
+	// Bind action channel
+	go action(tr.Channels.Action, tr)
+}
+
+func RebindKeys(tr *desktop.Tracker) {
+
+	// Rebind keyboard shortcuts
+	bindKeys(tr)
+}
+
+func bindKeys(tr *desktop.Tracker) {
+	keyFuns = map[string][]func(){}
 	actions := map[string]string{}
+	chords := map[string]map[string]string{}
 	mods := map[string]string{"current": ""}
 
-	// Map actions and modifiers
+	// Map actions, chords and modifiers
 	for c, ck := range common.Config.Keys {
-		if len(ck) == 0 {
+		keys := strings.Fields(ck)
+		if len(keys) == 0 {
 			continue
 		}
-		if !strings.HasPrefix(c, "mod_") {
-			actions[c] = ck
-		} else {
+		if strings.HasPrefix(c, "mod_") {
 			mods[c[4:]] = ck
+		} else if len(keys) == 2 {
+			if _, ok := chords[keys[0]]; !ok {
+				chords[keys[0]] = map[string]string{}
+			}
+			chords[keys[0]][keys[1]] = c
+		} else {
+			actions[c] = ck
+		}
+	}
+
+	// Bind key chord prefixes
+	for p, continuations := range chords {
+		for m, mk := range mods {
+			if len(mk) == 0 {
+				bindChord(p, continuations, m, tr)
+			} else {
+				bindChord(mk+"-"+p, continuations, m, tr)
+			}
 		}
 	}
 
 	// Bind keyboard shortcuts
 	for a, ak := range actions {
+		if digit, ok := strings.CutPrefix(a, "count_"); ok {
+			bindCount(ak, digit)
+			continue
+		}
 		for m, mk := range mods {
+			if strings.HasPrefix(a, "exec:") && m != "current" {
+				continue
+			}
 			if len(mk) == 0 {
 				bind(ak, a, m, tr)
 			} else {
@@ -43,22 +107,251 @@ func BindKeys(tr *desktop.Tracker) {
 		}
 	}
 
-	// Bind action channel
-	go action(tr.Channels.Action, tr)
+	// Grab bound keys
+	grabKeys()
 }
 
-func bind(key string, action string, mod string, tr *desktop.Tracker) {
-	err := keybind.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
-		ExecuteActions(action, tr, mod)
+func grabKeys() {
+	if keyGrabs == nil {
+		keyGrabs = map[string]bool{}
+	}
+
+	// Release keys without callbacks
+	for key, grabbed := range keyGrabs {
+		if _, ok := keyFuns[key]; ok || !grabbed {
+			continue
+		}
+		mods, codes, err := keybind.ParseString(store.X, key)
+		if err != nil {
+			continue
+		}
+		for _, code := range codes {
+			keybind.Ungrab(store.X, store.X.RootWin(), mods, code)
+		}
+		keyGrabs[key] = false
+	}
+
+	// Grab keys with callbacks, connecting each key only once
+	for key := range keyFuns {
+		grabbed, connected := keyGrabs[key]
+		if grabbed {
+			continue
+		}
+		if !connected {
+			if err := connectKey(key); err != nil {
+				log.Warn("Error on key ", key, ": ", err)
+				continue
+			}
+			keyGrabs[key] = true
+			continue
+		}
+		mods, codes, err := keybind.ParseString(store.X, key)
+		if err != nil {
+			log.Warn("Error on key ", key, ": ", err)
+			continue
+		}
+		for _, code := range codes {
+			if err := keybind.GrabChecked(store.X, store.X.RootWin(), mods, code); err != nil {
+				log.Warn("Error on key ", key, ": ", err)
+			}
+		}
+		keyGrabs[key] = true
+	}
+}
+
+func connectKey(key string) error {
+	return keybind.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+		for _, fun := range keyFuns[key] {
+			fun()
+		}
 	}).Connect(store.X, store.X.RootWin(), key, true)
+}
+
+func bind(key string, action string, mod string, tr *desktop.Tracker) {
+	keyFuns[key] = append(keyFuns[key], func() {
+		executeChain(action, tr, mod, popCount())
+	})
+}
+
+func bindCount(key string, digit string) {
+	d, err := strconv.Atoi(digit)
+	if err != nil || d < 0 || d > 9 {
+		log.Warn("Error on count ", digit, ": not a digit")
+		return
+	}
+
+	keyFuns[key] = append(keyFuns[key], func() {
+		pushCount(d)
+	})
+}
+
+func pushCount(digit int) {
+
+	// Accumulate count digits
+	count = common.MinInt(count*10+digit, 99)
+	log.Info("Action count ", count)
+
+	// Reset count after timeout
+	countTimer.Stop()
+	countTimer = store.AfterFunc(time.Duration(common.Config.GuiChordTimeout)*time.Millisecond, func() {
+		count = 0
+	})
+}
+
+func popCount() int {
+	n := count
+
+	// Reset pending count
+	count = 0
+	countTimer.Stop()
+
+	return common.MaxInt(n, 1)
+}
+
+func bindChord(key string, continuations map[string]string, mod string, tr *desktop.Tracker) {
+	keyFuns[key] = append(keyFuns[key], func() {
+		enterChord(key, continuations, mod, tr)
+	})
+}
+
+func enterChord(key string, continuations map[string]string, mod string, tr *desktop.Tracker) {
+	if !grabChord(key, continuations, mod, tr) {
+		return
+	}
+
+	// Show available continuations
+	keys := maps.Keys(continuations)
+	sort.Strings(keys)
+	lines := []string{}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("%s: %s", k, continuations[k]))
+	}
+	ui.ShowChord(tr.ActiveWorkspace(), lines)
+}
+
+func grabChord(key string, continuations map[string]string, mod string, tr *desktop.Tracker) bool {
+	if chord != nil {
+		return false
+	}
+
+	// Grab keyboard until continuation key is pressed
+	bindChordEvents()
+	if err := keybind.GrabKeyboard(store.X, store.X.RootWin()); err != nil {
+		log.Warn("Error grabbing keyboard: ", err)
+		return false
+	}
+
+	log.Info("Enter key chord ", key)
+
+	// Leave chord after timeout
+	chord = &Chord{
+		Prefix:        key,
+		Continuations: continuations,
+		Mod:           mod,
+		Tracker:       tr,
+	}
+	chord.Timer = store.AfterFunc(time.Duration(common.Config.GuiChordTimeout)*time.Millisecond, func() {
+		leaveChord("")
+	})
+
+	return true
+}
+
+func leaveChord(action string) {
+	if chord == nil {
+		return
+	}
+	c := chord
+	chord = nil
+
+	// Release keyboard and close overlay
+	c.Timer.Stop()
+	keybind.UngrabKeyboard(store.X)
+	ui.CloseChord()
 
+	// Execute selected action
+	if len(action) > 0 {
+		executeChain(action, c.Tracker, c.Mod, popCount())
+	}
+}
+
+func bindChordEvents() {
+	if chordBound {
+		return
+	}
+	chordBound = true
+
+	// Select action on continuation key
+	xevent.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+		if chord == nil || keybind.ModGet(X, ev.Detail) != 0 {
+			return
+		}
+		for k, action := range chord.Continuations {
+			if keybind.KeyMatch(X, k, ev.State, ev.Detail) {
+				leaveChord(action)
+				return
+			}
+		}
+
+		// Ignore prefix key that entered the chord
+		if keyPressed(chord.Prefix, ev) {
+			return
+		}
+		leaveChord("")
+	}).Connect(store.X, store.X.RootWin())
+}
+
+func keyPressed(key string, ev xevent.KeyPressEvent) bool {
+	mods, codes, err := keybind.ParseString(store.X, key)
 	if err != nil {
-		log.Warn("Error on action ", action, ": ", err)
+		return false
+	}
+
+	// Compare modifiers and keycode of event
+	state, detail := keybind.DeduceKeyInfo(ev.State, ev.Detail)
+	for _, code := range codes {
+		if mods == state && code == detail {
+			return true
+		}
 	}
+
+	return false
+}
+
+func executeChain(chain string, tr *desktop.Tracker, mod string, n int) bool {
+
+	// Execute comma separated actions until one fails
+	for _, action := range common.SplitChain(chain) {
+
+		// Use count as index or repetition argument
+		repeat := n
+		if common.IsInList(action, []string{"focus_master", "focus_slave"}) {
+			action, repeat = fmt.Sprintf("%s_%d", action, n), 1
+		}
+
+		for i := 0; i < repeat; i++ {
+			success := false
+			if command, ok := strings.CutPrefix(action, "exec:"); ok {
+				success = Execute(command, tr)
+			} else {
+				success = ExecuteActions(action, tr, mod)
+			}
+			if !success {
+				log.Info("Stop action chain ", chain, " at ", action)
+				return false
+			}
+		}
+	}
+
+	return true
 }
 
 func action(ch chan string, tr *desktop.Tracker) {
-	for {
-		ExecuteAction(<-ch, tr, tr.ActiveWorkspace())
+	defer common.Recover()
+
+	for name := range ch {
+		store.Post(func() {
+			ExecuteAction(name, tr, tr.ActiveWorkspace())
+		})
 	}
 }
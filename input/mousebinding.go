@@ -1,20 +1,20 @@
 package input
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
 	"github.com/leukipp/cortile/v2/store"
 	"github.com/leukipp/cortile/v2/ui"
-
-	log "github.com/sirupsen/logrus"
 )
 
 var (
 	workspace *desktop.Workspace // Stores previous workspace (for comparison only)
 	pointer   *store.XPointer    // Stores previous pointer (for comparison only)
-	hover     *time.Timer        // Timer to delay hover events
+	hover     *store.Timer       // Timer to delay hover events
+	dwell     *store.Timer       // Timer to delay corner events
 )
 
 func BindMouse(tr *desktop.Tracker) {
@@ -54,26 +54,55 @@ func updateWorkspace(tr *desktop.Tracker) {
 	if ws == nil || ws == workspace {
 		return
 	}
-	log.Info("Active workspace updated [", ws.Name, "]")
+	ws.Log().Info("Active workspace updated")
 
 	// Communicate workplace change
-	tr.Channels.Event <- "workplace_change"
+	tr.Communicate("workplace_change")
 
 	// Update systray icon
 	ui.UpdateIcon(ws)
 
+	// Announce active workspace
+	ui.AnnounceWorkspace(ws)
+
 	// Store last workspace
 	workspace = ws
 }
 
 func updateCorner(tr *desktop.Tracker) {
+	if store.HasQuirk("no_query_pointer") {
+		return
+	}
 	hc := store.HotCorner()
 	if hc == nil {
 		return
 	}
 
+	// Execute action immediately
+	if common.Config.EdgeCornerDelay == 0 {
+		executeCorner(hc, tr)
+		return
+	}
+
+	// Delay corner event by given duration
+	if dwell != nil {
+		dwell.Stop()
+	}
+	dwell = store.AfterFunc(time.Duration(common.Config.EdgeCornerDelay)*time.Millisecond, func() {
+		dwell = nil
+
+		// Pointer has left the corner in the meantime
+		if !hc.IsActive(store.Pointer) {
+			return
+		}
+		executeCorner(hc, tr)
+	})
+}
+
+func executeCorner(hc *store.Corner, tr *desktop.Tracker) {
+
 	// Communicate corner change
-	tr.Channels.Event <- "corner_change"
+	tr.Communicate("corner_change")
 
 	// Execute action
 	ExecuteAction(common.Config.Corners[hc.Name], tr, tr.ActiveWorkspace())
@@ -81,7 +110,7 @@ func updateCorner(tr *desktop.Tracker) {
 
 func updateFocus(tr *desktop.Tracker) {
 	ws := tr.ActiveWorkspace()
-	if ws == nil || pointer == nil || hover != nil {
+	if ws == nil || pointer == nil || hover != nil || store.HasQuirk("no_query_pointer") {
 		return
 	}
 
@@ -96,13 +125,13 @@ func updateFocus(tr *desktop.Tracker) {
 	if active == nil || hovered == nil {
 		return
 	}
-	log.Info("Hovered window updated [", hovered.Latest.Class, "]")
+	hovered.Log().Info("Hovered window updated")
 
 	// Delay hover event by given duration
 	if common.Config.WindowFocusDelay == 0 {
 		return
 	}
-	hover = time.AfterFunc(time.Duration(common.Config.WindowFocusDelay)*time.Millisecond, func() {
+	hover = store.AfterFunc(time.Duration(common.Config.WindowFocusDelay)*time.Millisecond, func() {
 		hover = nil
 
 		// Hovered client window has changed in the meantime
@@ -118,9 +147,20 @@ func updateFocus(tr *desktop.Tracker) {
 }
 
 func poll(t time.Duration, fun func()) {
+	var pending atomic.Bool
+
+	// Run function on the event loop (skip ticks while still pending)
 	go func() {
+		defer common.Recover()
+
 		for range time.Tick(t * time.Millisecond) {
-			fun()
+			if pending.Swap(true) {
+				continue
+			}
+			store.Post(func() {
+				defer pending.Store(false)
+				fun()
+			})
 		}
 	}()
 }
@@ -0,0 +1,133 @@
+package input
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	schedulePaused bool      // Scheduled entries are suspended
+	scheduleLatest time.Time // Minute of last schedule check
+)
+
+type scheduleDue struct {
+	Index int       // Index of schedule entry
+	Time  time.Time // Missed or current run time
+}
+
+func BindSchedule(tr *desktop.Tracker) {
+	scheduleLatest = time.Now().Truncate(time.Minute)
+	scheduleNext(tr)
+}
+
+func scheduleNext(tr *desktop.Tracker) {
+	next := time.Now().Truncate(time.Minute).Add(time.Minute)
+
+	// Check schedule entries on minute change
+	store.AfterFunc(time.Until(next), func() {
+		runMissed(tr)
+		scheduleNext(tr)
+	})
+}
+
+func runMissed(tr *desktop.Tracker) {
+	now := time.Now().Truncate(time.Minute)
+	if !now.After(scheduleLatest) {
+		return
+	}
+	latest := scheduleLatest
+	scheduleLatest = now
+	if schedulePaused {
+		return
+	}
+
+	// Collect entries due since last check (e.g. after suspend or delayed ticks)
+	entries := []scheduleDue{}
+	for i, entry := range common.Config.Schedule {
+		cron, err := common.ParseCron(entry.Cron)
+		if err != nil {
+			continue
+		}
+		if t := cron.Next(latest); !t.IsZero() && !t.After(now) {
+			entries = append(entries, scheduleDue{Index: i, Time: t})
+		}
+	}
+
+	// Run due entries in chronological order
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.Before(entries[j].Time)
+	})
+	for _, entry := range entries {
+		RunSchedule(tr, entry.Index)
+	}
+}
+
+func RunSchedule(tr *desktop.Tracker, index int) bool {
+	if index < 0 || index >= len(common.Config.Schedule) {
+		return false
+	}
+	entry := common.Config.Schedule[index]
+	success := false
+
+	log.Info("Run schedule entry ", index+1, " [", entry.Cron, "]")
+
+	// Switch config profile
+	if len(entry.Profile) > 0 && entry.Profile != common.Profile {
+		success = SwitchProfile(tr, entry.Profile) || success
+	}
+
+	// Obtain workspace of target desktop
+	ws := tr.ActiveWorkspace()
+	if entry.Desktop > 0 {
+		ws = tr.WorkspaceAt(uint(entry.Desktop-1), store.Workplace.CurrentScreen)
+	}
+	if ws == nil {
+		return success
+	}
+
+	// Switch layout and execute action
+	if len(entry.Layout) > 0 {
+		success = ExecuteAction("layout_"+strings.ReplaceAll(entry.Layout, "-", "_"), tr, ws) || success
+	}
+	if len(entry.Action) > 0 {
+		success = ExecuteAction(entry.Action, tr, ws) || success
+	}
+
+	return success
+}
+
+func PauseSchedule(paused bool) bool {
+	if schedulePaused == paused {
+		return false
+	}
+	schedulePaused = paused
+
+	log.Info("Update schedule pause to ", paused)
+
+	return true
+}
+
+func ScheduleEntries() []common.Map {
+	entries := []common.Map{}
+
+	// Collect entries with next run time
+	now := time.Now()
+	for i, entry := range common.Config.Schedule {
+		next := ""
+		if cron, err := common.ParseCron(entry.Cron); err == nil {
+			if t := cron.Next(now); !t.IsZero() {
+				next = t.Format(time.RFC3339)
+			}
+		}
+		entries = append(entries, common.Map{"Index": i, "Entry": entry, "Next": next})
+	}
+
+	return entries
+}
@@ -1,12 +1,24 @@
 package input
 
 import (
+	"fmt"
 	"os"
 	"syscall"
+	"time"
 
 	"os/signal"
+	"runtime/debug"
 
+	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	recoverTimeout time.Duration = 5 * time.Second // Timeout of window restoring on the event loop
 )
 
 func BindSignal(tr *desktop.Tracker) {
@@ -15,9 +27,115 @@ func BindSignal(tr *desktop.Tracker) {
 	// Bind signal channel
 	signal.Notify(ch, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	go exit(ch, tr)
+
+	// Bind reload signal channel
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	go reloadConfig(hangup, tr)
+
+	// Bind crash signal channel
+	crash := make(chan os.Signal, 1)
+	signal.Notify(crash, syscall.SIGQUIT)
+	go recoverExit(crash, tr)
+
+	// Bind config file changes
+	common.OnConfigUpdate(func() {
+		reload(tr)
+	})
+
+	// Bind panics of spawned goroutines
+	common.OnPanic(func() {
+		restoreLoop(tr)
+	})
+}
+
+func Recover(tr *desktop.Tracker) {
+	err := recover()
+	if err == nil {
+		return
+	}
+	log.Error(fmt.Errorf("%s\n%s", err, debug.Stack()))
+
+	// Restore windows before exit
+	restore(tr)
+
+	log.Fatal("Exit after panic")
+}
+
+func restore(tr *desktop.Tracker) {
+	common.SystemdNotify("STOPPING=1")
+	tr.Recover()
+	Disconnect()
+}
+
+func restoreLoop(tr *desktop.Tracker) {
+	done := make(chan bool, 1)
+
+	// Restore windows on event loop, unless it does not respond
+	store.Post(func() {
+		restore(tr)
+		done <- true
+	})
+	select {
+	case <-done:
+	case <-time.After(recoverTimeout):
+		log.Warn("Event loop is not responding, exit without restoring windows")
+	}
 }
 
 func exit(ch chan os.Signal, tr *desktop.Tracker) {
+	defer common.Recover()
+
 	<-ch
-	ExecuteAction("exit", tr, tr.ActiveWorkspace())
+	store.Post(func() {
+		ExecuteAction("exit", tr, tr.ActiveWorkspace())
+	})
+}
+
+func reloadConfig(ch chan os.Signal, tr *desktop.Tracker) {
+	defer common.Recover()
+
+	for range ch {
+		log.Info("Reload config after signal")
+		reload(tr)
+	}
+}
+
+func reload(tr *desktop.Tracker) {
+	common.SystemdNotify("RELOADING=1")
+
+	// Decode config files off the event loop
+	state, err := common.LoadConfig(common.Args.Config)
+	if err != nil {
+		log.Warn("Error updating config file ", err)
+		common.SystemdNotify("READY=1")
+		return
+	}
+
+	// Apply config file changes on event loop
+	store.Post(func() {
+		if err := state.Apply(); err != nil {
+			log.Warn("Error updating config file ", err)
+		}
+		SetProperty("Configuration", common.Config)
+		RebindKeys(tr)
+		tr.Update()
+		for _, ws := range tr.Workspaces {
+			ws.UpdateLimits()
+			tr.Tile(ws)
+		}
+		ui.UpdateIcon(tr.ActiveWorkspace())
+
+		common.SystemdNotify("READY=1")
+	})
+}
+
+func recoverExit(ch chan os.Signal, tr *desktop.Tracker) {
+	sig := <-ch
+	log.Warn("Exit after signal ", sig)
+
+	// Restore windows before exit
+	restoreLoop(tr)
+
+	os.Exit(1)
 }
@@ -0,0 +1,24 @@
+package input
+
+import (
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+	"github.com/leukipp/cortile/v2/ui"
+)
+
+func BindTabs(tr *desktop.Tracker) {
+	update := func() {
+		ui.UpdateTabs(tr, func(c *store.Client) {
+			store.ActiveWindowSet(store.X, c.Window)
+		})
+	}
+
+	// Update tabs on tiling, title and focus changes
+	desktop.OnGroupUpdate(update)
+	store.OnStateUpdate(func(state string, desktop uint, screen uint) {
+		if common.IsInList(state, []string{"_NET_ACTIVE_WINDOW", "_NET_CURRENT_DESKTOP"}) {
+			update()
+		}
+	})
+}
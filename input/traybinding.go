@@ -22,7 +22,7 @@ import (
 var (
 	clicked bool          // Tray clicked state from dbus
 	button  store.XButton // Pointer button state of device
-	click   *time.Timer   // Timer to compress pointer events
+	click   *store.Timer  // Timer to compress pointer events
 	menu    *Menu         // Items collection of systray menu
 )
 
@@ -39,6 +39,8 @@ func BindTray(tr *desktop.Tracker) {
 
 	// Start systray icon
 	go systray.Run(func() {
+		defer common.Recover()
+
 		items(tr)
 		messages(tr)
 	}, func() {})
@@ -80,6 +82,8 @@ func items(tr *desktop.Tracker) {
 
 			// Issue item click
 			go func(info common.Info) {
+				defer common.Recover()
+
 				for {
 					<-subitem.ClickedCh
 
@@ -89,7 +93,9 @@ func items(tr *desktop.Tracker) {
 					// Update cache and ui icons
 					if info.Seen() {
 						subitem.SetIcon(ui.HintIcon(false))
-						ui.UpdateIcon(tr.ActiveWorkspace())
+						store.Post(func() {
+							ui.UpdateIcon(tr.ActiveWorkspace())
+						})
 					}
 				}
 			}(issue)
@@ -114,6 +120,8 @@ func items(tr *desktop.Tracker) {
 
 			// Release item click
 			go func(info common.Info) {
+				defer common.Recover()
+
 				for {
 					<-subitem.ClickedCh
 
@@ -123,7 +131,9 @@ func items(tr *desktop.Tracker) {
 					// Update cache and ui icons
 					if info.Seen() {
 						subitem.SetIcon(ui.HintIcon(false))
-						ui.UpdateIcon(tr.ActiveWorkspace())
+						store.Post(func() {
+							ui.UpdateIcon(tr.ActiveWorkspace())
+						})
 					}
 				}
 			}(release)
@@ -164,6 +174,8 @@ func items(tr *desktop.Tracker) {
 
 			// Update item click
 			go func(info common.Info) {
+				defer common.Recover()
+
 				for {
 					<-subitem.ClickedCh
 
@@ -209,9 +221,13 @@ func items(tr *desktop.Tracker) {
 
 		// Menu item action
 		go func(action string) {
+			defer common.Recover()
+
 			for {
 				<-item.ClickedCh
-				ExecuteAction(action, tr, tr.ActiveWorkspace())
+				store.Post(func() {
+					ExecuteAction(action, tr, tr.ActiveWorkspace())
+				})
 			}
 		}(action)
 	}
@@ -253,6 +269,8 @@ func messages(tr *desktop.Tracker) {
 	conn.Eavesdrop(ch)
 
 	go func() {
+		defer common.Recover()
+
 		var iface string
 		var method string
 		for msg := range ch {
@@ -263,10 +281,15 @@ func messages(tr *desktop.Tracker) {
 
 			switch method {
 			case "Activate", "SecondaryActivate", "AboutToShow", "AboutToShowGroup":
-				clicked = true
-				onActivate(tr)
+				store.Post(func() {
+					clicked = true
+					onActivate(tr)
+				})
 			case "Scroll":
-				onPointerScroll(tr, msg.Body[0].(int32), strings.ToLower(msg.Body[1].(string)))
+				delta, orientation := msg.Body[0].(int32), strings.ToLower(msg.Body[1].(string))
+				store.Post(func() {
+					onPointerScroll(tr, delta, orientation)
+				})
 			}
 		}
 	}()
@@ -340,7 +363,7 @@ func onPointerClick(tr *desktop.Tracker, pointer store.XPointer) {
 	}
 
 	// Wait for dbus events
-	click = time.AfterFunc(150*time.Millisecond, func() {
+	click = store.AfterFunc(150*time.Millisecond, func() {
 		if clicked && button.Left {
 			ExecuteAction(common.Config.Systray["click_left"], tr, tr.ActiveWorkspace())
 		}
@@ -362,7 +385,7 @@ func onPointerScroll(tr *desktop.Tracker, delta int32, orientation string) {
 	}
 
 	// Compress scroll events
-	click = time.AfterFunc(150*time.Millisecond, func() {
+	click = store.AfterFunc(150*time.Millisecond, func() {
 		switch orientation {
 		case "vertical":
 			if delta >= 0 {
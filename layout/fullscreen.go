@@ -16,14 +16,14 @@ type FullscreenLayout struct {
 func CreateFullscreenLayout(loc store.Location) *FullscreenLayout {
 	layout := &FullscreenLayout{
 		Name:    "fullscreen",
-		Manager: store.CreateManager(loc),
+		Manager: store.CreateManager(loc, "fullscreen"),
 	}
 	layout.Reset()
 	return layout
 }
 
 func (l *FullscreenLayout) Reset() {
-	mg := store.CreateManager(*l.Location)
+	mg := store.CreateManager(*l.Location, l.Name)
 
 	// Reset layout proportions
 	l.Manager.Proportions = mg.Proportions
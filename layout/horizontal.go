@@ -17,7 +17,7 @@ type HorizontalLayout struct {
 func CreateHorizontalTopLayout(loc store.Location) *HorizontalLayout {
 	layout := &HorizontalLayout{
 		Name:    "horizontal-top",
-		Manager: store.CreateManager(loc),
+		Manager: store.CreateManager(loc, "horizontal-top"),
 	}
 	layout.Reset()
 	return layout
@@ -26,14 +26,14 @@ func CreateHorizontalTopLayout(loc store.Location) *HorizontalLayout {
 func CreateHorizontalBottomLayout(loc store.Location) *HorizontalLayout {
 	layout := &HorizontalLayout{
 		Name:    "horizontal-bottom",
-		Manager: store.CreateManager(loc),
+		Manager: store.CreateManager(loc, "horizontal-bottom"),
 	}
 	layout.Reset()
 	return layout
 }
 
 func (l *HorizontalLayout) Reset() {
-	mg := store.CreateManager(*l.Location)
+	mg := store.CreateManager(*l.Location, l.Name)
 
 	// Reset number of masters
 	for l.Masters.Maximum < mg.Masters.Maximum {
@@ -58,11 +58,11 @@ func (l *HorizontalLayout) Reset() {
 func (l *HorizontalLayout) Apply() {
 	clients := l.Clients(store.Stacked)
 
-	dx, dy, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	gap := common.Config.WindowGapSize
+	dx, dy, dw, dh := l.Geometry().Pieces()
+	gap := l.Gap
 
 	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	smax := l.SlaveSlots()
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -102,12 +102,37 @@ func (l *HorizontalLayout) Apply() {
 			minpw = 1.0
 		}
 
-		mx := 0
+		// Stacked masters use the full width
+		if l.Stacking {
+			minpw = 1.0
+			if msize > 1 {
+				minph = common.Config.ProportionMin
+			}
+		}
+
+		mx, ry := 0, 0
+		mps := l.StackProportions(l.Masters, l.Proportions.MasterMaster[msize])
 		for i, c := range l.Masters.Stacked {
 
-			// Reset x position
+			// Reset x and y position
 			if i%mmax == 0 {
 				mx = dx + gap
+				ry = my + gap
+			}
+
+			// Move and resize stacked master
+			if l.Stacking {
+				minw := int(math.Round(float64(dw-2*gap) * minpw))
+				minh := int(math.Round(float64(dh-(msize+1)*gap) * minph))
+				c.Limit(minw, minh)
+
+				mp := mps[i%msize]
+				rh := int(math.Round(float64(mh-(msize+1)*gap) * mp))
+				c.MoveWindow(dx+gap, ry, dw-2*gap, rh)
+
+				// Add y offset
+				ry += rh + gap
+				continue
 			}
 
 			// Limit minimum dimensions
@@ -116,9 +141,13 @@ func (l *HorizontalLayout) Apply() {
 			c.Limit(minw, minh)
 
 			// Move and resize master
-			mp := l.Proportions.MasterMaster[msize][i%msize]
+			mp := mps[i%msize]
 			mw := int(math.Round(float64(dw-(msize+1)*gap) * mp))
-			c.MoveWindow(mx, my+gap, mw, mh-2*gap)
+			if l.Reversed {
+				c.MoveWindow(2*dx+dw-mx-mw, my+gap, mw, mh-2*gap)
+			} else {
+				c.MoveWindow(mx, my+gap, mw, mh-2*gap)
+			}
 
 			// Add x offset
 			mx += mw + gap
@@ -141,10 +170,11 @@ func (l *HorizontalLayout) Apply() {
 		}
 
 		sx := 0
+		sps := l.StackProportions(l.Slaves, l.Proportions.SlaveSlave[ssize])
 		for i, c := range l.Slaves.Stacked {
 
 			// Reset x position
-			if i%smax == 0 {
+			if i == 0 {
 				sx = dx + gap
 			}
 
@@ -154,28 +184,37 @@ func (l *HorizontalLayout) Apply() {
 			c.Limit(minw, minh)
 
 			// Move and resize slave
-			sp := l.Proportions.SlaveSlave[ssize][i%ssize]
+			sp := sps[common.MinInt(i, ssize-1)]
 			sw := int(math.Round(float64(dw-(ssize+1)*gap) * sp))
-			c.MoveWindow(sx, sy, sw, sh-gap)
+			if l.Reversed {
+				c.MoveWindow(2*dx+dw-sx-sw, sy, sw, sh-gap)
+			} else {
+				c.MoveWindow(sx, sy, sw, sh-gap)
+			}
 
-			// Add x offset
-			sx += sw + gap
+			// Add x offset (overflow is stacked behind the last slave)
+			if i < smax-1 {
+				sx += sw + gap
+			}
 		}
 	}
 }
 
 func (l *HorizontalLayout) UpdateProportions(c *store.Client, d *store.Directions) {
-	_, _, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	_, _, cw, ch := c.OuterGeometry()
+	_, dy, dw, dh := l.Geometry().Pieces()
+	_, cy, cw, ch := c.OuterGeometry()
 
-	gap := common.Config.WindowGapSize
+	gap := l.Gap
 
 	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	smax := l.SlaveSlots()
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
 
+	// Store directions before swapping
+	up, down := d.Top, d.Bottom
+
 	// Swap values if master is on top
 	idxms := 0
 	if l.Name == "horizontal-top" {
@@ -191,6 +230,38 @@ func (l *HorizontalLayout) UpdateProportions(c *store.Client, d *store.Direction
 		idxms ^= 1
 	}
 
+	// Swap values if order is mirrored
+	if l.Reversed {
+		d.Left, d.Right = d.Right, d.Left
+	}
+
+	// Calculate proportions based on stacked window geometry
+	if l.IsMaster(c) && l.Stacking {
+		idxmm := l.Index(l.Masters, c) % mmax
+
+		// Set master-slave proportions
+		if l.Name == "horizontal-top" && down && idxmm == msize-1 {
+			py := float64(cy+ch+gap-dy) / float64(dh)
+			l.Manager.SetProportions(l.Proportions.MasterSlave[2], py, idxms, idxms^1)
+		} else if l.Name == "horizontal-bottom" && up && idxmm == 0 {
+			py := float64(dy+dh-cy+gap) / float64(dh)
+			l.Manager.SetProportions(l.Proportions.MasterSlave[2], py, idxms, idxms^1)
+		}
+
+		// Set master-master proportions
+		mh := dh
+		if ssize > 0 {
+			mh = int(math.Round(float64(dh) * l.Proportions.MasterSlave[2][idxms]))
+		}
+		py := float64(ch) / float64(mh-(msize+1)*gap)
+		if up {
+			l.Manager.SetProportions(l.Proportions.MasterMaster[msize], py, idxmm, idxmm-1)
+		} else if down {
+			l.Manager.SetProportions(l.Proportions.MasterMaster[msize], py, idxmm, idxmm+1)
+		}
+		return
+	}
+
 	// Calculate proportions based on window geometry
 	if l.IsMaster(c) {
 		py := float64(ch+2*gap) / float64(dh)
@@ -211,7 +282,7 @@ func (l *HorizontalLayout) UpdateProportions(c *store.Client, d *store.Direction
 	} else {
 		py := float64(ch+gap) / float64(dh)
 		px := float64(cw) / float64(dw-(ssize+1)*gap)
-		idxss := l.Index(l.Slaves, c) % smax
+		idxss := common.MinInt(l.Index(l.Slaves, c), smax-1)
 
 		// Set master-slave proportions
 		if d.Bottom {
@@ -3,7 +3,6 @@ package layout
 import (
 	"math"
 
-	"github.com/leukipp/cortile/v2/common"
 	"github.com/leukipp/cortile/v2/store"
 
 	log "github.com/sirupsen/logrus"
@@ -17,14 +16,14 @@ type MaximizedLayout struct {
 func CreateMaximizedLayout(loc store.Location) *MaximizedLayout {
 	layout := &MaximizedLayout{
 		Name:    "maximized",
-		Manager: store.CreateManager(loc),
+		Manager: store.CreateManager(loc, "maximized"),
 	}
 	layout.Reset()
 	return layout
 }
 
 func (l *MaximizedLayout) Reset() {
-	mg := store.CreateManager(*l.Location)
+	mg := store.CreateManager(*l.Location, l.Name)
 
 	// Reset layout proportions
 	l.Manager.Proportions = mg.Proportions
@@ -33,8 +32,8 @@ func (l *MaximizedLayout) Reset() {
 func (l *MaximizedLayout) Apply() {
 	clients := l.Clients(store.Stacked)
 
-	dx, dy, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	gap := common.Config.WindowGapSize
+	dx, dy, dw, dh := l.Geometry().Pieces()
+	gap := l.Gap
 
 	csize := len(clients)
 
@@ -17,7 +17,7 @@ type VerticalLayout struct {
 func CreateVerticalLeftLayout(loc store.Location) *VerticalLayout {
 	layout := &VerticalLayout{
 		Name:    "vertical-left",
-		Manager: store.CreateManager(loc),
+		Manager: store.CreateManager(loc, "vertical-left"),
 	}
 	layout.Reset()
 	return layout
@@ -26,14 +26,14 @@ func CreateVerticalLeftLayout(loc store.Location) *VerticalLayout {
 func CreateVerticalRightLayout(loc store.Location) *VerticalLayout {
 	layout := &VerticalLayout{
 		Name:    "vertical-right",
-		Manager: store.CreateManager(loc),
+		Manager: store.CreateManager(loc, "vertical-right"),
 	}
 	layout.Reset()
 	return layout
 }
 
 func (l *VerticalLayout) Reset() {
-	mg := store.CreateManager(*l.Location)
+	mg := store.CreateManager(*l.Location, l.Name)
 
 	// Reset number of masters
 	for l.Masters.Maximum < mg.Masters.Maximum {
@@ -58,11 +58,11 @@ func (l *VerticalLayout) Reset() {
 func (l *VerticalLayout) Apply() {
 	clients := l.Clients(store.Stacked)
 
-	dx, dy, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
-	gap := common.Config.WindowGapSize
+	dx, dy, dw, dh := l.Geometry().Pieces()
+	gap := l.Gap
 
 	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	smax := l.SlaveSlots()
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -103,6 +103,7 @@ func (l *VerticalLayout) Apply() {
 		}
 
 		my := 0
+		mps := l.StackProportions(l.Masters, l.Proportions.MasterMaster[msize])
 		for i, c := range l.Masters.Stacked {
 
 			// Reset y position
@@ -116,9 +117,13 @@ func (l *VerticalLayout) Apply() {
 			c.Limit(minw, minh)
 
 			// Move and resize master
-			mp := l.Proportions.MasterMaster[msize][i%msize]
+			mp := mps[i%msize]
 			mh := int(math.Round(float64(dh-(msize+1)*gap) * mp))
-			c.MoveWindow(mx+gap, my, mw-2*gap, mh)
+			if l.Reversed {
+				c.MoveWindow(mx+gap, 2*dy+dh-my-mh, mw-2*gap, mh)
+			} else {
+				c.MoveWindow(mx+gap, my, mw-2*gap, mh)
+			}
 
 			// Add y offset
 			my += mh + gap
@@ -141,10 +146,11 @@ func (l *VerticalLayout) Apply() {
 		}
 
 		sy := 0
+		sps := l.StackProportions(l.Slaves, l.Proportions.SlaveSlave[ssize])
 		for i, c := range l.Slaves.Stacked {
 
 			// Reset y position
-			if i%smax == 0 {
+			if i == 0 {
 				sy = dy + gap
 			}
 
@@ -154,24 +160,30 @@ func (l *VerticalLayout) Apply() {
 			c.Limit(minw, minh)
 
 			// Move and resize slave
-			sp := l.Proportions.SlaveSlave[ssize][i%ssize]
+			sp := sps[common.MinInt(i, ssize-1)]
 			sh := int(math.Round(float64(dh-(ssize+1)*gap) * sp))
-			c.MoveWindow(sx, sy, sw-gap, sh)
+			if l.Reversed {
+				c.MoveWindow(sx, 2*dy+dh-sy-sh, sw-gap, sh)
+			} else {
+				c.MoveWindow(sx, sy, sw-gap, sh)
+			}
 
-			// Add y offset
-			sy += sh + gap
+			// Add y offset (overflow is stacked behind the last slave)
+			if i < smax-1 {
+				sy += sh + gap
+			}
 		}
 	}
 }
 
 func (l *VerticalLayout) UpdateProportions(c *store.Client, d *store.Directions) {
-	_, _, dw, dh := store.DesktopGeometry(l.Location.Screen).Pieces()
+	_, _, dw, dh := l.Geometry().Pieces()
 	_, _, cw, ch := c.OuterGeometry()
 
-	gap := common.Config.WindowGapSize
+	gap := l.Gap
 
 	mmax := l.Masters.Maximum
-	smax := l.Slaves.Maximum
+	smax := l.SlaveSlots()
 
 	msize := common.MinInt(len(l.Masters.Stacked), mmax)
 	ssize := common.MinInt(len(l.Slaves.Stacked), smax)
@@ -191,6 +203,11 @@ func (l *VerticalLayout) UpdateProportions(c *store.Client, d *store.Directions)
 		idxms ^= 1
 	}
 
+	// Swap values if order is mirrored
+	if l.Reversed {
+		d.Top, d.Bottom = d.Bottom, d.Top
+	}
+
 	// Calculate proportions based on window geometry
 	if l.IsMaster(c) {
 		px := float64(cw+2*gap) / float64(dw)
@@ -211,7 +228,7 @@ func (l *VerticalLayout) UpdateProportions(c *store.Client, d *store.Directions)
 	} else {
 		px := float64(cw+gap) / float64(dw)
 		py := float64(ch) / float64(dh-(ssize+1)*gap)
-		idxss := l.Index(l.Slaves, c) % smax
+		idxss := common.MinInt(l.Index(l.Slaves, c), smax-1)
 
 		// Set master-slave proportions
 		if d.Right {
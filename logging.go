@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type moduleFormatter struct {
+	Formatter log.Formatter        // Formatter of log output
+	Level     log.Level            // Default log level
+	Levels    map[string]log.Level // Log levels per module
+	Module    bool                 // Module name is added as field
+}
+
+func (f *moduleFormatter) Format(entry *log.Entry) ([]byte, error) {
+	module := ""
+
+	// Obtain module name from caller (e.g. github.com/leukipp/cortile/v2/store.(*Client).Update)
+	if entry.Caller != nil {
+		function := entry.Caller.Function[strings.LastIndex(entry.Caller.Function, "/")+1:]
+		module = strings.SplitN(function, ".", 2)[0]
+		entry.Caller = nil
+	}
+
+	// Drop entries above module level
+	level, ok := f.Levels[module]
+	if !ok {
+		level = f.Level
+	}
+	if entry.Level > level {
+		return nil, nil
+	}
+
+	// Add module name to fields
+	if f.Module && len(module) > 0 {
+		entry.Data["module"] = module
+	}
+
+	return f.Formatter.Format(entry)
+}
+
+func parseLogLevels(value string) (map[string]log.Level, error) {
+	levels := make(map[string]log.Level)
+
+	// Parse module levels (e.g. store=debug,ui=warn)
+	for _, item := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(item)) == 0 {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return levels, fmt.Errorf("'%s' is not in module=level format", item)
+		}
+		level, err := log.ParseLevel(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return levels, err
+		}
+		levels[strings.TrimSpace(parts[0])] = level
+	}
+
+	return levels, nil
+}
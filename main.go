@@ -66,6 +66,24 @@ func main() {
 	// Run dbus instance
 	runDbus()
 
+	// Run helper instance
+	runHelper()
+
+	// Run doctor instance
+	runDoctor()
+
+	// Run config check instance
+	runCheckConfig()
+
+	// Run cache instance
+	runCache()
+
+	// Run rules test instance
+	runRulesTest()
+
+	// Run worker instances
+	runWorkers()
+
 	// Run main instance
 	runMain()
 }
@@ -97,6 +115,146 @@ func runDbus() {
 	}
 }
 
+func runHelper() {
+	list := len(common.Args.List.P) > 0
+	focus := len(common.Args.Focus) > 0
+	layout := len(common.Args.Layout) > 0
+	explain := len(common.Args.Explain) > 0
+	inspect := common.Args.Inspect
+
+	// Print list values
+	if list {
+		input.List(common.Args.List.P[0], common.Args.List.Format)
+	}
+
+	// Activate window
+	if focus {
+		input.Method("WindowActivate", []string{common.Args.Focus})
+	}
+
+	// Activate layout
+	if layout {
+		input.Method("LayoutSwitch", []string{common.Args.Layout})
+	}
+
+	// Explain window
+	if explain {
+		input.Explain(common.Args.Explain)
+	}
+
+	// Inspect picked window
+	if inspect {
+		input.Method("WindowInspect", []string{})
+	}
+
+	// Prevent main instance start
+	if list || focus || layout || explain || inspect {
+		os.Exit(0)
+	}
+}
+
+func runCheckConfig() {
+	if !common.Args.Check {
+		return
+	}
+
+	// Validate config file
+	errs := common.ValidateConfig(common.Args.Config)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+
+	// Prevent main instance start
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	fmt.Printf("%s: config is valid\n", common.Args.Config)
+	os.Exit(0)
+}
+
+func runRulesTest() {
+	if !common.Args.Rules.Test {
+		return
+	}
+
+	// Validate config file
+	errs := common.ValidateConfig(common.Args.Config)
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	if len(errs) > 0 {
+		os.Exit(1)
+	}
+	common.DecodeConfig(common.Args.Config)
+
+	// Create window info from arguments
+	args := common.Args.Rules
+	info := &store.Info{
+		Class:    args.Class,
+		Name:     args.Name,
+		Process:  store.Process{Name: args.Process, Cmdline: args.Cmdline},
+		Location: store.Location{Desktop: uint(args.Desktop - 1), Screen: uint(args.Screen)},
+	}
+	if len(args.Type) > 0 {
+		info.Types = []string{args.Type}
+	}
+	if len(args.State) > 0 {
+		info.States = []string{args.State}
+	}
+
+	// Print matching rules
+	fmt.Printf("WINDOW: \n  class: %s\n  name: %s\n  desktop: %d\n  screen: %d\n\nRULES: \n", info.Class, info.Name, args.Desktop, args.Screen)
+	rules := store.MatchingRules(info)
+	for _, rule := range rules {
+		fmt.Printf("  match: %s\n", rule)
+	}
+	if len(rules) == 0 {
+		fmt.Println("  no rule matches")
+	}
+
+	// Print tiling decision
+	reason := store.SpecialReason(info)
+	if len(reason) == 0 {
+		reason = store.IgnoredReason(info)
+	}
+	fmt.Printf("\nDECISION: \n  tiled: %t\n", len(reason) == 0)
+	if len(reason) > 0 {
+		fmt.Printf("  reason: %s\n", reason)
+	}
+
+	// Prevent main instance start
+	os.Exit(0)
+}
+
+func runCache() {
+	if len(common.Args.CacheCmd) == 0 {
+		return
+	}
+
+	// Forward cache command to running instance
+	file, err := createLockFile(common.Args.Lock)
+	if err != nil {
+		input.Method("CacheManage", []string{common.Args.CacheCmd})
+		os.Exit(0)
+	}
+
+	// Init cache and config
+	common.InitCache()
+	common.DecodeConfig(common.Args.Config)
+	if common.CacheDisabled() {
+		fmt.Println(fmt.Errorf("%s: cache is disabled", common.Args.Cache))
+		os.Exit(1)
+	}
+
+	// Execute cache command
+	input.Cache(common.Args.CacheCmd)
+
+	// Prevent main instance start
+	common.Database.Close()
+	file.Close()
+	os.Exit(0)
+}
+
 func runMain() {
 	defer func() {
 		if err := recover(); err != nil {
@@ -115,18 +273,31 @@ func runMain() {
 	// Init root properties
 	store.InitRoot()
 
+	// Init client cache writer and pruning, tiling exemptions and extents calibration
+	store.InitClientCache()
+	store.InitCachePrune()
+	store.InitExemptions()
+	store.InitExtents()
+
 	// Create tracker instance
 	tr := desktop.CreateTracker()
 	input.Bind(tr)
 	tr.Update()
 
+	// Launch autostart applications
+	input.Autostart(tr)
+
 	// Show layout overlay
 	ws := tr.ActiveWorkspace()
 	if ws.TilingEnabled() {
 		ui.ShowLayout(ws)
 	}
 
-	// Run X event loop
+	// Notify service manager
+	common.SystemdNotify("READY=1")
+
+	// Run X event loop (restore windows on panic)
+	defer input.Recover(tr)
 	xevent.Main(store.X)
 }
 
@@ -141,16 +312,41 @@ func InitLock() *os.File {
 }
 
 func InitLog() *os.File {
+	level := log.WarnLevel
 	if common.Args.VVV {
-		log.SetLevel(log.TraceLevel)
+		level = log.TraceLevel
 	} else if common.Args.VV {
-		log.SetLevel(log.DebugLevel)
+		level = log.DebugLevel
 	} else if common.Args.V {
-		log.SetLevel(log.InfoLevel)
-	} else {
-		log.SetLevel(log.WarnLevel)
+		level = log.InfoLevel
 	}
-	log.SetFormatter(&log.TextFormatter{ForceColors: true, FullTimestamp: true})
+
+	// Parse log levels per module
+	levels, err := parseLogLevels(common.Args.LogLevels)
+	if err != nil {
+		fmt.Println(fmt.Errorf("log levels can't be parsed (%s)", err))
+		os.Exit(2)
+	}
+
+	// Set most verbose level for logger
+	verbose := level
+	for _, l := range levels {
+		verbose = max(verbose, l)
+	}
+	log.SetLevel(verbose)
+	log.SetReportCaller(true)
+
+	// Set log output format
+	var formatter log.Formatter = &log.TextFormatter{ForceColors: true, FullTimestamp: true}
+	if common.Args.LogFormat == "json" {
+		formatter = &log.JSONFormatter{}
+	}
+	log.SetFormatter(&moduleFormatter{
+		Formatter: formatter,
+		Level:     level,
+		Levels:    levels,
+		Module:    common.Args.LogFormat == "json",
+	})
 
 	file, err := createLogFile(common.Args.Log)
 	if err != nil {
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"math"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+type Transition struct {
+	Client  *Client         // Client of animated window
+	From    common.Geometry // Start geometry of window
+	To      common.Geometry // Target geometry of window
+	Current common.Geometry // Current geometry of animated window
+	Start   time.Time       // Start time of animation
+}
+
+var (
+	transitions map[xproto.Window]*Transition                                       // Collected window transitions
+	animations  map[xproto.Window]*Transition = make(map[xproto.Window]*Transition) // Running window animations
+)
+
+var (
+	frameRate int = 60 // Animation frames per second
+)
+
+func BeginTransitions() {
+	if common.Config.WindowAnimation <= 0 {
+		return
+	}
+
+	// Skip animations while pointer is dragging or session is idle
+	if (Pointer != nil && Pointer.Pressed()) || IsIdle() {
+		return
+	}
+
+	// Collect window movements from now on
+	transitions = make(map[xproto.Window]*Transition)
+}
+
+func EndTransitions() {
+	pending := transitions
+	transitions = nil
+	if len(pending) == 0 {
+		return
+	}
+
+	// Start animations of collected windows
+	running := len(animations) > 0
+	for w, tr := range pending {
+		tr.Current = tr.From
+		tr.Start = time.Now()
+		animations[w] = tr
+	}
+	if !running {
+		AfterFunc(time.Second/time.Duration(frameRate), animateFrame)
+	}
+}
+
+func animateFrame() {
+	duration := time.Duration(common.Config.WindowAnimation) * time.Millisecond
+
+	// Interpolate window geometries
+	for w, tr := range animations {
+		t := 1.0
+		if duration > 0 {
+			t = math.Min(float64(time.Since(tr.Start))/float64(duration), 1)
+		}
+
+		// Move windows to target geometries
+		if t >= 1 {
+			delete(animations, w)
+			tr.Client.moveresize(tr.To.Pieces())
+			tr.Client.Update()
+			continue
+		}
+
+		t = easeOut(t)
+		x := tr.From.X + int(math.Round(float64(tr.To.X-tr.From.X)*t))
+		y := tr.From.Y + int(math.Round(float64(tr.To.Y-tr.From.Y)*t))
+		w := tr.From.Width + int(math.Round(float64(tr.To.Width-tr.From.Width)*t))
+		h := tr.From.Height + int(math.Round(float64(tr.To.Height-tr.From.Height)*t))
+		tr.Current = common.Geometry{X: x, Y: y, Width: w, Height: h}
+		tr.Client.moveresize(x, y, w, h)
+	}
+
+	// Draw next frame on event loop
+	if len(animations) > 0 {
+		AfterFunc(time.Second/time.Duration(frameRate), animateFrame)
+	}
+}
+
+func transition(c *Client, x, y, w, h int) bool {
+	if transitions == nil {
+		return false
+	}
+	from := c.Latest.Dimensions.Geometry
+	to := common.Geometry{X: x, Y: y, Width: w, Height: h}
+
+	// Continue from current geometry of running animations
+	if tr, ok := animations[c.Window.Id]; ok {
+		from = tr.Current
+	}
+
+	// Skip windows without previous or changed geometry
+	if from.Width <= 0 || from.Height <= 0 || from == to {
+		return false
+	}
+
+	// Keep start geometry of already collected windows
+	if tr, ok := transitions[c.Window.Id]; ok {
+		tr.To = to
+		return true
+	}
+	transitions[c.Window.Id] = &Transition{Client: c, From: from, To: to}
+
+	return true
+}
+
+func stopTransition(c *Client) {
+	delete(animations, c.Window.Id)
+}
+
+func easeOut(t float64) float64 {
+	return 1 - math.Pow(1-t, 3)
+}
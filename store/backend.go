@@ -0,0 +1,591 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/motif"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xrect"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Server Backend // Window system backend
+)
+
+var (
+	errDryRunOffline = errors.New("dry-run backend is not connected to X") // Read operation without X connection
+)
+
+type Backend interface {
+	GetEwmhWM() (string, error)
+	NumberOfDesktopsGet() (uint, error)
+	NumberOfDesktopsReq(count uint) error
+	CurrentDesktopGet() (uint, error)
+	CurrentDesktopSet(desktop uint) error
+	ActiveWindowGet() (xproto.Window, error)
+	ActiveWindowSet(w xproto.Window) error
+	ClientListStackingGet() ([]xproto.Window, error)
+	WorkareaGet() ([]ewmh.Workarea, error)
+	WmStrutPartialGet(w xproto.Window) (*ewmh.WmStrutPartial, error)
+	WmClassGet(w xproto.Window) (*icccm.WmClass, error)
+	WmNameGet(w xproto.Window) (string, error)
+	WmDesktopGet(w xproto.Window) (uint, error)
+	WmDesktopSet(w xproto.Window, desktop uint) error
+	WmWindowTypeGet(w xproto.Window) ([]string, error)
+	WmTransientForGet(w xproto.Window) (xproto.Window, error)
+	WmStateGet(w xproto.Window) ([]string, error)
+	WmStateReq(w xproto.Window, action int, state string) error
+	WmProtocolsGet(w xproto.Window) ([]string, error)
+	WmFullscreenMonitorsReq(w xproto.Window, edges *ewmh.WmFullscreenMonitors) error
+	WmNormalHintsGet(w xproto.Window) (*icccm.NormalHints, error)
+	WmNormalHintsSet(w xproto.Window, hints *icccm.NormalHints) error
+	WmMotifHintsGet(w xproto.Window) (*motif.Hints, error)
+	WmMotifHintsSet(w xproto.Window, hints *motif.Hints) error
+	PropertyNums(w xproto.Window, name string) ([]uint, error)
+	WmWindowOpacitySet(w xproto.Window, opacity float64) error
+	OverrideRedirectGet(w xproto.Window) (bool, error)
+	MoveWindow(w xproto.Window, x, y int) error
+	MoveresizeWindow(w xproto.Window, x, y, width, height int) error
+	PreplaceWindow(w xproto.Window, x, y, width, height int) error
+	RestackWindow(w xproto.Window) error
+	CloseWindow(w xproto.Window) error
+	IconifyWindow(w xproto.Window) error
+	PingWindow(w xproto.Window) error
+	SyncWindow(w xproto.Window, value int64) error
+	KillWindow(w xproto.Window) error
+	DecorGeometry(w xproto.Window) (xrect.Rect, error)
+	RawGeometry(w xproto.Window) (xrect.Rect, error)
+}
+
+type X11Backend struct {
+	X *xgbutil.XUtil // X connection
+}
+
+func CreateX11Backend(X *xgbutil.XUtil) *X11Backend {
+	return &X11Backend{
+		X: X,
+	}
+}
+
+func (b *X11Backend) GetEwmhWM() (string, error) {
+	return ewmh.GetEwmhWM(b.X)
+}
+
+func (b *X11Backend) NumberOfDesktopsGet() (uint, error) {
+	return ewmh.NumberOfDesktopsGet(b.X)
+}
+
+func (b *X11Backend) CurrentDesktopGet() (uint, error) {
+	return ewmh.CurrentDesktopGet(b.X)
+}
+
+func (b *X11Backend) NumberOfDesktopsReq(count uint) error {
+	return ewmh.NumberOfDesktopsReq(b.X, int(count))
+}
+
+func (b *X11Backend) CurrentDesktopSet(desktop uint) error {
+	ewmh.CurrentDesktopSet(b.X, desktop)
+	return ewmh.ClientEvent(b.X, b.X.RootWin(), "_NET_CURRENT_DESKTOP", int(desktop), int(0))
+}
+
+func (b *X11Backend) ActiveWindowGet() (xproto.Window, error) {
+	return ewmh.ActiveWindowGet(b.X)
+}
+
+func (b *X11Backend) ActiveWindowSet(w xproto.Window) error {
+	ewmh.ActiveWindowSet(b.X, w)
+	return ewmh.ClientEvent(b.X, w, "_NET_ACTIVE_WINDOW", int(2), int(0), int(0))
+}
+
+func (b *X11Backend) ClientListStackingGet() ([]xproto.Window, error) {
+	return ewmh.ClientListStackingGet(b.X)
+}
+
+func (b *X11Backend) WorkareaGet() ([]ewmh.Workarea, error) {
+	return ewmh.WorkareaGet(b.X)
+}
+
+func (b *X11Backend) WmStrutPartialGet(w xproto.Window) (*ewmh.WmStrutPartial, error) {
+	values, err := b.PropertyNums(w, "_NET_WM_STRUT_PARTIAL")
+	if err != nil {
+		return nil, err
+	}
+	return parseStrutPartial(w, values), nil
+}
+
+func (b *X11Backend) WmClassGet(w xproto.Window) (*icccm.WmClass, error) {
+	values, err := xprop.PropValStrs(xprop.GetProperty(b.X, w, "WM_CLASS"))
+	if err != nil {
+		return nil, err
+	}
+	return parseWmClass(w, values), nil
+}
+
+func (b *X11Backend) WmNameGet(w xproto.Window) (string, error) {
+	return icccm.WmNameGet(b.X, w)
+}
+
+func (b *X11Backend) WmDesktopGet(w xproto.Window) (uint, error) {
+	return ewmh.WmDesktopGet(b.X, w)
+}
+
+func (b *X11Backend) WmDesktopSet(w xproto.Window, desktop uint) error {
+	ewmh.WmDesktopSet(b.X, w, desktop)
+	return ewmh.ClientEvent(b.X, w, "_NET_WM_DESKTOP", int(desktop), int(2))
+}
+
+func (b *X11Backend) WmWindowTypeGet(w xproto.Window) ([]string, error) {
+	return ewmh.WmWindowTypeGet(b.X, w)
+}
+
+func (b *X11Backend) WmTransientForGet(w xproto.Window) (xproto.Window, error) {
+	return icccm.WmTransientForGet(b.X, w)
+}
+
+func (b *X11Backend) WmStateGet(w xproto.Window) ([]string, error) {
+	return ewmh.WmStateGet(b.X, w)
+}
+
+func (b *X11Backend) WmStateReq(w xproto.Window, action int, state string) error {
+	return ewmh.WmStateReq(b.X, w, action, state)
+}
+
+func (b *X11Backend) WmProtocolsGet(w xproto.Window) ([]string, error) {
+	return icccm.WmProtocolsGet(b.X, w)
+}
+
+func (b *X11Backend) WmFullscreenMonitorsReq(w xproto.Window, edges *ewmh.WmFullscreenMonitors) error {
+	return ewmh.WmFullscreenMonitorsReq(b.X, w, edges)
+}
+
+func (b *X11Backend) WmNormalHintsGet(w xproto.Window) (*icccm.NormalHints, error) {
+	values, err := b.PropertyNums(w, "WM_NORMAL_HINTS")
+	if err != nil {
+		return nil, err
+	}
+	return parseNormalHints(w, values), nil
+}
+
+func (b *X11Backend) WmNormalHintsSet(w xproto.Window, hints *icccm.NormalHints) error {
+	return icccm.WmNormalHintsSet(b.X, w, hints)
+}
+
+func (b *X11Backend) WmMotifHintsGet(w xproto.Window) (*motif.Hints, error) {
+	values, err := b.PropertyNums(w, "_MOTIF_WM_HINTS")
+	if err != nil {
+		return nil, err
+	}
+	return parseMotifHints(w, values), nil
+}
+
+func (b *X11Backend) WmMotifHintsSet(w xproto.Window, hints *motif.Hints) error {
+	return motif.WmHintsSet(b.X, w, hints)
+}
+
+func (b *X11Backend) PropertyNums(w xproto.Window, name string) ([]uint, error) {
+	return xprop.PropValNums(xprop.GetProperty(b.X, w, name))
+}
+
+func (b *X11Backend) WmWindowOpacitySet(w xproto.Window, opacity float64) error {
+	if opacity < 1 {
+		return ewmh.WmWindowOpacitySet(b.X, w, opacity)
+	}
+
+	// Remove opacity of fully opaque windows
+	atom, err := xprop.Atm(b.X, "_NET_WM_WINDOW_OPACITY")
+	if err != nil {
+		return err
+	}
+	return xproto.DeletePropertyChecked(b.X.Conn(), w, atom).Check()
+}
+
+func (b *X11Backend) OverrideRedirectGet(w xproto.Window) (bool, error) {
+	attrs, err := xproto.GetWindowAttributes(b.X.Conn(), w).Reply()
+	if err != nil {
+		return false, err
+	}
+	return attrs.OverrideRedirect, nil
+}
+
+func (b *X11Backend) MoveWindow(w xproto.Window, x, y int) error {
+	return ewmh.MoveWindow(b.X, w, x, y)
+}
+
+func (b *X11Backend) MoveresizeWindow(w xproto.Window, x, y, width, height int) error {
+	return ewmh.MoveresizeWindow(b.X, w, x, y, width, height)
+}
+
+func (b *X11Backend) PreplaceWindow(w xproto.Window, x, y, width, height int) error {
+
+	// Request user specified geometry to skip placement of window manager
+	hints, err := icccm.WmNormalHintsGet(b.X, w)
+	if err != nil {
+		hints = &icccm.NormalHints{}
+	}
+	hints.Flags |= icccm.SizeHintUSPosition | icccm.SizeHintUSSize
+	hints.X, hints.Y = x, y
+	hints.Width, hints.Height = uint(width), uint(height)
+	if err := icccm.WmNormalHintsSet(b.X, w, hints); err != nil {
+		return err
+	}
+
+	// Configure unmapped window geometry
+	xwindow.New(b.X, w).MoveResize(x, y, width, height)
+
+	return nil
+}
+
+func (b *X11Backend) RestackWindow(w xproto.Window) error {
+	return ewmh.RestackWindow(b.X, w)
+}
+
+func (b *X11Backend) CloseWindow(w xproto.Window) error {
+	return ewmh.CloseWindow(b.X, w)
+}
+
+func (b *X11Backend) IconifyWindow(w xproto.Window) error {
+	return ewmh.ClientEvent(b.X, w, "WM_CHANGE_STATE", icccm.StateIconic)
+}
+
+func (b *X11Backend) PingWindow(w xproto.Window) error {
+	protocols, err := icccm.WmProtocolsGet(b.X, w)
+	if err != nil {
+		return err
+	}
+	if !common.IsInList("_NET_WM_PING", protocols) {
+		return fmt.Errorf("window %d does not support _NET_WM_PING", w)
+	}
+
+	// Send ping request to window
+	typ, err := xprop.Atm(b.X, "WM_PROTOCOLS")
+	if err != nil {
+		return err
+	}
+	ping, err := xprop.Atm(b.X, "_NET_WM_PING")
+	if err != nil {
+		return err
+	}
+	ev, err := xevent.NewClientMessage(32, w, typ, int(ping), int(xproto.TimeCurrentTime), int(w))
+	if err != nil {
+		return err
+	}
+	return xproto.SendEventChecked(b.X.Conn(), false, w, xproto.EventMaskNoEvent, string(ev.Bytes())).Check()
+}
+
+func (b *X11Backend) SyncWindow(w xproto.Window, value int64) error {
+	low, high := int(uint32(value)), int(uint32(value>>32))
+
+	// Send sync request message to window
+	typ, err := xprop.Atm(b.X, "WM_PROTOCOLS")
+	if err != nil {
+		return err
+	}
+	request, err := xprop.Atm(b.X, "_NET_WM_SYNC_REQUEST")
+	if err != nil {
+		return err
+	}
+	ev, err := xevent.NewClientMessage(32, w, typ, int(request), int(b.X.TimeGet()), low, high)
+	if err != nil {
+		return err
+	}
+	xproto.SendEvent(b.X.Conn(), false, w, xproto.EventMaskNoEvent, string(ev.Bytes()))
+
+	return nil
+}
+
+func (b *X11Backend) KillWindow(w xproto.Window) error {
+	return xproto.KillClientChecked(b.X.Conn(), uint32(w)).Check()
+}
+
+func (b *X11Backend) DecorGeometry(w xproto.Window) (xrect.Rect, error) {
+	return xwindow.New(b.X, w).DecorGeometry()
+}
+
+func (b *X11Backend) RawGeometry(w xproto.Window) (xrect.Rect, error) {
+	return xwindow.RawGeometry(b.X, xproto.Drawable(w))
+}
+
+type DryRunBackend struct {
+	*X11Backend            // Backend for read operations (without X if connection is nil)
+	Limit       int        // Maximum number of recorded write operations
+	operations  []string   // Recorded write operations (ring buffer)
+	next        int        // Ring buffer index of next recorded operation
+	mutex       sync.Mutex // Mutex for recorded operations
+}
+
+var (
+	dryRunLimit int = 1000 // Default maximum number of recorded dry-run operations
+)
+
+func CreateDryRunBackend(X *xgbutil.XUtil) *DryRunBackend {
+	return &DryRunBackend{
+		X11Backend: CreateX11Backend(X),
+		Limit:      dryRunLimit,
+		operations: []string{},
+	}
+}
+
+func (b *DryRunBackend) Operations() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// Return recorded operations from oldest to newest
+	operations := make([]string, 0, len(b.operations))
+	operations = append(operations, b.operations[b.next:]...)
+	operations = append(operations, b.operations[:b.next]...)
+
+	return operations
+}
+
+func (b *DryRunBackend) GetEwmhWM() (string, error) {
+	if b.X == nil {
+		return "", errDryRunOffline
+	}
+	return b.X11Backend.GetEwmhWM()
+}
+
+func (b *DryRunBackend) NumberOfDesktopsGet() (uint, error) {
+	if b.X == nil {
+		return 0, errDryRunOffline
+	}
+	return b.X11Backend.NumberOfDesktopsGet()
+}
+
+func (b *DryRunBackend) CurrentDesktopGet() (uint, error) {
+	if b.X == nil {
+		return 0, errDryRunOffline
+	}
+	return b.X11Backend.CurrentDesktopGet()
+}
+
+func (b *DryRunBackend) ActiveWindowGet() (xproto.Window, error) {
+	if b.X == nil {
+		return 0, errDryRunOffline
+	}
+	return b.X11Backend.ActiveWindowGet()
+}
+
+func (b *DryRunBackend) ClientListStackingGet() ([]xproto.Window, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.ClientListStackingGet()
+}
+
+func (b *DryRunBackend) WorkareaGet() ([]ewmh.Workarea, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WorkareaGet()
+}
+
+func (b *DryRunBackend) WmStrutPartialGet(w xproto.Window) (*ewmh.WmStrutPartial, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmStrutPartialGet(w)
+}
+
+func (b *DryRunBackend) WmClassGet(w xproto.Window) (*icccm.WmClass, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmClassGet(w)
+}
+
+func (b *DryRunBackend) WmNameGet(w xproto.Window) (string, error) {
+	if b.X == nil {
+		return "", errDryRunOffline
+	}
+	return b.X11Backend.WmNameGet(w)
+}
+
+func (b *DryRunBackend) WmDesktopGet(w xproto.Window) (uint, error) {
+	if b.X == nil {
+		return 0, errDryRunOffline
+	}
+	return b.X11Backend.WmDesktopGet(w)
+}
+
+func (b *DryRunBackend) WmWindowTypeGet(w xproto.Window) ([]string, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmWindowTypeGet(w)
+}
+
+func (b *DryRunBackend) WmTransientForGet(w xproto.Window) (xproto.Window, error) {
+	if b.X == nil {
+		return 0, errDryRunOffline
+	}
+	return b.X11Backend.WmTransientForGet(w)
+}
+
+func (b *DryRunBackend) WmStateGet(w xproto.Window) ([]string, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmStateGet(w)
+}
+
+func (b *DryRunBackend) WmProtocolsGet(w xproto.Window) ([]string, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmProtocolsGet(w)
+}
+
+func (b *DryRunBackend) WmNormalHintsGet(w xproto.Window) (*icccm.NormalHints, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmNormalHintsGet(w)
+}
+
+func (b *DryRunBackend) WmMotifHintsGet(w xproto.Window) (*motif.Hints, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.WmMotifHintsGet(w)
+}
+
+func (b *DryRunBackend) PropertyNums(w xproto.Window, name string) ([]uint, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.PropertyNums(w, name)
+}
+
+func (b *DryRunBackend) OverrideRedirectGet(w xproto.Window) (bool, error) {
+	if b.X == nil {
+		return false, errDryRunOffline
+	}
+	return b.X11Backend.OverrideRedirectGet(w)
+}
+
+func (b *DryRunBackend) DecorGeometry(w xproto.Window) (xrect.Rect, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.DecorGeometry(w)
+}
+
+func (b *DryRunBackend) RawGeometry(w xproto.Window) (xrect.Rect, error) {
+	if b.X == nil {
+		return nil, errDryRunOffline
+	}
+	return b.X11Backend.RawGeometry(w)
+}
+
+func (b *DryRunBackend) NumberOfDesktopsReq(count uint) error {
+	return b.record("NumberOfDesktopsReq", b.root(), count)
+}
+
+func (b *DryRunBackend) CurrentDesktopSet(desktop uint) error {
+	return b.record("CurrentDesktopSet", b.root(), desktop)
+}
+
+func (b *DryRunBackend) ActiveWindowSet(w xproto.Window) error {
+	return b.record("ActiveWindowSet", w)
+}
+
+func (b *DryRunBackend) WmDesktopSet(w xproto.Window, desktop uint) error {
+	return b.record("WmDesktopSet", w, desktop)
+}
+
+func (b *DryRunBackend) WmStateReq(w xproto.Window, action int, state string) error {
+	return b.record("WmStateReq", w, action, state)
+}
+
+func (b *DryRunBackend) WmFullscreenMonitorsReq(w xproto.Window, edges *ewmh.WmFullscreenMonitors) error {
+	return b.record("WmFullscreenMonitorsReq", w, edges.Top, edges.Bottom, edges.Left, edges.Right)
+}
+
+func (b *DryRunBackend) WmNormalHintsSet(w xproto.Window, hints *icccm.NormalHints) error {
+	return b.record("WmNormalHintsSet", w, hints.MinWidth, hints.MinHeight)
+}
+
+func (b *DryRunBackend) WmMotifHintsSet(w xproto.Window, hints *motif.Hints) error {
+	return b.record("WmMotifHintsSet", w, hints.Decoration)
+}
+
+func (b *DryRunBackend) MoveWindow(w xproto.Window, x, y int) error {
+	return b.record("MoveWindow", w, x, y)
+}
+
+func (b *DryRunBackend) MoveresizeWindow(w xproto.Window, x, y, width, height int) error {
+	return b.record("MoveresizeWindow", w, x, y, width, height)
+}
+
+func (b *DryRunBackend) PreplaceWindow(w xproto.Window, x, y, width, height int) error {
+	return b.record("PreplaceWindow", w, x, y, width, height)
+}
+
+func (b *DryRunBackend) RestackWindow(w xproto.Window) error {
+	return b.record("RestackWindow", w)
+}
+
+func (b *DryRunBackend) WmWindowOpacitySet(w xproto.Window, opacity float64) error {
+	return b.record("WmWindowOpacitySet", w, opacity)
+}
+
+func (b *DryRunBackend) CloseWindow(w xproto.Window) error {
+	return b.record("CloseWindow", w)
+}
+
+func (b *DryRunBackend) IconifyWindow(w xproto.Window) error {
+	return b.record("IconifyWindow", w)
+}
+
+func (b *DryRunBackend) PingWindow(w xproto.Window) error {
+	return b.record("PingWindow", w)
+}
+
+func (b *DryRunBackend) SyncWindow(w xproto.Window, value int64) error {
+	return b.record("SyncWindow", w, value)
+}
+
+func (b *DryRunBackend) KillWindow(w xproto.Window) error {
+	return b.record("KillWindow", w)
+}
+
+func (b *DryRunBackend) root() xproto.Window {
+	if b.X == nil {
+		return 0
+	}
+	return b.X.RootWin()
+}
+
+func (b *DryRunBackend) record(name string, w xproto.Window, values ...interface{}) error {
+	operation := fmt.Sprintf("%s %d %v", name, w, values)
+	log.Info("Dry-run operation ", operation)
+
+	// Store operation, overwrite oldest one if limit is reached
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.Limit <= 0 {
+		return nil
+	}
+	if len(b.operations) < b.Limit {
+		b.operations = append(b.operations, operation)
+		b.next = len(b.operations) % b.Limit
+		return nil
+	}
+	b.operations[b.next] = operation
+	b.next = (b.next + 1) % b.Limit
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+func TestDryRunBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		limit      int
+		count      int
+		operations []string
+	}{
+		{"empty", 3, 0, []string{}},
+		{"below limit", 3, 2, []string{"MoveWindow 1 [0 0]", "MoveWindow 2 [0 0]"}},
+		{"at limit", 3, 3, []string{"MoveWindow 1 [0 0]", "MoveWindow 2 [0 0]", "MoveWindow 3 [0 0]"}},
+		{"above limit", 3, 5, []string{"MoveWindow 3 [0 0]", "MoveWindow 4 [0 0]", "MoveWindow 5 [0 0]"}},
+		{"disabled", 0, 2, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := CreateDryRunBackend(nil)
+			b.Limit = tt.limit
+
+			// Record operations without X connection
+			for i := 1; i <= tt.count; i++ {
+				if err := b.MoveWindow(xproto.Window(i), 0, 0); err != nil {
+					t.Fatalf("MoveWindow() error = %v", err)
+				}
+			}
+			if operations := b.Operations(); !slices.Equal(operations, tt.operations) {
+				t.Errorf("Operations() = %v, want %v", operations, tt.operations)
+			}
+		})
+	}
+}
+
+func TestDryRunBackendOffline(t *testing.T) {
+	b := CreateDryRunBackend(nil)
+
+	// Read operations fail without X connection
+	if _, err := b.WmClassGet(1); err != errDryRunOffline {
+		t.Errorf("WmClassGet() error = %v, want %v", err, errDryRunOffline)
+	}
+	if err := b.CurrentDesktopSet(1); err != nil {
+		t.Errorf("CurrentDesktopSet() error = %v", err)
+	}
+	if operations := b.Operations(); !slices.Equal(operations, []string{"CurrentDesktopSet 0 [1]"}) {
+		t.Errorf("Operations() = %v, want %v", operations, []string{"CurrentDesktopSet 0 [1]"})
+	}
+}
@@ -2,23 +2,23 @@ package store
 
 import (
 	"fmt"
-	"os"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"encoding/json"
 	"path/filepath"
+	"runtime/debug"
 
 	"github.com/jezek/xgb/xproto"
 
 	"github.com/jezek/xgbutil/ewmh"
 	"github.com/jezek/xgbutil/icccm"
 	"github.com/jezek/xgbutil/motif"
-	"github.com/jezek/xgbutil/xprop"
 	"github.com/jezek/xgbutil/xrect"
-	"github.com/jezek/xgbutil/xwindow"
 
 	"github.com/leukipp/cortile/v2/common"
 
@@ -26,20 +26,30 @@ import (
 )
 
 type Client struct {
-	Window   *XWindow // X window object
-	Original *Info    `json:"-"` // Original client window information
-	Cached   *Info    `json:"-"` // Cached client window information
-	Latest   *Info    // Latest client window information
-	Locked   bool     // Internal client move/resize lock
+	Window   *XWindow        // X window object
+	Original *Info           `json:"-"` // Original client window information
+	Cached   *Info           `json:"-"` // Cached client window information
+	Latest   *Info           // Latest client window information
+	Locked   bool            // Internal client move/resize lock
+	Fixed    float64         // Fixed size proportion within its stack (0 = unfixed)
+	Opacity  float64         `json:"-"` // Current window opacity (0 = unknown)
+	Initial  float64         `json:"-"` // Window opacity set by the application (0 = unset)
+	Output   string          // Output name of the client screen
+	Slot     int             // Tile position within the workspace stack
+	Tile     common.Geometry // Computed tile geometry
+	Tabbed   int             `json:"-"` // Height of tab strip reserved on top of the tile
 }
 
 type Info struct {
-	Class      string     // Client window application name
-	Name       string     // Client window title name
-	Types      []string   // Client window types
-	States     []string   // Client window states
-	Location   Location   // Client window location
-	Dimensions Dimensions // Client window dimensions
+	Class      string        // Client window application name
+	Name       string        // Client window title name
+	Types      []string      // Client window types
+	States     []string      // Client window states
+	Transient  xproto.Window // Client window transient for parent window
+	Widget     bool          // Client window is probed as desktop widget
+	Process    Process       // Client window process information
+	Location   Location      // Client window location
+	Dimensions Dimensions    // Client window dimensions
 }
 
 type Dimensions struct {
@@ -49,6 +59,7 @@ type Dimensions struct {
 	AdjPos     bool              // Position adjustments on move/resize
 	AdjSize    bool              // Size adjustments on move/resize
 	AdjRestore bool              // Disable adjustments on restore
+	Toolkit    string            // Decoration toolkit of extents corrections (csd = client side, ssd = server side)
 }
 
 type Hints struct {
@@ -62,12 +73,28 @@ const (
 	Latest   uint8 = 3 // Flag to restore latest info
 )
 
+var (
+	dirtyClients  map[xproto.Window]dirtyClient = make(map[xproto.Window]dirtyClient) // Serialized clients with pending cache writes
+	dirtyMutex    sync.Mutex                                                          // Mutex for pending cache writes
+	pipGeometries map[string]common.Geometry    = make(map[string]common.Geometry)    // Picture-in-picture geometries per class
+	cachePrune    time.Duration                 = 6 * time.Hour                       // Interval of automatic cache pruning
+)
+
+type dirtyClient struct {
+	Cache common.Cache[*Client] // Cache object of client
+	Data  []byte                // Serialized client cache data
+}
+
 func CreateClient(w xproto.Window) *Client {
+	return CreateClientInfo(w, GetInfo(w))
+}
+
+func CreateClientInfo(w xproto.Window, info *Info) *Client {
 	c := &Client{
 		Window:   CreateXWindow(w),
-		Original: GetInfo(w),
-		Cached:   GetInfo(w),
-		Latest:   GetInfo(w),
+		Original: info,
+		Cached:   info.Copy(),
+		Latest:   info.Copy(),
 		Locked:   false,
 	}
 
@@ -79,6 +106,12 @@ func CreateClient(w xproto.Window) *Client {
 	c.Cached.Dimensions.Geometry = cached.Latest.Dimensions.Geometry
 	c.Cached.Location.Screen = ScreenGet(cached.Latest.Dimensions.Geometry.Center())
 
+	// Move geometry to screen with cached output name
+	if screen, ok := Workplace.Displays.ScreenIndex(cached.Output); ok && len(cached.Output) > 0 && screen != c.Cached.Location.Screen {
+		c.Cached.Dimensions.Geometry = screenGeometry(c.Cached.Dimensions.Geometry, screen)
+		c.Cached.Location.Screen = screen
+	}
+
 	// Restore window position
 	c.Restore(Cached)
 
@@ -86,9 +119,58 @@ func CreateClient(w xproto.Window) *Client {
 	c.Latest.Dimensions.Geometry = c.Cached.Dimensions.Geometry
 	c.Latest.Location.Screen = c.Cached.Location.Screen
 
+	// Restore fixed size and tile position
+	c.Fixed = cached.Fixed
+	c.Output = cached.Output
+	c.Slot = cached.Slot
+
 	return c
 }
 
+func InitClientCache() {
+
+	// Flush dirty clients periodically
+	go func() {
+		defer common.Recover()
+
+		for range time.Tick(1000 * time.Millisecond) {
+			FlushClients()
+		}
+	}()
+}
+
+func InitCachePrune() {
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Prune cache on the event loop periodically
+	pruneCache()
+}
+
+func pruneCache() {
+	common.PruneCache()
+	AfterFunc(cachePrune, pruneCache)
+}
+
+func FlushClients() {
+
+	// Obtain and reset dirty clients
+	dirtyMutex.Lock()
+	clients := dirtyClients
+	dirtyClients = make(map[xproto.Window]dirtyClient)
+	dirtyMutex.Unlock()
+
+	// Write serialized client caches
+	for _, dirty := range clients {
+		if err := dirty.Cache.Write(dirty.Data); err != nil {
+			log.Warn("Error writing client cache ", dirty.Cache.Key)
+			continue
+		}
+		log.Trace("Write client cache data ", dirty.Cache.Key)
+	}
+}
+
 func (c *Client) Lock() {
 	c.Locked = true
 }
@@ -98,7 +180,7 @@ func (c *Client) UnLock() {
 }
 
 func (c *Client) Limit(w, h int) bool {
-	if !Compatible("icccm.SizeHintPMinSize") {
+	if HasQuirk("ignore_min_size") || predictions != nil {
 		return false
 	}
 
@@ -111,18 +193,18 @@ func (c *Client) Limit(w, h int) bool {
 	nhints.Flags |= icccm.SizeHintPMinSize
 	nhints.MinWidth = uint(w - dw)
 	nhints.MinHeight = uint(h - dh)
-	icccm.WmNormalHintsSet(X, c.Window.Id, &nhints)
+	Server.WmNormalHintsSet(c.Window.Id, &nhints)
 
 	return true
 }
 
 func (c *Client) UnLimit() bool {
-	if !Compatible("icccm.SizeHintPMinSize") {
+	if HasQuirk("ignore_min_size") {
 		return false
 	}
 
 	// Restore window size limits
-	icccm.WmNormalHintsSet(X, c.Window.Id, &c.Cached.Dimensions.Hints.Normal)
+	Server.WmNormalHintsSet(c.Window.Id, &c.Cached.Dimensions.Hints.Normal)
 
 	return true
 }
@@ -139,7 +221,7 @@ func (c *Client) Decorate() bool {
 	mhints := c.Cached.Dimensions.Hints.Motif
 	mhints.Flags |= motif.HintDecorations
 	mhints.Decoration = motif.DecorationAll
-	motif.WmHintsSet(X, c.Window.Id, &mhints)
+	Server.WmMotifHintsSet(c.Window.Id, &mhints)
 
 	return true
 }
@@ -156,7 +238,7 @@ func (c *Client) UnDecorate() bool {
 	mhints := c.Cached.Dimensions.Hints.Motif
 	mhints.Flags |= motif.HintDecorations
 	mhints.Decoration = motif.DecorationNone
-	motif.WmHintsSet(X, c.Window.Id, &mhints)
+	Server.WmMotifHintsSet(c.Window.Id, &mhints)
 
 	return true
 }
@@ -167,7 +249,7 @@ func (c *Client) Fullscreen() bool {
 	}
 
 	// Fullscreen window
-	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_FULLSCREEN")
+	Server.WmStateReq(c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_FULLSCREEN")
 
 	return true
 }
@@ -178,7 +260,7 @@ func (c *Client) UnFullscreen() bool {
 	}
 
 	// Unfullscreen window
-	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_FULLSCREEN")
+	Server.WmStateReq(c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_FULLSCREEN")
 
 	return true
 }
@@ -189,20 +271,91 @@ func (c *Client) UnMaximize() bool {
 	}
 
 	// Unmaximize window
-	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_MAXIMIZED_VERT")
-	ewmh.WmStateReq(X, c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_MAXIMIZED_HORZ")
+	Server.WmStateReq(c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_MAXIMIZED_VERT")
+	Server.WmStateReq(c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_MAXIMIZED_HORZ")
+
+	return true
+}
+
+func (c *Client) Raise() bool {
+
+	// Raise window above siblings
+	Server.RestackWindow(c.Window.Id)
+
+	return true
+}
+
+func (c *Client) Close() bool {
+
+	// Request graceful window close
+	Server.CloseWindow(c.Window.Id)
+
+	return true
+}
+
+func (c *Client) Minimize() bool {
+
+	// Request window iconification
+	Server.IconifyWindow(c.Window.Id)
+
+	return true
+}
+
+func (c *Client) Pin() bool {
+	geom := c.pipGeometry()
+
+	// Pin window on top of all desktops
+	Server.WmStateReq(c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_ABOVE")
+	c.MoveToDesktop(^uint32(0))
+
+	// Move window into corner
+	c.MoveWindow(geom.X, geom.Y, geom.Width, geom.Height)
+
+	return true
+}
+
+func (c *Client) UnPin() bool {
+	c.Update()
+
+	// Remember picture-in-picture geometry
+	geom := c.Latest.Dimensions.Geometry
+	pipGeometries[c.Latest.Class] = geom
+	if data, err := json.Marshal(geom); err == nil {
+		pipCache(c.Latest.Class).Write(data)
+	}
+
+	// Unpin window from top of all desktops
+	Server.WmStateReq(c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_ABOVE")
+	Server.WmStateReq(c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_STICKY")
+	c.MoveToDesktop(uint32(Workplace.CurrentDesktop))
+
+	return true
+}
+
+func (c *Client) Span(edges ewmh.WmFullscreenMonitors) bool {
+
+	// Fullscreen window across monitors
+	Server.WmFullscreenMonitorsReq(c.Window.Id, &edges)
+	Server.WmStateReq(c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_FULLSCREEN")
+
+	return true
+}
+
+func (c *Client) UnSpan() bool {
+
+	// Restore fullscreen window to single monitor
+	Server.WmStateReq(c.Window.Id, ewmh.StateRemove, "_NET_WM_STATE_FULLSCREEN")
 
 	return true
 }
 
 func (c *Client) MoveToDesktop(desktop uint32) bool {
 	if desktop == ^uint32(0) {
-		ewmh.WmStateReq(X, c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_STICKY")
+		Server.WmStateReq(c.Window.Id, ewmh.StateAdd, "_NET_WM_STATE_STICKY")
 	}
 
 	// Set client desktop
-	ewmh.WmDesktopSet(X, c.Window.Id, uint(desktop))
-	ewmh.ClientEvent(X, c.Window.Id, "_NET_WM_DESKTOP", int(desktop), int(2))
+	Server.WmDesktopSet(c.Window.Id, uint(desktop))
 
 	return true
 }
@@ -215,24 +368,93 @@ func (c *Client) MoveToScreen(screen uint32) bool {
 	x, y := common.MaxInt(geom.Center().X-w/2, geom.X+100), common.MaxInt(geom.Center().Y-h/2, geom.Y+100)
 
 	// Move window and simulate tracker pointer press
-	ewmh.MoveWindow(X, c.Window.Id, x, y)
+	Server.MoveWindow(c.Window.Id, x, y)
 	Pointer.Press()
 
 	return true
 }
 
+func (c *Client) SetOpacity(opacity float64) bool {
+	if c.Opacity == 0 {
+		c.Opacity = 1
+
+		// Remember opacity set by the application
+		if values, err := Server.PropertyNums(c.Window.Id, "_NET_WM_WINDOW_OPACITY"); err == nil && len(values) > 0 {
+			c.Initial = float64(values[0]) / float64(0xffffffff)
+			c.Opacity = c.Initial
+		}
+	}
+
+	// Restore opacity set by the application
+	if opacity >= 1 && c.Initial > 0 {
+		opacity = c.Initial
+	}
+	if opacity == c.Opacity {
+		return false
+	}
+
+	// Set window opacity
+	if err := Server.WmWindowOpacitySet(c.Window.Id, opacity); err != nil {
+		c.Log().Warn("Error setting window opacity")
+		return false
+	}
+	c.Opacity = opacity
+
+	return true
+}
+
 func (c *Client) MoveWindow(x, y, w, h int) {
+	if predict(c, x, y, w, h) {
+		return
+	}
 	if c.Locked {
-		log.Info("Reject window move/resize [", c.Latest.Class, "]")
+		c.Log().Info("Reject window move/resize")
+		c.Trace("MoveWindow", "rejected")
 
 		// Remove lock
 		c.UnLock()
 		return
 	}
+	if w > 0 && h > 0 {
+		c.Tile = common.Geometry{X: x, Y: y, Width: w, Height: h}
+
+		// Reserve tab strip of grouped clients
+		if c.Tabbed > 0 && h > 2*c.Tabbed {
+			y, h = y+c.Tabbed, h-c.Tabbed
+		}
+	}
 
 	// Remove unwanted properties
-	c.UnMaximize()
+	if c.UnMaximize() && HasQuirk("async_unmaximize") {
+		c.deferUnmaximize(x, y, w, h)
+		return
+	}
+	c.move(x, y, w, h)
+}
+
+func (c *Client) move(x, y, w, h int) {
 	c.UnFullscreen()
+	c.Trace("MoveWindow", fmt.Sprintf("%d %d %d %d", x, y, w, h))
+
+	// Collect animated window transition
+	if w > 0 && h > 0 && transition(c, x, y, w, h) {
+		return
+	}
+
+	// Move and/or resize window
+	stopTransition(c)
+	c.moveresize(x, y, w, h)
+
+	// Update stored dimensions
+	c.Update()
+}
+
+func (c *Client) moveresize(x, y, w, h int) {
+
+	// Defer resizes until synced clients acknowledged the previous one
+	if w > 0 && h > 0 && c.deferSync(x, y, w, h) {
+		return
+	}
 
 	// Calculate dimension offsets
 	ext := c.Latest.Dimensions.Extents
@@ -245,27 +467,29 @@ func (c *Client) MoveWindow(x, y, w, h int) {
 		dw, dh = ext.Left+ext.Right, ext.Top+ext.Bottom
 	}
 
+	// Apply extents profile corrections
+	if offsets := ActiveExtents(c.Latest.Dimensions.Toolkit).Offsets; len(offsets) == 4 {
+		dx, dy, dw, dh = dx-offsets[0], dy-offsets[1], dw+offsets[2], dh+offsets[3]
+	}
+
 	// Move and/or resize window
 	if w > 0 && h > 0 {
-		ewmh.MoveresizeWindow(X, c.Window.Id, x+dx, y+dy, w-dw, h-dh)
+		Server.MoveresizeWindow(c.Window.Id, x+dx, y+dy, w-dw, h-dh)
 	} else {
-		ewmh.MoveWindow(X, c.Window.Id, x+dx, y+dy)
+		Server.MoveWindow(c.Window.Id, x+dx, y+dy)
 	}
-
-	// Update stored dimensions
-	c.Update()
 }
 
 func (c *Client) OuterGeometry() (x, y, w, h int) {
 
 	// Outer window dimensions (x/y relative to workspace)
-	oGeom, err := c.Window.Instance.DecorGeometry()
+	oGeom, err := Server.DecorGeometry(c.Window.Id)
 	if err != nil {
 		return
 	}
 
 	// Inner window dimensions (x/y relative to outer window)
-	iGeom, err := xwindow.RawGeometry(X, xproto.Drawable(c.Window.Id))
+	iGeom, err := Server.RawGeometry(c.Window.Id)
 	if err != nil {
 		return
 	}
@@ -298,14 +522,17 @@ func (c *Client) Restore(flag uint8) {
 		}
 	}
 
+	// Restore window opacity
+	c.SetOpacity(1)
+
 	// Restore window sizes
 	c.UnLimit()
 	c.UnMaximize()
 	c.UnFullscreen()
 
-	// Restore window decorations
-	if flag == Original {
-		if common.Config.WindowDecoration {
+	// Restore original window decorations (new clients keep theirs)
+	if flag == Original || (flag == Latest && common.Config.WindowAutoDecor) {
+		if common.Config.WindowDecoration || common.Config.WindowAutoDecor {
 			c.Decorate()
 		} else {
 			c.UnDecorate()
@@ -335,7 +562,7 @@ func (c *Client) Update() {
 	if len(info.Class) == 0 {
 		return
 	}
-	log.Debug("Update client info [", info.Class, "]")
+	log.WithField("class", info.Class).Debug("Update client info")
 
 	// Update client info
 	c.Latest = info
@@ -348,27 +575,23 @@ func (c *Client) Write() {
 
 	// Obtain cache object
 	cache := c.Cache()
+	cache.Data = nil
 
 	// Parse client cache
-	data, err := json.MarshalIndent(cache.Data, "", "  ")
+	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
-		log.Warn("Error parsing client cache [", c.Latest.Class, "]")
+		c.Log().Warn("Error parsing client cache")
 		return
 	}
 
-	// Write client cache
-	path := filepath.Join(cache.Folder, cache.Name)
-	err = os.WriteFile(path, data, 0644)
-	if err != nil {
-		log.Warn("Error writing client cache [", c.Latest.Class, "]")
-		return
-	}
-
-	log.Trace("Write client cache data ", cache.Name, " [", c.Latest.Class, "]")
+	// Mark client cache as dirty
+	dirtyMutex.Lock()
+	dirtyClients[c.Window.Id] = dirtyClient{Cache: cache, Data: data}
+	dirtyMutex.Unlock()
 }
 
 func (c *Client) Read() *Client {
-	if common.CacheDisabled()  || !common.Config.CacheWindows {
+	if common.CacheDisabled() || !common.Config.CacheWindows {
 		return c
 	}
 
@@ -376,22 +599,21 @@ func (c *Client) Read() *Client {
 	cache := c.Cache()
 
 	// Read client cache
-	path := filepath.Join(cache.Folder, cache.Name)
-	data, err := os.ReadFile(path)
-	if os.IsNotExist(err) {
-		log.Info("No client cache found [", c.Latest.Class, "]")
+	data, err := cache.Read()
+	if err != nil {
+		c.Log().Info("No client cache found")
 		return c
 	}
 
 	// Parse client cache
 	cached := &Client{}
-	err = json.Unmarshal([]byte(data), &cached)
+	err = json.Unmarshal(data, &cached)
 	if err != nil {
-		log.Warn("Error reading client cache [", c.Latest.Class, "]")
+		c.Log().Warn("Error reading client cache")
 		return c
 	}
 
-	log.Debug("Read client cache data ", cache.Name, " [", c.Latest.Class, "]")
+	c.Log().Debug("Read client cache data ", cache.Key)
 
 	return cached
 }
@@ -400,33 +622,107 @@ func (c *Client) Cache() common.Cache[*Client] {
 	subfolder := c.Latest.Class
 	filename := fmt.Sprintf("%s-%d", subfolder, c.Latest.Location.Desktop)
 
-	// Create client cache folder
-	folder := filepath.Join(common.Args.Cache, "workplaces", Workplace.Displays.Name, "clients", subfolder)
-	if _, err := os.Stat(folder); os.IsNotExist(err) {
-		os.MkdirAll(folder, 0755)
-	}
-
 	// Create client cache object
+	folder := filepath.Join("workplaces", Workplace.Displays.Name, "clients")
 	cache := common.Cache[*Client]{
-		Folder: folder,
-		Name:   common.HashString(filename, 20) + ".json",
+		Bucket: folder,
+		Key:    filename,
 		Data:   c,
 	}
 
 	return cache
 }
 
+func (c *Client) Log() *log.Entry {
+	return log.WithFields(log.Fields{
+		"client": c.Window.Id,
+		"class":  c.Latest.Class,
+	})
+}
+
+func screenGeometry(geom common.Geometry, screen uint) common.Geometry {
+	x, y, w, h := ScreenGeometry(screen).Pieces()
+
+	// Center geometry within screen
+	geom.Width, geom.Height = common.MinInt(geom.Width, w), common.MinInt(geom.Height, h)
+	geom.X, geom.Y = x+(w-geom.Width)/2, y+(h-geom.Height)/2
+
+	return geom
+}
+
+func (c *Client) pipGeometry() common.Geometry {
+
+	// Read remembered geometry
+	if geom, ok := pipGeometries[c.Latest.Class]; ok {
+		return geom
+	}
+	if data, err := pipCache(c.Latest.Class).Read(); err == nil {
+		geom := common.Geometry{}
+		if err := json.Unmarshal(data, &geom); err == nil {
+			return geom
+		}
+	}
+
+	// Calculate corner geometry
+	dx, dy, dw, dh := DesktopGeometry(c.Latest.Location.Screen).Pieces()
+	w, h := 480, 270
+	if len(common.Config.WindowPipSize) == 2 {
+		w, h = common.Config.WindowPipSize[0], common.Config.WindowPipSize[1]
+	}
+	gap := common.Config.WindowGapSize
+
+	x, y := dx+gap, dy+gap
+	if strings.HasSuffix(common.Config.WindowPipCorner, "right") {
+		x = dx + dw - w - gap
+	}
+	if strings.HasPrefix(common.Config.WindowPipCorner, "bottom") {
+		y = dy + dh - h - gap
+	}
+
+	return common.Geometry{X: x, Y: y, Width: w, Height: h}
+}
+
+func pipCache(class string) common.Cache[*common.Geometry] {
+
+	// Create picture-in-picture cache object
+	folder := filepath.Join("workplaces", Workplace.Displays.Name, "pip")
+	cache := common.Cache[*common.Geometry]{
+		Bucket: folder,
+		Key:    class,
+	}
+
+	return cache
+}
+
 func (c *Client) IsNew() bool {
 	created := time.UnixMilli(c.Window.Created)
 	return time.Since(created) < 1000*time.Millisecond
 }
 
 func IsSpecial(info *Info) bool {
+	reason := SpecialReason(info)
+	if len(reason) > 0 {
+		log.WithField("class", info.Class).Info("Ignore ", reason)
+	}
+
+	return len(reason) > 0
+}
+
+func SpecialReason(info *Info) string {
 
 	// Check internal windows
 	if info.Class == common.Build.Name {
-		log.Info("Ignore internal window [", info.Class, "]")
-		return true
+		return "internal window"
+	}
+
+	// Check desktop widgets
+	if info.Widget {
+		return "desktop widget window"
+	}
+
+	// Check transient windows
+	if info.Transient != 0 {
+		return "transient window"
 	}
 
 	// Check window types
@@ -445,10 +741,10 @@ func IsSpecial(info *Info) bool {
 		"_NET_WM_WINDOW_TYPE_MENU",
 		"_NET_WM_WINDOW_TYPE_DND",
 	}
+	types = append(types, common.Config.WindowIgnoreType...)
 	for _, typ := range info.Types {
 		if common.IsInList(typ, types) {
-			log.Info("Ignore window with type ", typ, " [", info.Class, "]")
-			return true
+			return "window with type " + typ
 		}
 	}
 
@@ -456,64 +752,258 @@ func IsSpecial(info *Info) bool {
 	states := []string{
 		"_NET_WM_STATE_HIDDEN",
 		"_NET_WM_STATE_MODAL",
-		"_NET_WM_STATE_ABOVE",
 		"_NET_WM_STATE_BELOW",
 		"_NET_WM_STATE_SKIP_PAGER",
 		"_NET_WM_STATE_SKIP_TASKBAR",
 	}
+	if common.Config.WindowAbove == "ignore" {
+		states = append(states, "_NET_WM_STATE_ABOVE")
+	}
+	states = append(states, common.Config.WindowIgnoreState...)
 	for _, state := range info.States {
 		if common.IsInList(state, states) {
-			log.Info("Ignore window with state ", state, " [", info.Class, "]")
-			return true
+			return "window with state " + state
 		}
 	}
 
-	return false
+	return ""
 }
 
 type ignoreSpec struct {
-	class, name *regexp.Regexp
+	class      regexRule
+	name       *regexp.Regexp
+	workspaces string
+	scope      []common.WorkspaceScope
 }
 
 func (spec *ignoreSpec) String() string {
+	if len(spec.scope) > 0 {
+		return spec.class.String() + " " + spec.name.String() + " on " + spec.workspaces
+	}
 	return spec.class.String() + " " + spec.name.String()
 }
 
+func (spec *ignoreSpec) applies(location Location) bool {
+	if len(spec.scope) == 0 {
+		return true
+	}
+
+	// Match desktop number and screen reference
+	for _, s := range spec.scope {
+		if s.Desktop > 0 && uint(s.Desktop-1) != location.Desktop {
+			continue
+		}
+		if len(s.Screen) > 0 {
+			if screen, ok := scopeScreen(s.Screen); !ok || screen != location.Screen {
+				continue
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+func (spec *ignoreSpec) unresolved() bool {
+	for _, s := range spec.scope {
+		if _, ok := scopeScreen(s.Screen); len(s.Screen) > 0 && !ok && Workplace == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func scopeScreen(ref string) (uint, bool) {
+	if Workplace != nil {
+		return Workplace.Displays.ScreenIndex(ref)
+	}
+
+	// Resolve screen index without display information
+	if i, err := strconv.Atoi(ref); err == nil && i >= 0 {
+		return uint(i), true
+	}
+
+	return 0, false
+}
+
 var windowIgnoreList []ignoreSpec
+var windowIgnoreConfig string
 
 func getWindowIgnoreList() []ignoreSpec {
-	if len(windowIgnoreList) == 0 && len(common.Config.WindowIgnore) > 0 {
-		for _, s := range common.Config.WindowIgnore {
-			conf_class := s[0]
-			conf_name := s[1]
-
-			spec := ignoreSpec{
-				class: regexp.MustCompile(strings.ToLower(conf_class)),
-				name:  regexp.MustCompile(strings.ToLower(conf_name)),
+
+	// Rebuild list when config values changed
+	config := fmt.Sprint(common.Config.WindowIgnore)
+	if config == windowIgnoreConfig {
+		return windowIgnoreList
+	}
+	windowIgnoreList = []ignoreSpec{}
+	windowIgnoreConfig = config
+
+	// Compile class and name regexes
+	for _, s := range common.Config.WindowIgnore {
+		if len(s) != 2 && len(s) != 3 {
+			log.Warn("Error parsing ignore entry ", s, ": expected [class, name, workspaces]")
+			continue
+		}
+		class, err := parseRule(s[0], "class")
+		if err != nil {
+			log.Warn("Error parsing regex ", s[0], ": ", err)
+			continue
+		}
+		name, err := regexp.Compile(strings.ToLower(s[1]))
+		if err != nil {
+			log.Warn("Error parsing regex ", s[1], ": ", err)
+			continue
+		}
+		spec := ignoreSpec{class: class, name: name}
+		if len(s) == 3 {
+			scope, err := common.ParseWorkspaceScope(s[2])
+			if err != nil {
+				log.Warn("Error parsing workspaces ", s[2], ": ", err)
+				continue
 			}
-			windowIgnoreList = append(windowIgnoreList, spec)
+			spec.workspaces, spec.scope = s[2], scope
 		}
+		windowIgnoreList = append(windowIgnoreList, spec)
 	}
 
 	return windowIgnoreList
 }
 
 func IsIgnored(info *Info) bool {
+	reason := IgnoredReason(info)
+	if len(reason) > 0 {
+		log.WithField("class", info.Class).WithField("name", info.Name).Info("Ignore ", reason)
+	}
+
+	return len(reason) > 0
+}
+
+func IsIgnoredTitle(info *Info) bool {
+
+	// Check ignored windows depending on window titles
+	for _, spec := range getWindowIgnoreList() {
+		if spec.name.String() != "" && spec.class.match(info) && spec.applies(info.Location) {
+			return true
+		}
+	}
+
+	return windowTitleRules.match(common.Config.WindowIgnoreTitle, "name", info)
+}
+
+func IgnoredReason(info *Info) string {
+
 	// Check invalid windows
 	if len(info.Class) == 0 {
-		log.Info("Ignore invalid window")
-		return true
+		return "invalid window"
 	}
+
 	// Check ignored windows
 	for _, spec := range getWindowIgnoreList() {
-		// Ignore all windows with this class
-		class_match := spec.class.MatchString(strings.ToLower(info.Class))
+		// Ignore all windows with this class on matching workspaces
+		class_match := spec.class.match(info) && spec.applies(info.Location)
 
 		// But allow the window with a special name
 		name_match := spec.name.String() != "" && spec.name.MatchString(strings.ToLower(info.Name))
 
 		if class_match && !name_match {
-			log.Info("Ignore window with ", spec.String(), " from config [", info.Name, "]")
+			return "window with " + spec.String() + " from config"
+		}
+	}
+
+	// Check ignored window titles
+	if windowTitleRules.match(common.Config.WindowIgnoreTitle, "name", info) {
+		return "window with title from config"
+	}
+
+	// Check exempted window classes
+	if IsExempted(info) {
+		return "window with exempted class"
+	}
+
+	return ""
+}
+
+type regexRule struct {
+	field string         // Matched window info field
+	expr  *regexp.Regexp // Compiled regex
+}
+
+type regexRules struct {
+	config string      // Config values of compiled rules
+	list   []regexRule // Compiled regex rules
+}
+
+var windowMasterRules regexRules
+var windowSlaveRules regexRules
+var windowGroupRules regexRules
+var windowTitleRules regexRules
+var windowOpaqueRules regexRules
+
+func parseRule(value string, field string) (regexRule, error) {
+
+	// Split optional field selector (e.g. "process:slack")
+	for _, f := range []string{"class", "name", "process", "cmdline", "cgroup", "app"} {
+		if strings.HasPrefix(value, f+":") {
+			field, value = f, strings.TrimPrefix(value, f+":")
+			break
+		}
+	}
+
+	// Compile regex
+	expr, err := regexp.Compile(strings.ToLower(value))
+
+	return regexRule{field: field, expr: expr}, err
+}
+
+func (r regexRule) match(info *Info) bool {
+	var value string
+
+	// Select window info field
+	switch r.field {
+	case "class":
+		value = info.Class
+	case "name":
+		value = info.Name
+	case "process":
+		value = info.Process.Name
+	case "cmdline":
+		value = info.Process.Cmdline
+	case "cgroup":
+		value = info.Process.Cgroup
+	case "app":
+		value = info.Process.AppId
+	}
+
+	return r.expr.MatchString(strings.ToLower(value))
+}
+
+func (r regexRule) String() string {
+	if r.field == "class" {
+		return r.expr.String()
+	}
+	return r.field + ":" + r.expr.String()
+}
+
+func (r *regexRules) match(values []string, field string, info *Info) bool {
+
+	// Rebuild list when config values changed
+	if config := fmt.Sprint(values); config != r.config {
+		r.list = []regexRule{}
+		r.config = config
+		for _, v := range values {
+			rule, err := parseRule(v, field)
+			if err != nil {
+				log.Warn("Error parsing regex ", v, ": ", err)
+				continue
+			}
+			r.list = append(r.list, rule)
+		}
+	}
+
+	// Check regex rules
+	for _, rule := range r.list {
+		if rule.match(info) {
 			return true
 		}
 	}
@@ -521,10 +1011,63 @@ func IsIgnored(info *Info) bool {
 	return false
 }
 
+func (r *regexRules) matches(values []string, field string, info *Info) []string {
+	matches := []string{}
+
+	// Collect matching regex rules
+	if r.match(values, field, info) {
+		for _, rule := range r.list {
+			if rule.match(info) {
+				matches = append(matches, rule.String())
+			}
+		}
+	}
+
+	return matches
+}
+
+func IsAlwaysMaster(info *Info) bool {
+	return windowMasterRules.match(common.Config.WindowMaster, "class", info)
+}
+
+func IsAlwaysSlave(info *Info) bool {
+	return windowSlaveRules.match(common.Config.WindowSlave, "class", info)
+}
+
+func GroupRule(info *Info) string {
+	if rules := windowGroupRules.matches(common.Config.WindowGroup, "class", info); len(rules) > 0 {
+		return rules[0]
+	}
+	return ""
+}
+
+func IsMatching(expr string, info *Info) bool {
+	rule, err := parseRule(expr, "class")
+	return err == nil && rule.match(info)
+}
+
+func IsAlwaysOpaque(info *Info) bool {
+	return windowOpaqueRules.match(common.Config.WindowOpaque, "class", info)
+}
+
+func (i *Info) Copy() *Info {
+	info := *i
+
+	// Copy window types and states
+	info.Types = append([]string{}, i.Types...)
+	info.States = append([]string{}, i.States...)
+
+	return &info
+}
+
 func IsFullscreen(info *Info) bool {
 	return common.IsInList("_NET_WM_STATE_FULLSCREEN", info.States)
 }
 
+func IsAbove(info *Info) bool {
+	return common.IsInList("_NET_WM_STATE_ABOVE", info.States)
+}
+
 func IsMaximized(info *Info) bool {
 	return common.IsInList("_NET_WM_STATE_MAXIMIZED_VERT", info.States) || common.IsInList("_NET_WM_STATE_MAXIMIZED_HORZ", info.States)
 }
@@ -537,6 +1080,51 @@ func IsSticky(info *Info) bool {
 	return common.IsInList("_NET_WM_STATE_STICKY", info.States)
 }
 
+func IsUrgent(info *Info) bool {
+	return common.IsInList("_NET_WM_STATE_DEMANDS_ATTENTION", info.States)
+}
+
+func GetInfos(windows []XWindow) map[xproto.Window]*Info {
+	infos := make(map[xproto.Window]*Info)
+
+	var mutex sync.Mutex
+	var group sync.WaitGroup
+	var failure error
+
+	// Limit number of parallel requests
+	workers := make(chan bool, 16)
+
+	// Request window infos in parallel
+	for _, w := range windows {
+		group.Add(1)
+		workers <- true
+		go func(w xproto.Window) {
+			defer group.Done()
+			defer func() {
+				if err := recover(); err != nil {
+					mutex.Lock()
+					failure = fmt.Errorf("%s\n%s", err, debug.Stack())
+					mutex.Unlock()
+				}
+				<-workers
+			}()
+			info := GetInfo(w)
+
+			mutex.Lock()
+			infos[w] = info
+			mutex.Unlock()
+		}(w.Id)
+	}
+	group.Wait()
+
+	// Raise panics of workers on the calling goroutine
+	if failure != nil {
+		panic(failure)
+	}
+
+	return infos
+}
+
 func GetInfo(w xproto.Window) *Info {
 	var err error
 
@@ -548,7 +1136,7 @@ func GetInfo(w xproto.Window) *Info {
 	var dimensions Dimensions
 
 	// Window class (internal class name of the window)
-	cls, err := icccm.WmClassGet(X, w)
+	cls, err := Server.WmClassGet(w)
 	if err != nil {
 		log.Trace("Error on request: ", err)
 	} else if cls != nil {
@@ -556,19 +1144,19 @@ func GetInfo(w xproto.Window) *Info {
 	}
 
 	// Window name (title on top of the window)
-	name, err = icccm.WmNameGet(X, w)
+	name, err = Server.WmNameGet(w)
 	if err != nil {
 		name = class
 	}
 
 	// Window geometry (dimensions of the window)
-	geom, err := CreateXWindow(w).Instance.DecorGeometry()
+	geom, err := Server.DecorGeometry(w)
 	if err != nil {
 		geom = &xrect.XRect{}
 	}
 
 	// Window desktop and screen (window workspace location)
-	desktop, err := ewmh.WmDesktopGet(X, w)
+	desktop, err := Server.WmDesktopGet(w)
 	sticky := desktop > Workplace.DesktopCount
 	if err != nil || sticky {
 		desktop = CurrentDesktopGet(X)
@@ -579,13 +1167,19 @@ func GetInfo(w xproto.Window) *Info {
 	}
 
 	// Window types (types of the window)
-	types, err = ewmh.WmWindowTypeGet(X, w)
+	types, err = Server.WmWindowTypeGet(w)
 	if err != nil {
 		types = []string{}
 	}
 
+	// Window transient for (parent of dialog windows)
+	transient, err := Server.WmTransientForGet(w)
+	if err != nil {
+		transient = 0
+	}
+
 	// Window states (states of the window)
-	states, err = ewmh.WmStateGet(X, w)
+	states, err = Server.WmStateGet(w)
 	if err != nil {
 		states = []string{}
 	}
@@ -593,28 +1187,35 @@ func GetInfo(w xproto.Window) *Info {
 		states = append(states, "_NET_WM_STATE_STICKY")
 	}
 
+	// Window widget probe (override redirect or reserved panel space)
+	redirect, _ := Server.OverrideRedirectGet(w)
+	strutNet, _ := PropertyValues(w, "_NET_WM_STRUT", 4)
+	strutPartial, _ := PropertyValues(w, "_NET_WM_STRUT_PARTIAL", 12)
+	widget := redirect || !common.AllZero(strutNet) || !common.AllZero(strutPartial)
+
+	// Window process (process information of the window)
+	pid, _ := PropertyValues(w, "_NET_WM_PID", 1)
+	process := ProcessGet(pid[0])
+
 	// Window normal hints (normal hints of the window)
-	nhints, err := icccm.WmNormalHintsGet(X, w)
+	nhints, err := Server.WmNormalHintsGet(w)
 	if err != nil {
 		nhints = &icccm.NormalHints{}
 	}
 
 	// Window motif hints (hints of the window)
-	mhints, err := motif.WmHintsGet(X, w)
+	mhints, err := Server.WmMotifHintsGet(w)
 	if err != nil {
 		mhints = &motif.Hints{}
 	}
 
 	// Window extents (server/client decorations of the window)
-	extNet, _ := xprop.PropValNums(xprop.GetProperty(X, w, "_NET_FRAME_EXTENTS"))
-	extGtk, _ := xprop.PropValNums(xprop.GetProperty(X, w, "_GTK_FRAME_EXTENTS"))
+	extNet, _ := PropertyValues(w, "_NET_FRAME_EXTENTS", 4)
+	extGtk, _ := PropertyValues(w, "_GTK_FRAME_EXTENTS", 4)
 
-	ext := make([]uint, 4)
-	for i, e := range extNet {
-		ext[i] += e
-	}
-	for i, e := range extGtk {
-		ext[i] -= e
+	ext := make([]int, 4)
+	for i := range ext {
+		ext[i] = int(extNet[i]) - int(extGtk[i])
 	}
 
 	// Window dimensions (geometry/extent information for move/resize)
@@ -625,14 +1226,27 @@ func GetInfo(w xproto.Window) *Info {
 			Motif:  *mhints,
 		},
 		Extents: ewmh.FrameExtents{
-			Left:   int(ext[0]),
-			Right:  int(ext[1]),
-			Top:    int(ext[2]),
-			Bottom: int(ext[3]),
+			Left:   ext[0],
+			Right:  ext[1],
+			Top:    ext[2],
+			Bottom: ext[3],
 		},
 		AdjPos:     (nhints.WinGravity > 1 && !common.AllZero(extNet)) || !common.AllZero(extGtk),
 		AdjSize:    !common.AllZero(extNet) || !common.AllZero(extGtk),
 		AdjRestore: !common.AllZero(extGtk),
+		Toolkit:    "ssd",
+	}
+	if !common.AllZero(extGtk) {
+		dimensions.Toolkit = "csd"
+	}
+
+	// Override adjustments with extents profile
+	profile := ActiveExtents(dimensions.Toolkit)
+	if profile.Position != nil {
+		dimensions.AdjPos = *profile.Position
+	}
+	if profile.Size != nil {
+		dimensions.AdjSize = *profile.Size
 	}
 
 	return &Info{
@@ -640,6 +1254,9 @@ func GetInfo(w xproto.Window) *Info {
 		Name:       name,
 		Types:      types,
 		States:     states,
+		Transient:  transient,
+		Widget:     widget,
+		Process:    process,
 		Location:   location,
 		Dimensions: dimensions,
 	}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"github.com/jezek/xgbutil"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+type Context struct {
+	Config        common.Configuration // Config values
+	X             *xgbutil.XUtil       // X connection
+	Server        Backend              // Window system backend
+	WindowManager *XWindowManager      // X window manager
+	Workplace     *XWorkplace          // X workplace
+	Pointer       *XPointer            // X pointer
+	Windows       *XWindows            // X windows
+}
+
+func CreateContext(conn *xgbutil.XUtil, backend Backend, config common.Configuration) *Context {
+	return &Context{
+		Config:        config,
+		X:             conn,
+		Server:        backend,
+		WindowManager: &XWindowManager{},
+		Workplace:     &XWorkplace{},
+		Pointer:       &XPointer{},
+		Windows:       &XWindows{},
+	}
+}
+
+func CurrentContext() *Context {
+	return &Context{
+		Config:        common.Config,
+		X:             X,
+		Server:        Server,
+		WindowManager: WindowManager,
+		Workplace:     Workplace,
+		Pointer:       Pointer,
+		Windows:       Windows,
+	}
+}
+
+func (ctx *Context) Activate() *Context {
+	previous := CurrentContext()
+
+	// Switch package state to context (test fixture swap, not safe for concurrent use)
+	common.Config = ctx.Config
+	X, Server, WindowManager = ctx.X, ctx.Server, ctx.WindowManager
+	Workplace, Pointer, Windows = ctx.Workplace, ctx.Pointer, ctx.Windows
+
+	return previous
+}
+
+func (ctx *Context) Capture() {
+
+	// Store package state in context
+	ctx.Config = common.Config
+	ctx.X, ctx.Server, ctx.WindowManager = X, Server, WindowManager
+	ctx.Workplace, ctx.Pointer, ctx.Windows = Workplace, Pointer, Windows
+}
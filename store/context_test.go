@@ -0,0 +1,66 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+func TestContextIgnoreRules(t *testing.T) {
+	ctx := useContext(t, common.Configuration{
+		WindowIgnore: [][]string{
+			{"firefox", "^picture-in-picture$"},
+			{"kitty", "", "4"},
+			{"mpv", "", "*:HDMI-1"},
+			{"process:slack", ""},
+		},
+		WindowIgnoreTitle: []string{"^splash"},
+	})
+	ctx.Workplace.Displays.Screens = []XHead{{Name: "eDP-1"}, {Name: "HDMI-1"}}
+
+	tests := []struct {
+		name    string
+		info    Info
+		ignored bool
+	}{
+		{"empty class", Info{}, true},
+		{"class match", Info{Class: "firefox", Name: "Mozilla Firefox"}, true},
+		{"class match with allowed name", Info{Class: "firefox", Name: "Picture-in-Picture"}, false},
+		{"class mismatch", Info{Class: "chromium"}, false},
+		{"desktop scope match", Info{Class: "kitty", Location: Location{Desktop: 3}}, true},
+		{"desktop scope mismatch", Info{Class: "kitty", Location: Location{Desktop: 0}}, false},
+		{"output scope match", Info{Class: "mpv", Location: Location{Screen: 1}}, true},
+		{"output scope mismatch", Info{Class: "mpv", Location: Location{Screen: 0}}, false},
+		{"process field match", Info{Class: "chat", Process: Process{Name: "slack"}}, true},
+		{"title match", Info{Class: "gimp", Name: "Splash screen"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := IgnoredReason(&tt.info); (len(reason) > 0) != tt.ignored {
+				t.Errorf("IgnoredReason(%+v) = %q, want ignored %t", tt.info, reason, tt.ignored)
+			}
+		})
+	}
+}
+
+func TestContextActivate(t *testing.T) {
+	config := common.Configuration{TilingLayout: "horizontal-top"}
+	ctx := CreateContext(nil, nil, config)
+
+	// Switch to context and back
+	previous := ctx.Activate()
+	if common.Config.TilingLayout != "horizontal-top" || Workplace != ctx.Workplace {
+		t.Fatalf("Activate() did not switch package state to context")
+	}
+	common.Config.TilingLayout = "vertical-left"
+	ctx.Capture()
+	previous.Activate()
+
+	if ctx.Config.TilingLayout != "vertical-left" {
+		t.Errorf("Capture() stored layout %q, want %q", ctx.Config.TilingLayout, "vertical-left")
+	}
+	if Workplace == ctx.Workplace {
+		t.Errorf("Activate() of previous context kept workplace of switched context")
+	}
+}
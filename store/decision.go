@@ -0,0 +1,57 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+type Decision struct {
+	Window xproto.Window // Window id
+	Class  string        // Window class name
+	Name   string        // Window title name
+	Tiled  bool          // Window is tiled
+	Reason string        // Reason the window is not tiled
+	Rules  []string      // Matching config rules
+}
+
+func MatchingRules(info *Info) []string {
+	rules := []string{}
+
+	// Collect matching regex rules
+	for _, r := range windowMasterRules.matches(common.Config.WindowMaster, "class", info) {
+		rules = append(rules, "window_master "+r)
+	}
+	for _, r := range windowSlaveRules.matches(common.Config.WindowSlave, "class", info) {
+		rules = append(rules, "window_slave "+r)
+	}
+	for _, r := range windowGroupRules.matches(common.Config.WindowGroup, "class", info) {
+		rules = append(rules, "window_group "+r)
+	}
+	for _, r := range windowOpaqueRules.matches(common.Config.WindowOpaque, "class", info) {
+		rules = append(rules, "window_opaque "+r)
+	}
+	for _, r := range windowTitleRules.matches(common.Config.WindowIgnoreTitle, "name", info) {
+		rules = append(rules, "window_ignore_title "+r)
+	}
+
+	// Collect matching ignore rules
+	for _, spec := range getWindowIgnoreList() {
+		if spec.class.match(info) && spec.unresolved() {
+			rules = append(rules, "window_ignore "+spec.String()+" (unresolved output name)")
+			continue
+		}
+		if !spec.class.match(info) || !spec.applies(info.Location) {
+			continue
+		}
+		if spec.name.String() != "" && spec.name.MatchString(strings.ToLower(info.Name)) {
+			rules = append(rules, "window_ignore "+spec.String()+" (allowed by name)")
+			continue
+		}
+		rules = append(rules, "window_ignore "+spec.String())
+	}
+
+	return rules
+}
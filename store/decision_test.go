@@ -0,0 +1,79 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+func TestMatchingRules(t *testing.T) {
+	ctx := useContext(t, common.Configuration{
+		WindowMaster:      []string{"^firefox$"},
+		WindowSlave:       []string{"name:^htop$"},
+		WindowGroup:       []string{"^alacritty$"},
+		WindowOpaque:      []string{"^mpv$"},
+		WindowIgnoreTitle: []string{"^splash"},
+		WindowIgnore: [][]string{
+			{"^gimp$", "^gimp$"},
+			{"^kitty$", "", "2"},
+			{"^mpv$", "", "*:HDMI-1"},
+		},
+	})
+	ctx.Workplace.Displays.Screens = []XHead{{Name: "eDP-1"}, {Name: "HDMI-1"}}
+
+	tests := []struct {
+		name  string
+		info  Info
+		rules []string
+	}{
+		{"no match", Info{Class: "chromium"}, []string{}},
+		{"master", Info{Class: "firefox"}, []string{"window_master ^firefox$"}},
+		{"slave by name", Info{Class: "kitty", Name: "htop"}, []string{"window_slave name:^htop$"}},
+		{"group", Info{Class: "alacritty"}, []string{"window_group ^alacritty$"}},
+		{"opaque", Info{Class: "mpv"}, []string{"window_opaque ^mpv$"}},
+		{"ignore allowed by name", Info{Class: "gimp", Name: "GIMP"}, []string{"window_ignore ^gimp$ ^gimp$ (allowed by name)"}},
+		{"ignore title", Info{Class: "gimp", Name: "Splash"}, []string{"window_ignore_title name:^splash", "window_ignore ^gimp$ ^gimp$"}},
+		{"ignored on desktop", Info{Class: "kitty", Location: Location{Desktop: 1}}, []string{"window_ignore ^kitty$  on 2"}},
+		{"ignored on output", Info{Class: "mpv", Location: Location{Screen: 1}}, []string{"window_opaque ^mpv$", "window_ignore ^mpv$  on *:HDMI-1"}},
+		{"other output", Info{Class: "mpv", Location: Location{Screen: 0}}, []string{"window_opaque ^mpv$"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rules := MatchingRules(&tt.info); !slices.Equal(rules, tt.rules) {
+				t.Errorf("MatchingRules(%+v) = %q, want %q", tt.info, rules, tt.rules)
+			}
+		})
+	}
+}
+
+func TestMatchingRulesUnresolved(t *testing.T) {
+	useContext(t, common.Configuration{
+		WindowIgnore: [][]string{
+			{"^mpv$", "", "*:HDMI-1"},
+			{"^kitty$", "", "2:1"},
+		},
+	})
+
+	// Evaluate rules without display information (e.g. rules test subcommand)
+	Workplace = nil
+
+	tests := []struct {
+		name  string
+		info  Info
+		rules []string
+	}{
+		{"output name", Info{Class: "mpv", Location: Location{Screen: 1}}, []string{"window_ignore ^mpv$  on *:HDMI-1 (unresolved output name)"}},
+		{"screen index", Info{Class: "kitty", Location: Location{Desktop: 1, Screen: 1}}, []string{"window_ignore ^kitty$  on 2:1"}},
+		{"other screen index", Info{Class: "kitty", Location: Location{Desktop: 1, Screen: 0}}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rules := MatchingRules(&tt.info); !slices.Equal(rules, tt.rules) {
+				t.Errorf("MatchingRules(%+v) = %q, want %q", tt.info, rules, tt.rules)
+			}
+		})
+	}
+}
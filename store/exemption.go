@@ -0,0 +1,95 @@
+package store
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Exemptions []string // Window classes exempted from tiling
+)
+
+func InitExemptions() {
+	Exemptions = []string{}
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Read exemption cache
+	data, err := exemptionCache().Read()
+	if err != nil {
+		log.Info("No exemption cache found")
+		return
+	}
+
+	// Parse exemption cache
+	err = json.Unmarshal(data, &Exemptions)
+	if err != nil {
+		log.Warn("Error reading exemption cache")
+		return
+	}
+
+	log.Debug("Read exemption cache data ", Exemptions)
+}
+
+func IsExempted(info *Info) bool {
+	return common.IsInList(info.Class, Exemptions)
+}
+
+func ToggleExemption(class string) bool {
+	exempted := !common.IsInList(class, Exemptions)
+
+	// Add or remove window class
+	classes := []string{}
+	for _, c := range Exemptions {
+		if c != class {
+			classes = append(classes, c)
+		}
+	}
+	if exempted {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	Exemptions = classes
+
+	log.WithField("class", class).Info("Update tiling exemption to ", exempted)
+
+	// Write exemption cache
+	writeExemptions()
+
+	return exempted
+}
+
+func writeExemptions() {
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Parse exemption cache
+	data, err := json.MarshalIndent(Exemptions, "", "  ")
+	if err != nil {
+		log.Warn("Error parsing exemption cache")
+		return
+	}
+
+	// Write exemption cache
+	err = exemptionCache().Write(data)
+	if err != nil {
+		log.Warn("Error writing exemption cache")
+		return
+	}
+
+	log.Trace("Write exemption cache data ", Exemptions)
+}
+
+func exemptionCache() common.Cache[[]string] {
+	return common.Cache[[]string]{
+		Bucket: "exemptions",
+		Key:    "classes",
+		Data:   Exemptions,
+	}
+}
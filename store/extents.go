@@ -0,0 +1,224 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Calibration map[string][]int // Calibrated corrections of move/resize requests per decoration toolkit
+)
+
+var (
+	extentsDetected  string        = "heuristic"            // Detected extents profile name
+	extentsDelay     time.Duration = 500 * time.Millisecond // Delay until calibration window is placed
+	calibrationMutex sync.Mutex                             // Mutex for calibrated corrections
+)
+
+func InitExtents() {
+	Calibration = map[string][]int{}
+
+	// Detect profile of window manager and compositor
+	extentsDetected = detectExtents()
+
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Read calibration cache
+	data, err := extentsCache().Read()
+	if err != nil {
+		log.Info("No extents calibration cache found")
+		return
+	}
+
+	// Parse calibration cache
+	err = json.Unmarshal(data, &Calibration)
+	if err != nil {
+		log.Warn("Error reading extents calibration cache")
+		Calibration = map[string][]int{}
+		return
+	}
+
+	log.Debug("Read extents calibration cache data ", Calibration)
+}
+
+func ActiveExtents(toolkit string) common.ExtentsProfile {
+	name := common.Config.WindowExtents
+
+	calibrationMutex.Lock()
+	defer calibrationMutex.Unlock()
+
+	// Use detected profile of window manager and compositor
+	if name == "auto" {
+		name = extentsDetected
+		if len(Calibration) > 0 {
+			name = "calibrated"
+		}
+	}
+
+	// Use measured corrections of toolkit
+	if name == "calibrated" {
+		return common.ExtentsProfile{Offsets: Calibration[toolkit]}
+	}
+
+	return common.ExtentsProfiles[name]
+}
+
+func CalibrateExtents(c *Client, done func()) bool {
+	if _, ok := Server.(*DryRunBackend); ok {
+		log.Warn("Calibration is not available in dry-run mode")
+		return false
+	}
+
+	dim := DesktopGeometry(Workplace.CurrentScreen)
+	target := common.Geometry{
+		X:      dim.X + dim.Width/4,
+		Y:      dim.Y + dim.Height/4,
+		Width:  dim.Width / 2,
+		Height: dim.Height / 2,
+	}
+
+	// Create decorated calibration window without active client
+	var win *xwindow.Window
+	if c == nil {
+		var err error
+		win, err = xwindow.Generate(X)
+		if err != nil {
+			log.Error("Calibration window generation failed: ", err)
+			return false
+		}
+		win.Create(X.RootWin(), target.X, target.Y, target.Width, target.Height, 0)
+		icccm.WmClassSet(win.X, win.Id, &icccm.WmClass{
+			Instance: common.Build.Name,
+			Class:    common.Build.Name,
+		})
+		icccm.WmNameSet(win.X, win.Id, fmt.Sprintf("%s calibration", common.Build.Name))
+		win.Map()
+	}
+
+	// Move calibration window to target geometry once placed
+	AfterFunc(extentsDelay, func() {
+		if win != nil {
+			c = &Client{Window: CreateXWindow(win.Id), Latest: GetInfo(win.Id)}
+		}
+		c.moveresize(target.Pieces())
+
+		// Measure deviation of actual geometry once moved
+		AfterFunc(extentsDelay, func() {
+			if win != nil {
+				defer win.Destroy()
+			}
+			measureExtents(c, target)
+			done()
+		})
+	})
+
+	return true
+}
+
+func measureExtents(c *Client, target common.Geometry) {
+	info := GetInfo(c.Window.Id)
+	actual, toolkit := info.Dimensions.Geometry, info.Dimensions.Toolkit
+	if actual.Width <= 0 || actual.Height <= 0 {
+		log.Warn("Calibration window geometry is unavailable")
+		return
+	}
+
+	// Combine deviation with active corrections of toolkit
+	offsets := append(ActiveExtents(toolkit).Offsets, 0, 0, 0, 0)[:4]
+	corrections := []int{
+		offsets[0] + actual.X - target.X,
+		offsets[1] + actual.Y - target.Y,
+		offsets[2] + actual.Width - target.Width,
+		offsets[3] + actual.Height - target.Height,
+	}
+	calibrationMutex.Lock()
+	Calibration[toolkit] = corrections
+	calibrationMutex.Unlock()
+
+	log.Info("Calibrate window extents ", corrections, " [", toolkit, ", ", common.Config.WindowExtents, "]")
+
+	// Write calibration cache
+	writeExtents()
+}
+
+func detectExtents() string {
+	detected := "heuristic"
+	if X == nil || WindowManager == nil {
+		return detected
+	}
+
+	// Match window manager name
+	wm := strings.ToLower(WindowManager.Name)
+	switch {
+	case strings.Contains(wm, "kwin"):
+		detected = "kde"
+	case strings.Contains(wm, "mutter"), strings.Contains(wm, "gnome"), strings.Contains(wm, "budgie"):
+		detected = "gtk-csd"
+	default:
+
+		// Match compositor name
+		atom, err := xprop.Atm(X, fmt.Sprintf("_NET_WM_CM_S%d", X.Conn().DefaultScreen))
+		if err != nil {
+			break
+		}
+		owner, err := xproto.GetSelectionOwner(X.Conn(), atom).Reply()
+		if err != nil || owner.Owner == 0 {
+			break
+		}
+		name, _ := Server.WmNameGet(owner.Owner)
+		if strings.Contains(strings.ToLower(name), "picom") || strings.Contains(strings.ToLower(name), "compton") {
+			detected = "picom"
+		}
+	}
+
+	log.Info("Detect extents profile ", detected, " [", WindowManager.Name, "]")
+
+	return detected
+}
+
+func writeExtents() {
+	if common.CacheDisabled() {
+		return
+	}
+
+	// Parse calibration cache
+	calibrationMutex.Lock()
+	data, err := json.Marshal(Calibration)
+	calibrationMutex.Unlock()
+	if err != nil {
+		log.Warn("Error parsing extents calibration cache")
+		return
+	}
+
+	// Write calibration cache
+	err = extentsCache().Write(data)
+	if err != nil {
+		log.Warn("Error writing extents calibration cache")
+		return
+	}
+
+	log.Trace("Write extents calibration cache data ", Calibration)
+}
+
+func extentsCache() common.Cache[map[string][]int] {
+	return common.Cache[map[string][]int]{
+		Bucket: "extents",
+		Key:    "calibrations",
+		Data:   Calibration,
+	}
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+var (
+	floatGeometries map[string]common.Geometry = make(map[string]common.Geometry) // Floating geometries per class and title
+)
+
+func (c *Client) Float() bool {
+	geom, ok := c.floatGeometry()
+	if !ok {
+		return false
+	}
+	c.Log().Info("Restore floating geometry ", geom)
+
+	// Move window to remembered geometry
+	c.MoveWindow(geom.X, geom.Y, geom.Width, geom.Height)
+
+	return true
+}
+
+func (c *Client) RememberFloat(persist bool) bool {
+	c.Update()
+
+	// Remember floating geometry
+	key := floatKey(c.Latest)
+	geom := c.Latest.Dimensions.Geometry
+	if geom.Width <= 0 || geom.Height <= 0 {
+		return false
+	}
+	floatGeometries[key] = geom
+
+	// Write floating geometry cache
+	if persist {
+		if data, err := json.Marshal(geom); err == nil {
+			floatCache(key).Write(data)
+		}
+	}
+
+	return true
+}
+
+func (c *Client) floatGeometry() (common.Geometry, bool) {
+	key := floatKey(c.Latest)
+
+	// Read remembered geometry
+	if geom, ok := floatGeometries[key]; ok {
+		return geom, true
+	}
+	if data, err := floatCache(key).Read(); err == nil {
+		geom := common.Geometry{}
+		if err := json.Unmarshal(data, &geom); err == nil {
+			floatGeometries[key] = geom
+			return geom, true
+		}
+	}
+
+	return common.Geometry{}, false
+}
+
+func floatKey(info *Info) string {
+	return fmt.Sprintf("%s-%s", info.Class, common.HashString(info.Name, 20))
+}
+
+func floatCache(key string) common.Cache[*common.Geometry] {
+
+	// Create floating geometry cache object
+	folder := filepath.Join("workplaces", Workplace.Displays.Name, "floating")
+	cache := common.Cache[*common.Geometry]{
+		Bucket: folder,
+		Key:    key,
+	}
+
+	return cache
+}
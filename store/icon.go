@@ -0,0 +1,61 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/xgraphics"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type Icon struct {
+	Id   xproto.Window // Icon window id
+	Size int           // Icon width and height
+}
+
+var (
+	icons      map[Icon]*xgraphics.Image = make(map[Icon]*xgraphics.Image) // Cached window icons
+	iconsMutex sync.Mutex                                                  // Mutex for cached window icons
+)
+
+func IconGet(w xproto.Window, size int) (*xgraphics.Image, error) {
+	key := Icon{Id: w, Size: size}
+
+	// Obtain cached icon
+	iconsMutex.Lock()
+	ico, ok := icons[key]
+	iconsMutex.Unlock()
+	if ok {
+		return ico, nil
+	}
+
+	// Find icon of window
+	ico, err := xgraphics.FindIcon(X, w, size, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cache icon of window
+	iconsMutex.Lock()
+	icons[key] = ico
+	iconsMutex.Unlock()
+
+	return ico, nil
+}
+
+func IconReset(w xproto.Window) {
+	iconsMutex.Lock()
+	defer iconsMutex.Unlock()
+
+	// Remove cached icons of window
+	for key := range icons {
+		if key.Id != w {
+			continue
+		}
+		log.Trace("Reset icon cache [", key.Id, "-", key.Size, "]")
+
+		delete(icons, key)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"time"
+
+	"github.com/jezek/xgb/screensaver"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	Presenting bool // Presentation mode suspends tiling, overlays and new windows
+)
+
+var (
+	idleSupported bool      // Screensaver extension is available
+	idleState     bool      // Cached idle state of the session
+	idleCheck     time.Time // Time when cached idle state expires
+)
+
+func initIdle() {
+	idleSupported = false
+
+	// Init screensaver extension
+	err := screensaver.Init(X.Conn())
+	if err != nil {
+		log.Info("Screensaver extension is not available, idle detection is disabled: ", err)
+		return
+	}
+	idleSupported = true
+
+	// Expire cached idle state on screensaver events
+	screensaver.SelectInput(X.Conn(), xproto.Drawable(X.RootWin()), screensaver.EventNotifyMask|screensaver.EventCycleMask)
+	xevent.HookFun(func(X *xgbutil.XUtil, ev interface{}) bool {
+		if _, ok := ev.(screensaver.NotifyEvent); ok {
+			idleCheck = time.Time{}
+			return false
+		}
+		return true
+	}).Connect(X)
+}
+
+func IsIdle() bool {
+	if !idleSupported || X == nil {
+		return false
+	}
+	if time.Now().Before(idleCheck) {
+		return idleState
+	}
+
+	// Query screensaver state
+	info, err := screensaver.QueryInfo(X.Conn(), xproto.Drawable(X.RootWin())).Reply()
+	if err != nil {
+		return false
+	}
+
+	// Session is idle while screensaver or locker is active
+	timeout := uint32(common.Config.IdleTimeout) * 1000
+	idleState = info.State == screensaver.StateOn || (timeout > 0 && info.MsSinceUserInput >= timeout)
+
+	// Cache active state until timeout is reached, recheck idle state for user input
+	switch {
+	case idleState:
+		idleCheck = time.Now().Add(time.Second)
+	case timeout > 0:
+		idleCheck = time.Now().Add(time.Duration(timeout-info.MsSinceUserInput) * time.Millisecond)
+	default:
+		idleCheck = time.Now().Add(time.Hour)
+	}
+
+	return idleState
+}
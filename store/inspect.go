@@ -0,0 +1,138 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/xprop"
+)
+
+type Inspection struct {
+	Window     xproto.Window // Inspected window id
+	Properties []Property    // Raw window properties
+	Info       *Info         // Interpreted window information
+	Decision   Decision      // Tiling decision of the window
+	Suggestion string        // Suggested window_ignore rule
+}
+
+type Property struct {
+	Name  string // Property atom name
+	Value string // Property value
+}
+
+var (
+	inspectProperties []string = []string{ // Inspected EWMH, ICCCM and Motif properties
+		"WM_CLASS",
+		"WM_NAME",
+		"_NET_WM_NAME",
+		"_NET_WM_PID",
+		"_NET_WM_DESKTOP",
+		"_NET_WM_WINDOW_TYPE",
+		"_NET_WM_STATE",
+		"WM_TRANSIENT_FOR",
+		"WM_NORMAL_HINTS",
+		"_MOTIF_WM_HINTS",
+		"_NET_FRAME_EXTENTS",
+		"_GTK_FRAME_EXTENTS",
+	}
+)
+
+func ClientWindow(w xproto.Window) (xproto.Window, bool) {
+	queue := []xproto.Window{w}
+
+	// Search stacked window within frame window children
+	for len(queue) > 0 {
+		w, queue = queue[0], queue[1:]
+		for _, s := range Windows.Stacked {
+			if s.Id == w {
+				return w, true
+			}
+		}
+		tree, err := xproto.QueryTree(X.Conn(), w).Reply()
+		if err != nil {
+			continue
+		}
+		queue = append(queue, tree.Children...)
+	}
+
+	return 0, false
+}
+
+func Inspect(w xproto.Window) Inspection {
+	info := GetInfo(w)
+
+	// Read raw window properties
+	properties := []Property{}
+	for _, name := range inspectProperties {
+		if value, ok := propertyValue(w, name); ok {
+			properties = append(properties, Property{Name: name, Value: value})
+		}
+	}
+
+	return Inspection{
+		Window:     w,
+		Properties: properties,
+		Info:       info,
+		Suggestion: fmt.Sprintf("[\"^%s$\", \"\"]", regexp.QuoteMeta(strings.ToLower(info.Class))),
+	}
+}
+
+func (i Inspection) Lines() []string {
+	info := i.Info
+	lines := []string{fmt.Sprintf("window %d [%s]", i.Window, info.Class)}
+
+	// Append raw window properties
+	for _, p := range i.Properties {
+		lines = append(lines, fmt.Sprintf("%s: %s", p.Name, p.Value))
+	}
+
+	// Append interpreted window information
+	dim := info.Dimensions
+	lines = append(lines,
+		fmt.Sprintf("workspace: desktop-%d-%d", info.Location.Desktop, info.Location.Screen),
+		fmt.Sprintf("extents: %d %d %d %d [position=%t, size=%t]", dim.Extents.Left, dim.Extents.Right, dim.Extents.Top, dim.Extents.Bottom, dim.AdjPos, dim.AdjSize),
+		fmt.Sprintf("tiled: %t", i.Decision.Tiled),
+	)
+	if len(i.Decision.Reason) > 0 {
+		lines = append(lines, fmt.Sprintf("reason: %s", i.Decision.Reason))
+	}
+	for _, rule := range i.Decision.Rules {
+		lines = append(lines, fmt.Sprintf("rule: %s", rule))
+	}
+	lines = append(lines, fmt.Sprintf("window_ignore: %s", i.Suggestion))
+
+	return lines
+}
+
+func propertyValue(w xproto.Window, name string) (string, bool) {
+	reply, err := xprop.GetProperty(X, w, name)
+	if err != nil {
+		return "", false
+	}
+
+	// Format string values
+	if reply.Format == 8 {
+		return strings.Trim(strings.ReplaceAll(string(reply.Value), "\x00", ", "), ", "), true
+	}
+
+	// Format atom values
+	if typ, _ := xprop.AtomName(X, reply.Type); typ == "ATOM" {
+		atoms, err := xprop.PropValAtoms(X, reply, nil)
+		return strings.Join(atoms, ", "), err == nil
+	}
+
+	// Format numeric values
+	nums, err := xprop.PropValNums(reply, nil)
+	if err != nil {
+		return "", false
+	}
+	values := make([]string, len(nums))
+	for j, n := range nums {
+		values[j] = fmt.Sprint(n)
+	}
+
+	return strings.Join(values, " "), true
+}
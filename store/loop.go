@@ -0,0 +1,123 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+	"github.com/jezek/xgbutil/xwindow"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	loopWindow *xwindow.Window // Hidden window receiving wake up messages
+	loopAtom   xproto.Atom     // Atom of wake up messages
+	loopQueue  []func()        // Functions waiting for the event loop
+	loopMutex  sync.Mutex      // Mutex of the function queue
+)
+
+type Timer struct {
+	Timer   *time.Timer // Underlying timer of the timeout
+	Stopped bool        // Timer was stopped or fired (event loop only)
+}
+
+func initLoop() {
+	win, err := xwindow.Generate(X)
+	if err != nil {
+		log.Error("Event loop window generation failed: ", err)
+		return
+	}
+	win.Create(X.RootWin(), -1, -1, 1, 1, 0)
+
+	// Attach wake up messages
+	loopAtom, _ = xprop.Atm(X, "_CORTILE_LOOP")
+	xevent.ClientMessageFun(func(X *xgbutil.XUtil, ev xevent.ClientMessageEvent) {
+		if ev.Type == loopAtom {
+			runLoop()
+		}
+	}).Connect(X, win.Id)
+
+	loopWindow = win
+}
+
+func Post(fun func()) {
+	if loopWindow == nil {
+		fun()
+		return
+	}
+
+	// Queue function and wake up event loop once
+	loopMutex.Lock()
+	loopQueue = append(loopQueue, fun)
+	wake := len(loopQueue) == 1
+	loopMutex.Unlock()
+	if !wake {
+		return
+	}
+
+	ev, err := xevent.NewClientMessage(32, loopWindow.Id, loopAtom, 0)
+	if err != nil {
+		log.Warn("Error waking up event loop: ", err)
+		return
+	}
+	xproto.SendEvent(X.Conn(), false, loopWindow.Id, xproto.EventMaskNoEvent, string(ev.Bytes()))
+}
+
+func Await(fun func()) {
+	done := make(chan struct{})
+
+	// Wait until function ran on the event loop (never call from the loop itself)
+	Post(func() {
+		defer close(done)
+		fun()
+	})
+	<-done
+}
+
+func AfterFunc(d time.Duration, fun func()) *Timer {
+	t := &Timer{}
+
+	// Run function on the event loop unless stopped
+	t.Timer = time.AfterFunc(d, func() {
+		Post(func() {
+			if t.Stopped {
+				return
+			}
+			t.Stopped = true
+			fun()
+		})
+	})
+
+	return t
+}
+
+func (t *Timer) Stop() bool {
+	if t == nil || t.Stopped {
+		return false
+	}
+	t.Stopped = true
+	t.Timer.Stop()
+
+	return true
+}
+
+func (t *Timer) Active() bool {
+	return t != nil && !t.Stopped
+}
+
+func runLoop() {
+	loopMutex.Lock()
+	queue := loopQueue
+	loopQueue = nil
+	loopMutex.Unlock()
+
+	// Run queued functions in order
+	for _, fun := range queue {
+		fun()
+	}
+}
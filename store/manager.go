@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/jezek/xgb/xproto"
+
 	"github.com/leukipp/cortile/v2/common"
 
 	log "github.com/sirupsen/logrus"
@@ -16,6 +18,9 @@ type Manager struct {
 	Masters     *Clients     // List of master window clients
 	Slaves      *Clients     // List of slave window clients
 	Decoration  bool         // Window decoration is enabled
+	Gap         int          // Gap size between windows
+	Reversed    bool         // Window order is mirrored
+	Stacking    bool         // Masters are stacked in horizontal layouts
 }
 
 type Location struct {
@@ -31,6 +36,7 @@ type Proportions struct {
 
 type Clients struct {
 	Maximum int       // Currently maximum allowed clients
+	Allowed int       `json:"-"` // Upper limit of maximum clients (-1 = unlimited)
 	Stacked []*Client `json:"-"` // List of stored window clients
 }
 
@@ -41,14 +47,18 @@ type Directions struct {
 	Left   bool // Indicates proportion changes on the left
 }
 
+var (
+	InsertMarker xproto.Window // One-shot insertion point of new clients
+)
+
 const (
 	Stacked uint8 = 1 // Flag for stacked (internal index order) clients
 	Ordered uint8 = 2 // Flag for ordered (bottom to top order) clients
 	Visible uint8 = 3 // Flag for visible (top only) clients
 )
 
-func CreateManager(loc Location) *Manager {
-	return &Manager{
+func CreateManager(loc Location, layout string) *Manager {
+	mg := &Manager{
 		Name:     fmt.Sprintf("manager-%d-%d", loc.Desktop, loc.Screen),
 		Location: &loc,
 		Proportions: &Proportions{
@@ -65,7 +75,14 @@ func CreateManager(loc Location) *Manager {
 			Stacked: make([]*Client, 0),
 		},
 		Decoration: common.Config.WindowDecoration,
+		Gap:        common.Config.WindowGapSize,
+		Stacking:   common.Config.WindowMasterStack,
 	}
+
+	// Apply allowed number of clients
+	mg.SetAllowed(common.ClientLimits(loc.Desktop, layout))
+
+	return mg
 }
 
 func (mg *Manager) EnableDecoration() {
@@ -84,12 +101,94 @@ func (mg *Manager) DecorationDisabled() bool {
 	return !mg.Decoration
 }
 
+func (mg *Manager) SetGap(gap int) {
+	mg.Gap = common.MaxInt(0, common.MinInt(gap, 100))
+}
+
+func (mg *Manager) SetAllowed(masters int, slaves int) {
+	mg.Masters.Allowed = masters
+	mg.Slaves.Allowed = slaves
+
+	// Clamp maximum number of clients
+	if !mg.Masters.Allows(mg.Masters.Maximum) {
+		mg.Masters.Maximum = mg.Masters.Allowed
+	}
+	if !mg.Slaves.Allows(mg.Slaves.Maximum) {
+		mg.Slaves.Maximum = mg.Slaves.Allowed
+	}
+
+	// Move overflow masters into slave area
+	for len(mg.Masters.Stacked) > mg.Masters.Maximum {
+		last := len(mg.Masters.Stacked) - 1
+		mg.Slaves.Stacked = append([]*Client{mg.Masters.Stacked[last]}, mg.Slaves.Stacked...)
+		mg.Masters.Stacked = mg.Masters.Stacked[:last]
+	}
+}
+
+func (mg *Manager) SlaveSlots() int {
+	smax := mg.Slaves.Maximum
+
+	// Show all slaves with shrink overflow policy
+	if common.Config.WindowOverflow == "shrink" && len(mg.Slaves.Stacked) > smax {
+		smax = len(mg.Slaves.Stacked)
+		extendProportions(mg.Proportions.SlaveSlave, smax)
+	}
+
+	return smax
+}
+
+func (mg *Manager) Overflow() []*Client {
+	smax := mg.SlaveSlots()
+	if len(mg.Slaves.Stacked) <= smax {
+		return []*Client{}
+	}
+
+	// Slaves exceeding the visible slots
+	return mg.Slaves.Stacked[smax:]
+}
+
+func (mg *Manager) Geometry() *common.Geometry {
+	dim := DesktopGeometry(mg.Location.Screen)
+
+	// Apply outer gap to tiling area
+	outer := common.Config.WindowGapOuter
+	if outer < 0 {
+		return dim
+	}
+	inset := outer - mg.Gap
+
+	return &common.Geometry{
+		X:      dim.X + inset,
+		Y:      dim.Y + inset,
+		Width:  dim.Width - 2*inset,
+		Height: dim.Height - 2*inset,
+	}
+}
+
 func (mg *Manager) AddClient(c *Client) {
 	if mg.IsMaster(c) || mg.IsSlave(c) {
 		return
 	}
 
-	log.Debug("Add client for manager [", c.Latest.Class, ", ", mg.Name, "]")
+	c.Log().WithField("manager", mg.Name).Debug("Add client for manager")
+
+	// Insert always master windows
+	if IsAlwaysMaster(c.Latest) {
+		mg.insertClient(c, 0)
+		return
+	}
+
+	// Insert always slave windows
+	if IsAlwaysSlave(c.Latest) {
+		mg.Slaves.Stacked = append(mg.Slaves.Stacked, c)
+		return
+	}
+
+	// Insert at marked client or by insertion policy
+	if i := mg.insertIndex(); i >= 0 {
+		mg.insertClient(c, i)
+		return
+	}
 
 	// Fill up master area then slave area
 	if len(mg.Masters.Stacked) < mg.Masters.Maximum {
@@ -99,8 +198,65 @@ func (mg *Manager) AddClient(c *Client) {
 	}
 }
 
+func (mg *Manager) PlaceClient(c *Client, master bool, index int) {
+	if !mg.IsMaster(c) && !mg.IsSlave(c) {
+		return
+	}
+
+	c.Log().WithField("manager", mg.Name).Debug("Place client for manager")
+
+	// Detach client from stacks
+	masters, slaves := []*Client{}, []*Client{}
+	for _, mc := range mg.Masters.Stacked {
+		if mc != c {
+			masters = append(masters, mc)
+		}
+	}
+	for _, sc := range mg.Slaves.Stacked {
+		if sc != c {
+			slaves = append(slaves, sc)
+		}
+	}
+
+	// Insert client into target stack
+	if master && mg.Masters.Maximum > 0 {
+		i := common.MinInt(common.MaxInt(index, 0), len(masters))
+		masters = append(masters[:i], append([]*Client{c}, masters[i:]...)...)
+	} else {
+		i := common.MinInt(common.MaxInt(index, 0), len(slaves))
+		slaves = append(slaves[:i], append([]*Client{c}, slaves[i:]...)...)
+	}
+
+	// Move overflowing masters into slave area
+	for len(masters) > mg.Masters.Maximum {
+		slaves = append([]*Client{masters[len(masters)-1]}, slaves...)
+		masters = masters[:len(masters)-1]
+	}
+	mg.Masters.Stacked = masters
+	mg.Slaves.Stacked = slaves
+}
+
+func (mg *Manager) OrderClients(order []*Client) {
+	clients := append(append([]*Client{}, mg.Masters.Stacked...), mg.Slaves.Stacked...)
+
+	// Sort clients by given order
+	sorted := []*Client{}
+	added := make(map[*Client]bool)
+	for _, c := range append(order, clients...) {
+		if !added[c] && (mg.IsMaster(c) || mg.IsSlave(c)) {
+			sorted = append(sorted, c)
+			added[c] = true
+		}
+	}
+
+	// Keep size of master and slave area
+	m := len(mg.Masters.Stacked)
+	mg.Masters.Stacked = append([]*Client{}, sorted[:m]...)
+	mg.Slaves.Stacked = append([]*Client{}, sorted[m:]...)
+}
+
 func (mg *Manager) RemoveClient(c *Client) {
-	log.Debug("Remove client from manager [", c.Latest.Class, ", ", mg.Name, "]")
+	c.Log().WithField("manager", mg.Name).Debug("Remove client from manager")
 
 	// Remove master window
 	mi := mg.Index(mg.Masters, c)
@@ -120,8 +276,21 @@ func (mg *Manager) RemoveClient(c *Client) {
 	}
 }
 
+func (mg *Manager) ReplaceClient(c *Client, r *Client) bool {
+
+	// Replace window at the same position
+	for _, windows := range []*Clients{mg.Masters, mg.Slaves} {
+		if i := mg.Index(windows, c); i >= 0 {
+			windows.Stacked[i] = r
+			return true
+		}
+	}
+
+	return false
+}
+
 func (mg *Manager) MakeMaster(c *Client) {
-	log.Info("Make window master [", c.Latest.Class, ", ", mg.Name, "]")
+	c.Log().WithField("manager", mg.Name).Info("Make window master")
 
 	// Swap window with first master
 	if len(mg.Masters.Stacked) > 0 {
@@ -130,7 +299,7 @@ func (mg *Manager) MakeMaster(c *Client) {
 }
 
 func (mg *Manager) SwapClient(c1 *Client, c2 *Client) {
-	log.Info("Swap clients [", c1.Latest.Class, "-", c2.Latest.Class, ", ", mg.Name, "]")
+	c1.Log().WithFields(log.Fields{"target": c2.Latest.Class, "manager": mg.Name}).Info("Swap clients")
 
 	mIndex1 := mg.Index(mg.Masters, c1)
 	sIndex1 := mg.Index(mg.Slaves, c1)
@@ -163,6 +332,27 @@ func (mg *Manager) SwapClient(c1 *Client, c2 *Client) {
 	}
 }
 
+func (mg *Manager) RotateClients(dir int) bool {
+	clients := mg.Clients(Stacked)
+	n := len(clients)
+	if n < 2 {
+		return false
+	}
+
+	// Shift clients through master and slave order
+	rotated := make([]*Client, n)
+	for i, c := range clients {
+		rotated[((i+dir)%n+n)%n] = c
+	}
+
+	// Split clients into masters and slaves
+	m := len(mg.Masters.Stacked)
+	mg.Masters.Stacked = append([]*Client{}, rotated[:m]...)
+	mg.Slaves.Stacked = append([]*Client{}, rotated[m:]...)
+
+	return true
+}
+
 func (mg *Manager) ActiveClient() *Client {
 	clients := mg.Clients(Stacked)
 
@@ -224,11 +414,38 @@ func (mg *Manager) PreviousClient() *Client {
 	return clients[prev]
 }
 
+func (mg *Manager) Swap(target *Manager) {
+
+	// Swap clients and layout state
+	mg.Proportions, target.Proportions = target.Proportions, mg.Proportions
+	mg.Masters.Stacked, target.Masters.Stacked = target.Masters.Stacked, mg.Masters.Stacked
+	mg.Masters.Maximum, target.Masters.Maximum = target.Masters.Maximum, mg.Masters.Maximum
+	mg.Slaves.Stacked, target.Slaves.Stacked = target.Slaves.Stacked, mg.Slaves.Stacked
+	mg.Slaves.Maximum, target.Slaves.Maximum = target.Slaves.Maximum, mg.Slaves.Maximum
+	mg.Decoration, target.Decoration = target.Decoration, mg.Decoration
+	mg.Gap, target.Gap = target.Gap, mg.Gap
+	mg.Reversed, target.Reversed = target.Reversed, mg.Reversed
+	mg.Stacking, target.Stacking = target.Stacking, mg.Stacking
+
+	// Clamp clients to allowed numbers
+	mg.SetAllowed(mg.Masters.Allowed, mg.Slaves.Allowed)
+	target.SetAllowed(target.Masters.Allowed, target.Slaves.Allowed)
+
+	// Update client locations
+	for _, c := range mg.Clients(Stacked) {
+		c.Latest.Location = *mg.Location
+	}
+	for _, c := range target.Clients(Stacked) {
+		c.Latest.Location = *target.Location
+	}
+}
+
 func (mg *Manager) IncreaseMaster() {
 
 	// Increase master area
-	if len(mg.Slaves.Stacked) > 1 && mg.Masters.Maximum < common.Config.WindowMastersMax {
+	if len(mg.Slaves.Stacked) > 1 && mg.Masters.Allows(mg.Masters.Maximum+1) {
 		mg.Masters.Maximum += 1
+		extendProportions(mg.Proportions.MasterMaster, mg.Masters.Maximum)
 		mg.Masters.Stacked = append(mg.Masters.Stacked, mg.Slaves.Stacked[0])
 		mg.Slaves.Stacked = mg.Slaves.Stacked[1:]
 	}
@@ -251,8 +468,9 @@ func (mg *Manager) DecreaseMaster() {
 func (mg *Manager) IncreaseSlave() {
 
 	// Increase slave area
-	if mg.Slaves.Maximum < common.Config.WindowSlavesMax {
+	if mg.Slaves.Allows(mg.Slaves.Maximum + 1) {
 		mg.Slaves.Maximum += 1
+		extendProportions(mg.Proportions.SlaveSlave, mg.Slaves.Maximum)
 	}
 
 	log.Info("Increase slaves to ", mg.Slaves.Maximum)
@@ -284,6 +502,98 @@ func (mg *Manager) DecreaseProportion() {
 	mg.SetProportions(mg.Proportions.MasterSlave[2], proportion, 0, 1)
 }
 
+func (mg *Manager) ResizeMaster(index int, step float64) bool {
+	ps := mg.Proportions.MasterSlave[2]
+	if index < 0 || index >= len(ps) {
+		return false
+	}
+
+	// Resize master area on given side index
+	return mg.SetProportions(ps, ps[index]+step, index, index^1)
+}
+
+func (mg *Manager) ResizeClient(c *Client, step float64) bool {
+	windows, proportions := mg.Slaves, mg.Proportions.SlaveSlave
+	if mg.IsMaster(c) {
+		windows, proportions = mg.Masters, mg.Proportions.MasterMaster
+	}
+
+	// Obtain visible client index
+	n := common.MinInt(len(windows.Stacked), windows.Maximum)
+	i := mg.Index(windows, c)
+	if n < 2 || i < 0 {
+		return false
+	}
+	i %= windows.Maximum
+
+	// Resize client against its next (or previous) neighbor
+	j := i + 1
+	if j >= n {
+		j = i - 1
+	}
+
+	return mg.SetProportions(proportions[n], proportions[n][i]+step, i, j)
+}
+
+func (mg *Manager) StackProportions(windows *Clients, ps []float64) []float64 {
+	n := len(ps)
+	if len(windows.Stacked) < n {
+		return ps
+	}
+
+	// Sum up fixed and free proportions
+	fixed, free, count := 0.0, 0.0, 0
+	for i, c := range windows.Stacked[:n] {
+		if c.Fixed > 0 {
+			fixed += c.Fixed
+		} else {
+			free += ps[i]
+			count++
+		}
+	}
+
+	// Ignore fixed sizes without enough remaining space
+	remaining := 1.0 - fixed
+	if fixed == 0 || count == 0 || remaining < float64(count)*common.Config.ProportionMin {
+		return ps
+	}
+
+	// Distribute remaining space among free clients
+	result := make([]float64, n)
+	for i, c := range windows.Stacked[:n] {
+		if c.Fixed > 0 {
+			result[i] = c.Fixed
+		} else {
+			result[i] = ps[i] / free * remaining
+		}
+	}
+
+	return result
+}
+
+func (mg *Manager) ToggleFixed(c *Client) bool {
+	windows, proportions := mg.Slaves, mg.Proportions.SlaveSlave
+	if mg.IsMaster(c) {
+		windows, proportions = mg.Masters, mg.Proportions.MasterMaster
+	}
+
+	// Release fixed size
+	if c.Fixed > 0 {
+		c.Fixed = 0
+		return true
+	}
+
+	// Obtain current size proportion
+	n := common.MinInt(len(windows.Stacked), windows.Maximum)
+	i := mg.Index(windows, c)
+	if n < 2 || i < 0 || i >= n {
+		return false
+	}
+	c.Fixed = mg.StackProportions(windows, proportions[n])[i]
+
+	return true
+}
+
 func (mg *Manager) SetProportions(ps []float64, pi float64, i int, j int) bool {
 
 	// Ignore changes on border sides
@@ -374,6 +684,61 @@ func (mg *Manager) Clients(flag uint8) []*Client {
 	return make([]*Client, 0)
 }
 
+func (mg *Manager) insertIndex() int {
+	clients := mg.Clients(Stacked)
+
+	// Find marked, active and previous active client
+	marked, active, previous := -1, -1, -1
+	for i, c := range clients {
+		switch c.Window.Id {
+		case InsertMarker:
+			marked = i
+		case Windows.Active.Id:
+			active = i
+		case Windows.Previous.Id:
+			previous = i
+		}
+	}
+	if marked >= 0 {
+		return marked
+	}
+
+	// New windows may already be focused
+	focused := active
+	if focused < 0 {
+		focused = previous
+	}
+
+	// Obtain index from insertion policy
+	switch common.Config.WindowInsert {
+	case "master":
+		return 0
+	case "before":
+		return focused
+	case "after":
+		if focused >= 0 {
+			return focused + 1
+		}
+	case "end":
+		return len(clients)
+	}
+
+	return -1
+}
+
+func (mg *Manager) insertClient(c *Client, i int) {
+	clients := append(append([]*Client{}, mg.Masters.Stacked...), mg.Slaves.Stacked...)
+	clients = append(clients[:i], append([]*Client{c}, clients[i:]...)...)
+
+	// Fill up master area then slave area
+	m := len(mg.Masters.Stacked)
+	if m < mg.Masters.Maximum {
+		m++
+	}
+	mg.Masters.Stacked = append([]*Client{}, clients[:m]...)
+	mg.Slaves.Stacked = append([]*Client{}, clients[m:]...)
+}
+
 func addClient(cs []*Client, c *Client) []*Client {
 	return append([]*Client{c}, cs...)
 }
@@ -382,6 +747,19 @@ func removeClient(cs []*Client, i int) []*Client {
 	return append(cs[:i], cs[i+1:]...)
 }
 
+func (cl *Clients) Allows(n int) bool {
+	return cl.Allowed < 0 || n <= cl.Allowed
+}
+
+func extendProportions(ps map[int][]float64, n int) {
+	if _, ok := ps[n]; ok {
+		return
+	}
+
+	// Add equal proportions for unknown sizes
+	ps[n] = calcProportions(n)[n]
+}
+
 func calcProportions(n int) map[int][]float64 {
 	p := map[int][]float64{}
 	for i := 1; i <= n; i++ {
@@ -0,0 +1,150 @@
+package store
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+func testClients(n int) []*Client {
+	clients := []*Client{}
+	for i := 1; i <= n; i++ {
+		clients = append(clients, &Client{
+			Window: &XWindow{Id: xproto.Window(i)},
+			Latest: &Info{Class: "test"},
+		})
+	}
+	return clients
+}
+
+func testManager(loc Location, masters []*Client, slaves []*Client) *Manager {
+	mg := CreateManager(loc, "vertical-left")
+	mg.Masters.Maximum = len(masters)
+	mg.Masters.Stacked = append([]*Client{}, masters...)
+	mg.Slaves.Stacked = append([]*Client{}, slaves...)
+	return mg
+}
+
+func testIds(clients []*Client) []xproto.Window {
+	ids := []xproto.Window{}
+	for _, c := range clients {
+		ids = append(ids, c.Window.Id)
+	}
+	return ids
+}
+
+func TestManagerSwapClient(t *testing.T) {
+	useContext(t, common.Configuration{WindowMastersMax: 2, WindowSlavesMax: 3})
+
+	tests := []struct {
+		name    string
+		c1      int
+		c2      int
+		masters []xproto.Window
+		slaves  []xproto.Window
+	}{
+		{"master with master", 0, 1, []xproto.Window{2, 1}, []xproto.Window{3, 4, 5}},
+		{"master with slave", 0, 3, []xproto.Window{4, 2}, []xproto.Window{3, 1, 5}},
+		{"slave with master", 4, 1, []xproto.Window{1, 5}, []xproto.Window{3, 4, 2}},
+		{"slave with slave", 2, 4, []xproto.Window{1, 2}, []xproto.Window{5, 4, 3}},
+		{"same client", 2, 2, []xproto.Window{1, 2}, []xproto.Window{3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := testClients(5)
+			mg := testManager(Location{}, cs[:2], cs[2:])
+
+			mg.SwapClient(cs[tt.c1], cs[tt.c2])
+			if masters := testIds(mg.Masters.Stacked); !slices.Equal(masters, tt.masters) {
+				t.Errorf("SwapClient() masters = %v, want %v", masters, tt.masters)
+			}
+			if slaves := testIds(mg.Slaves.Stacked); !slices.Equal(slaves, tt.slaves) {
+				t.Errorf("SwapClient() slaves = %v, want %v", slaves, tt.slaves)
+			}
+		})
+	}
+}
+
+func TestManagerSwap(t *testing.T) {
+	useContext(t, common.Configuration{WindowMastersMax: 2, WindowSlavesMax: 3})
+
+	tests := []struct {
+		name    string
+		allowed int
+		masters []xproto.Window
+		slaves  []xproto.Window
+	}{
+		{"unlimited", -1, []xproto.Window{1, 2}, []xproto.Window{3}},
+		{"within limit", 2, []xproto.Window{1, 2}, []xproto.Window{3}},
+		{"above limit", 1, []xproto.Window{1}, []xproto.Window{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := testClients(4)
+			source := testManager(Location{Desktop: 0, Screen: 0}, cs[:2], cs[2:3])
+			target := testManager(Location{Desktop: 1, Screen: 1}, cs[3:4], []*Client{})
+			target.SetAllowed(tt.allowed, -1)
+
+			// Swap clients into target manager with limited masters
+			source.Swap(target)
+			if masters := testIds(target.Masters.Stacked); !slices.Equal(masters, tt.masters) {
+				t.Errorf("Swap() target masters = %v, want %v", masters, tt.masters)
+			}
+			if slaves := testIds(target.Slaves.Stacked); !slices.Equal(slaves, tt.slaves) {
+				t.Errorf("Swap() target slaves = %v, want %v", slaves, tt.slaves)
+			}
+			if masters := testIds(source.Masters.Stacked); !slices.Equal(masters, []xproto.Window{4}) {
+				t.Errorf("Swap() source masters = %v, want %v", masters, []xproto.Window{4})
+			}
+
+			// Clients follow their manager location
+			for _, c := range target.Clients(Stacked) {
+				if c.Latest.Location != *target.Location {
+					t.Errorf("Swap() client %d location = %v, want %v", c.Window.Id, c.Latest.Location, *target.Location)
+				}
+			}
+			if c := cs[3]; c.Latest.Location != *source.Location {
+				t.Errorf("Swap() client %d location = %v, want %v", c.Window.Id, c.Latest.Location, *source.Location)
+			}
+		})
+	}
+}
+
+func TestManagerReplaceClient(t *testing.T) {
+	useContext(t, common.Configuration{WindowMastersMax: 2, WindowSlavesMax: 3})
+
+	tests := []struct {
+		name     string
+		c        int
+		replaced bool
+		masters  []xproto.Window
+		slaves   []xproto.Window
+	}{
+		{"master", 1, true, []xproto.Window{1, 9}, []xproto.Window{3, 4}},
+		{"slave", 3, true, []xproto.Window{1, 2}, []xproto.Window{3, 9}},
+		{"unmanaged", 4, false, []xproto.Window{1, 2}, []xproto.Window{3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := testClients(5)
+			mg := testManager(Location{}, cs[:2], cs[2:4])
+			r := &Client{Window: &XWindow{Id: 9}, Latest: &Info{Class: "test"}}
+
+			if replaced := mg.ReplaceClient(cs[tt.c], r); replaced != tt.replaced {
+				t.Errorf("ReplaceClient() = %t, want %t", replaced, tt.replaced)
+			}
+			if masters := testIds(mg.Masters.Stacked); !slices.Equal(masters, tt.masters) {
+				t.Errorf("ReplaceClient() masters = %v, want %v", masters, tt.masters)
+			}
+			if slaves := testIds(mg.Slaves.Stacked); !slices.Equal(slaves, tt.slaves) {
+				t.Errorf("ReplaceClient() slaves = %v, want %v", slaves, tt.slaves)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+)
+
+var (
+	pings     map[xproto.Window]bool = map[xproto.Window]bool{} // Windows with pending ping replies
+	pingsLock sync.Mutex                                        // Lock of pending ping replies
+)
+
+func (c *Client) Ping() bool {
+
+	// Send ping request to window
+	err := Server.PingWindow(c.Window.Id)
+	if err != nil {
+		c.Log().Debug("Error pinging client: ", err)
+		return false
+	}
+
+	// Wait for ping reply
+	pingsLock.Lock()
+	pings[c.Window.Id] = true
+	pingsLock.Unlock()
+
+	return true
+}
+
+func (c *Client) Responding() bool {
+	pingsLock.Lock()
+	defer pingsLock.Unlock()
+
+	return !pings[c.Window.Id]
+}
+
+func (c *Client) Kill() bool {
+	c.Log().Warn("Kill unresponsive client")
+
+	// Terminate client connection
+	return Server.KillWindow(c.Window.Id) == nil
+}
+
+func PingReply(X *xgbutil.XUtil, ev xevent.ClientMessageEvent) {
+	if ev.Format != 32 {
+		return
+	}
+
+	// Filter ping replies
+	tname, err := xprop.AtomName(X, ev.Type)
+	if err != nil || tname != "WM_PROTOCOLS" {
+		return
+	}
+	pname, err := xprop.AtomName(X, xproto.Atom(ev.Data.Data32[0]))
+	if err != nil || pname != "_NET_WM_PING" {
+		return
+	}
+
+	// Clear pending ping of window
+	pingsLock.Lock()
+	delete(pings, xproto.Window(ev.Data.Data32[2]))
+	pingsLock.Unlock()
+}
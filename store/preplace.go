@@ -0,0 +1,71 @@
+package store
+
+import (
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/icccm"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+var (
+	predictions map[xproto.Window]common.Geometry                                                 // Collected window predictions
+	preplaced   map[xproto.Window]*icccm.NormalHints = make(map[xproto.Window]*icccm.NormalHints) // Original normal hints of preplaced windows
+)
+
+func BeginPrediction() {
+
+	// Collect window movements without moving windows
+	predictions = make(map[xproto.Window]common.Geometry)
+}
+
+func EndPrediction() map[xproto.Window]common.Geometry {
+	pending := predictions
+	predictions = nil
+
+	return pending
+}
+
+func (c *Client) Preplace(geom common.Geometry) {
+	c.Log().Info("Preplace client at predicted tile")
+
+	// Calculate dimension offsets
+	ext := c.Latest.Dimensions.Extents
+	x, y, w, h := geom.Pieces()
+
+	// Remember original normal hints until first map
+	if _, ok := preplaced[c.Window.Id]; !ok {
+		hints, err := Server.WmNormalHintsGet(c.Window.Id)
+		if err != nil {
+			hints = &icccm.NormalHints{}
+		}
+		preplaced[c.Window.Id] = hints
+	}
+
+	// Move and resize unmapped window
+	Server.PreplaceWindow(c.Window.Id, x+ext.Left, y+ext.Top, w-ext.Left-ext.Right, h-ext.Top-ext.Bottom)
+}
+
+func RestorePreplaced(w xproto.Window, mapped bool) {
+	hints, ok := preplaced[w]
+	if !ok {
+		return
+	}
+	delete(preplaced, w)
+
+	// Restore original normal hints of mapped window
+	if mapped {
+		Server.WmNormalHintsSet(w, hints)
+	}
+}
+
+func predict(c *Client, x, y, w, h int) bool {
+	if predictions == nil {
+		return false
+	}
+
+	// Store predicted window geometry
+	predictions[c.Window.Id] = common.Geometry{X: x, Y: y, Width: w, Height: h}
+
+	return true
+}
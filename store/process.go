@@ -0,0 +1,97 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+type processEntry struct {
+	Process Process   // Cached process information
+	Started time.Time // Process folder time detecting reused pids
+}
+
+type Process struct {
+	Pid     uint   // Process id of the window
+	Session uint   // Process session id of the window
+	Name    string // Process name of the window
+	Cmdline string // Process command line of the window
+	Cgroup  string // Process control group of the window
+	AppId   string // Sandboxed application id (flatpak, snap)
+}
+
+var (
+	appIdPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`app-flatpak-(.+)-\d+\.scope$`),    // Flatpak application scope
+		regexp.MustCompile(`snap\.([^.]+\.[^.]+)-.+\.scope$`), // Snap application scope
+	}
+	processes    map[uint]processEntry = make(map[uint]processEntry) // Cached process information per pid
+	processMutex sync.Mutex                                          // Mutex of the process cache
+)
+
+func ProcessGet(pid uint) Process {
+	if pid == 0 {
+		return Process{Pid: pid}
+	}
+
+	// Validate cached process against reused pids
+	info, err := os.Stat(fmt.Sprintf("/proc/%d", pid))
+	processMutex.Lock()
+	defer processMutex.Unlock()
+	if err != nil {
+		delete(processes, pid)
+		return Process{Pid: pid}
+	}
+	if entry, ok := processes[pid]; ok && entry.Started.Equal(info.ModTime()) {
+		return entry.Process
+	}
+
+	// Read and cache process information
+	process := processRead(pid)
+	processes[pid] = processEntry{Process: process, Started: info.ModTime()}
+
+	return process
+}
+
+func processRead(pid uint) Process {
+	process := Process{Pid: pid}
+
+	// Process name
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/comm", pid)); err == nil {
+		process.Name = strings.TrimSpace(string(data))
+	}
+
+	// Process session id
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid)); err == nil {
+		stat := string(data)
+		if fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:]); len(fields) > 3 {
+			fmt.Sscan(fields[3], &process.Session)
+		}
+	}
+
+	// Process command line
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil {
+		process.Cmdline = strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	}
+
+	// Process control group
+	if data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid)); err == nil {
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if parts := strings.SplitN(lines[len(lines)-1], ":", 3); len(parts) == 3 {
+			process.Cgroup = parts[2]
+		}
+	}
+
+	// Sandboxed application id
+	for _, pattern := range appIdPatterns {
+		if match := pattern.FindStringSubmatch(process.Cgroup); match != nil {
+			process.AppId = match[1]
+			break
+		}
+	}
+
+	return process
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/motif"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type PropertyError struct {
+	Window   xproto.Window // Window id of property owner
+	Name     string        // Name of property
+	Length   int           // Number of values found
+	Expected int           // Number of values expected
+}
+
+func (e *PropertyError) Error() string {
+	return fmt.Sprintf("property %s of window %d has %d values, expected %d", e.Name, e.Window, e.Length, e.Expected)
+}
+
+func PropertyValues(w xproto.Window, name string, size int) ([]uint, error) {
+	values, err := Server.PropertyNums(w, name)
+	if err != nil {
+		return make([]uint, size), err
+	}
+	return parseValues(w, name, values, size), nil
+}
+
+func parseValues[T any](w xproto.Window, name string, values []T, size int) []T {
+	parsed := make([]T, size)
+	copy(parsed, values)
+
+	// Report malformed or truncated properties
+	if len(values) != size {
+		log.Debug("Error on property: ", &PropertyError{Window: w, Name: name, Length: len(values), Expected: size})
+	}
+
+	return parsed
+}
+
+func parseStrutPartial(w xproto.Window, values []uint) *ewmh.WmStrutPartial {
+	s := parseValues(w, "_NET_WM_STRUT_PARTIAL", values, 12)
+	return &ewmh.WmStrutPartial{
+		Left: s[0], Right: s[1], Top: s[2], Bottom: s[3],
+		LeftStartY: s[4], LeftEndY: s[5],
+		RightStartY: s[6], RightEndY: s[7],
+		TopStartX: s[8], TopEndX: s[9],
+		BottomStartX: s[10], BottomEndX: s[11],
+	}
+}
+
+func parseWmClass(w xproto.Window, values []string) *icccm.WmClass {
+	if len(values) == 1 {
+
+		// Use single value as instance and class name
+		values = append(values, values[0])
+	}
+	s := parseValues(w, "WM_CLASS", values, 2)
+	return &icccm.WmClass{
+		Instance: s[0],
+		Class:    s[1],
+	}
+}
+
+func parseNormalHints(w xproto.Window, values []uint) *icccm.NormalHints {
+	if len(values) == 15 {
+
+		// Accept pre ICCCM hints without base size and gravity
+		values = append(values, 0, 0, 0)
+	}
+	s := parseValues(w, "WM_NORMAL_HINTS", values, 18)
+	hints := &icccm.NormalHints{
+		Flags:        s[0],
+		X:            int(s[1]),
+		Y:            int(s[2]),
+		Width:        s[3],
+		Height:       s[4],
+		MinWidth:     s[5],
+		MinHeight:    s[6],
+		MaxWidth:     s[7],
+		MaxHeight:    s[8],
+		WidthInc:     s[9],
+		HeightInc:    s[10],
+		MinAspectNum: s[11],
+		MinAspectDen: s[12],
+		MaxAspectNum: s[13],
+		MaxAspectDen: s[14],
+		BaseWidth:    s[15],
+		BaseHeight:   s[16],
+		WinGravity:   s[17],
+	}
+
+	// Use default gravity for missing values
+	if hints.WinGravity <= 0 {
+		hints.WinGravity = xproto.GravityNorthWest
+	}
+
+	return hints
+}
+
+func parseMotifHints(w xproto.Window, values []uint) *motif.Hints {
+	s := parseValues(w, "_MOTIF_WM_HINTS", values, 5)
+	return &motif.Hints{
+		Flags:      s[0],
+		Function:   s[1],
+		Decoration: s[2],
+		Input:      s[3],
+		Status:     s[4],
+	}
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xprop"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+var (
+	quirkTimeout time.Duration = 100 * time.Millisecond // Maximum wait time of quirk workarounds
+)
+
+var (
+	unmaximizes      map[xproto.Window]*Unmaximize = make(map[xproto.Window]*Unmaximize) // Moves waiting for removed maximized states
+	unmaximizeHooked bool                                                                // Property events of unmaximized windows are attached
+)
+
+type Unmaximize struct {
+	Client   *Client         // Client waiting for removed maximized states
+	Geometry common.Geometry // Latest deferred window geometry
+	Timer    *Timer          // Timer to move the window without state change
+}
+
+func HasQuirk(name string) bool {
+
+	// Check user override
+	if enabled, ok := common.Config.Quirks[name]; ok {
+		return enabled
+	}
+
+	// Check detected window manager
+	quirk, ok := common.Quirks[name]
+	if !ok || WindowManager == nil {
+		return false
+	}
+	if quirk.Wayland && WindowManager.Wayland {
+		return true
+	}
+	wm := strings.ToLower(WindowManager.Name)
+	for _, manager := range quirk.Managers {
+		if strings.Contains(wm, manager) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ActiveQuirks() []string {
+	quirks := []string{}
+	for _, name := range common.QuirkNames() {
+		if HasQuirk(name) {
+			quirks = append(quirks, name)
+		}
+	}
+
+	return quirks
+}
+
+func (c *Client) deferUnmaximize(x, y, w, h int) {
+	geom := common.Geometry{X: x, Y: y, Width: w, Height: h}
+	if um, ok := unmaximizes[c.Window.Id]; ok {
+		um.Geometry = geom
+		return
+	}
+	attachUnmaximizeEvents()
+
+	// Move window once maximized states are removed or after timeout
+	um := &Unmaximize{Client: c, Geometry: geom}
+	um.Timer = AfterFunc(quirkTimeout, func() {
+		unmaximized(c.Window.Id)
+	})
+	unmaximizes[c.Window.Id] = um
+}
+
+func unmaximized(w xproto.Window) {
+	um, ok := unmaximizes[w]
+	if !ok {
+		return
+	}
+	delete(unmaximizes, w)
+	um.Timer.Stop()
+
+	// Apply deferred window geometry
+	um.Client.move(um.Geometry.Pieces())
+}
+
+func attachUnmaximizeEvents() {
+	if unmaximizeHooked {
+		return
+	}
+	unmaximizeHooked = true
+
+	// Attach state changes of waiting windows
+	xevent.HookFun(func(X *xgbutil.XUtil, ev interface{}) bool {
+		pev, ok := ev.(xproto.PropertyNotifyEvent)
+		if !ok {
+			return true
+		}
+		if _, ok := unmaximizes[pev.Window]; !ok {
+			return true
+		}
+		if aname, _ := xprop.AtomName(X, pev.Atom); aname != "_NET_WM_STATE" {
+			return true
+		}
+
+		// Continue once maximized states are removed
+		states, err := Server.WmStateGet(pev.Window)
+		if err != nil || (!common.IsInList("_NET_WM_STATE_MAXIMIZED_VERT", states) && !common.IsInList("_NET_WM_STATE_MAXIMIZED_HORZ", states)) {
+			unmaximized(pev.Window)
+		}
+
+		return true
+	}).Connect(X)
+}
@@ -2,11 +2,16 @@ package store
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/jezek/xgb/randr"
+	"github.com/jezek/xgb/xinerama"
 	"github.com/jezek/xgb/xproto"
 
 	"github.com/jezek/xgbutil"
@@ -30,7 +35,8 @@ var (
 )
 
 type XWindowManager struct {
-	Name string // Window manager name
+	Name    string // Window manager name
+	Wayland bool   // Window manager runs on XWayland
 }
 
 type XWorkplace struct {
@@ -56,9 +62,10 @@ type XHead struct {
 }
 
 type XPointer struct {
-	Drag     XDrag        // Pointer device drag states
-	Button   XButton      // Pointer device button states
-	Position common.Point // Pointer position coordinates
+	Drag      XDrag        // Pointer device drag states
+	Button    XButton      // Pointer device button states
+	Modifiers uint16       // Pointer device modifier key states
+	Position  common.Point // Pointer position coordinates
 }
 
 func (p *XPointer) Dragging(dt time.Duration) bool {
@@ -69,6 +76,36 @@ func (p *XPointer) Pressed() bool {
 	return p.Button.Left || p.Button.Middle || p.Button.Right
 }
 
+func (p *XPointer) Resizing() bool {
+	button := common.Config.ResizeButton
+	modifier := common.Config.ResizeModifier
+
+	// Check required pointer button
+	pressed := map[string]bool{"left": p.Button.Left, "middle": p.Button.Middle, "right": p.Button.Right}
+	if len(button) > 0 && !pressed[button] {
+		return false
+	}
+
+	// Check required modifier keys
+	masks := map[string]uint16{
+		"shift":   xproto.ModMaskShift,
+		"lock":    xproto.ModMaskLock,
+		"control": xproto.ModMaskControl,
+		"mod1":    xproto.ModMask1,
+		"mod2":    xproto.ModMask2,
+		"mod3":    xproto.ModMask3,
+		"mod4":    xproto.ModMask4,
+		"mod5":    xproto.ModMask5,
+	}
+	for _, m := range strings.Split(modifier, "-") {
+		if mask, ok := masks[strings.ToLower(m)]; ok && p.Modifiers&mask != mask {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *XPointer) Press() {
 	p.Button = XButton{true, true, true}
 }
@@ -98,8 +135,9 @@ type XButton struct {
 }
 
 type XWindows struct {
-	Active  XWindow   // Current active window
-	Stacked []XWindow // List of stacked windows
+	Active   XWindow   // Current active window
+	Previous XWindow   // Previous active window
+	Stacked  []XWindow // List of stacked windows
 }
 
 type XWindow struct {
@@ -119,6 +157,7 @@ func CreateXWindow(w xproto.Window) *XWindow {
 var (
 	stateCallbacksFun   []func(string, uint, uint)   // State events callback functions
 	pointerCallbacksFun []func(XPointer, uint, uint) // Pointer events callback functions
+	strutWindows        map[xproto.Window]bool       // Windows with attached strut events
 )
 
 func InitRoot() {
@@ -129,6 +168,25 @@ func InitRoot() {
 		log.Fatal("Connection to X server failed: exit")
 	}
 
+	// Init root state
+	initState()
+}
+
+func Setup(conn *xgbutil.XUtil, backend Backend) error {
+
+	// Use existing X connection
+	if err := attach(conn, backend); err != nil {
+		return err
+	}
+
+	// Init root state
+	initState()
+
+	return nil
+}
+
+func initState() {
+
 	// Init pointer
 	Pointer = PointerGet(X)
 
@@ -149,10 +207,13 @@ func InitRoot() {
 	root := CreateXWindow(X.RootWin())
 	root.Instance.Listen(xproto.EventMaskSubstructureNotify | xproto.EventMaskPropertyChange)
 	xevent.PropertyNotifyFun(StateUpdate).Connect(X, root.Id)
+	xevent.ClientMessageFun(PingReply).Connect(X, root.Id)
+
+	// Init event loop messages
+	initLoop()
 }
 
 func Connected() bool {
-	var err error
 	var connected bool
 
 	// Retry to connect
@@ -164,50 +225,105 @@ func Connected() bool {
 		}
 
 		// Connect to X server
-		X, err = xgbutil.NewConn()
+		conn, err := xgbutil.NewConn()
 		if err != nil {
 			log.Error("Connection to X server failed: ", err)
 			continue
 		}
 
-		// Check EWMH compliance
-		name, err := ewmh.GetEwmhWM(X)
-		if err != nil {
-			log.Error("Window manager is not EWMH compliant: ", err)
-			continue
+		// Init window system backend
+		var backend Backend = CreateX11Backend(conn)
+		if common.Args.DryRun {
+			backend = CreateDryRunBackend(conn)
 		}
-		WindowManager = &XWindowManager{Name: name}
 
-		// Validate ROOT properties
-		_, err = ewmh.ClientListStackingGet(X)
-		if err != nil {
-			log.Error("Error retrieving ROOT properties: ", err)
+		// Attach to X server
+		if err = attach(conn, backend); err != nil {
+			log.Error("Connection to X server failed: ", err)
 			continue
 		}
 
 		// Connection to X established
 		log.Info("Connected to X server on ", common.Process.Host.Hostname, " [", common.Process.Host.Platform, ", ", WindowManager.Name, "]")
-		randr.Init(X.Conn())
 		connected = true
+
+		// Window operations are not applied
+		if common.Args.DryRun {
+			log.Warn("Running in dry-run mode, window operations are logged only")
+		}
+
+		// Partial support on XWayland
+		if WindowManager.Wayland {
+			log.Warn("Running on XWayland, only X11 windows are managed [", WindowManager.Name, "]")
+			log.Warn("Hot corners and hover focus are disabled, pointer positions are only reported above X11 windows")
+		}
+
+		// Window manager deviations
+		if quirks := ActiveQuirks(); len(quirks) > 0 {
+			log.Info("Apply window manager quirks ", quirks, " [", WindowManager.Name, "]")
+		}
 	}
 
 	return connected
 }
 
-func Compatible(feature string) bool {
-	wm := strings.ToLower(WindowManager.Name)
+func attach(conn *xgbutil.XUtil, backend Backend) error {
+	X, Server = conn, backend
 
-	// Check feature compatibility
-	switch feature {
-	case "icccm.SizeHintPMinSize":
-		return !strings.Contains(wm, "mutter") && !strings.Contains(wm, "muffin")
+	// Check EWMH compliance
+	name, err := Server.GetEwmhWM()
+	if err != nil {
+		return fmt.Errorf("window manager is not EWMH compliant: %s", err)
 	}
+	WindowManager = &XWindowManager{Name: name, Wayland: Wayland(X)}
 
-	return true
+	// Validate ROOT properties
+	_, err = Server.ClientListStackingGet()
+	if err != nil {
+		return fmt.Errorf("error retrieving ROOT properties: %s", err)
+	}
+
+	// Init randr extension
+	randr.Init(X.Conn())
+
+	// Init screensaver extension
+	initIdle()
+
+	return nil
+}
+
+func Wayland(X *xgbutil.XUtil) bool {
+	if common.HasFlag("disable-wayland-detection") {
+		return false
+	}
+
+	// Check XWayland extension
+	extension := "XWAYLAND"
+	reply, err := xproto.QueryExtension(X.Conn(), uint16(len(extension)), extension).Reply()
+	if err == nil && reply.Present {
+		return true
+	}
+
+	// Check XWayland output names (e.g. XWAYLAND0)
+	if err := randr.Init(X.Conn()); err != nil {
+		return false
+	}
+	resources, err := randr.GetScreenResources(X.Conn(), X.RootWin()).Reply()
+	if err != nil {
+		return false
+	}
+	for _, output := range resources.Outputs {
+		oinfo, err := randr.GetOutputInfo(X.Conn(), output, 0).Reply()
+		if err == nil && strings.HasPrefix(string(oinfo.Name), extension) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func NumberOfDesktopsGet(X *xgbutil.XUtil) uint {
-	deskCount, err := ewmh.NumberOfDesktopsGet(X)
+	deskCount, err := Server.NumberOfDesktopsGet()
 
 	// Validate number of desktops
 	if err != nil {
@@ -219,7 +335,7 @@ func NumberOfDesktopsGet(X *xgbutil.XUtil) uint {
 }
 
 func CurrentDesktopGet(X *xgbutil.XUtil) uint {
-	currentDesk, err := ewmh.CurrentDesktopGet(X)
+	currentDesk, err := Server.CurrentDesktopGet()
 
 	// Validate current desktop
 	if err != nil {
@@ -231,13 +347,12 @@ func CurrentDesktopGet(X *xgbutil.XUtil) uint {
 }
 
 func CurrentDesktopSet(X *xgbutil.XUtil, desktop uint) {
-	ewmh.CurrentDesktopSet(X, desktop)
-	ewmh.ClientEvent(X, X.RootWin(), "_NET_CURRENT_DESKTOP", int(desktop), int(0))
+	Server.CurrentDesktopSet(desktop)
 	Workplace.CurrentDesktop = desktop
 }
 
 func ActiveWindowGet(X *xgbutil.XUtil) XWindow {
-	active, err := ewmh.ActiveWindowGet(X)
+	active, err := Server.ActiveWindowGet()
 
 	// Validate active window
 	if err != nil {
@@ -249,13 +364,12 @@ func ActiveWindowGet(X *xgbutil.XUtil) XWindow {
 }
 
 func ActiveWindowSet(X *xgbutil.XUtil, w *XWindow) {
-	ewmh.ActiveWindowSet(X, w.Id)
-	ewmh.ClientEvent(X, w.Id, "_NET_ACTIVE_WINDOW", int(2), int(0), int(0))
+	Server.ActiveWindowSet(w.Id)
 	Windows.Active = *CreateXWindow(w.Id)
 }
 
 func ClientListStackingGet(X *xgbutil.XUtil) []XWindow {
-	clients, err := ewmh.ClientListStackingGet(X)
+	clients, err := Server.ClientListStackingGet()
 
 	// Validate client list
 	if err != nil {
@@ -301,11 +415,14 @@ func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 
 	// Get margins of desktop panels
 	for _, w := range Windows.Stacked {
-		strut, err := ewmh.WmStrutPartialGet(X, w.Id)
+		strut, err := Server.WmStrutPartialGet(w.Id)
 		if err != nil {
 			continue
 		}
 
+		// Attach strut events of desktop panels
+		attachStrutEvents(w.Id)
+
 		// Apply struts to rectangles in place
 		xrect.ApplyStrut(rects, uint(geom.Width()), uint(geom.Height()),
 			strut.Left, strut.Right, strut.Top, strut.Bottom,
@@ -314,6 +431,25 @@ func DisplaysGet(X *xgbutil.XUtil) XDisplays {
 		)
 	}
 
+	// Limit desktop rectangles to workarea
+	if HasQuirk("workarea_margins") {
+		if areas, err := Server.WorkareaGet(); err == nil && len(areas) > 0 {
+
+			// Use workarea of current desktop (e.g. marco and budgie update them per desktop)
+			area := areas[0]
+			if desktop := CurrentDesktopGet(X); int(desktop) < len(areas) {
+				area = areas[desktop]
+			}
+			for i, r := range rects {
+				x0, y0 := common.MaxInt(r.X(), area.X), common.MaxInt(r.Y(), area.Y)
+				x1, y1 := common.MinInt(r.X()+r.Width(), area.X+int(area.Width)), common.MinInt(r.Y()+r.Height(), area.Y+int(area.Height))
+				if x1 > x0 && y1 > y0 {
+					rects[i] = xrect.New(x0, y0, x1-x0, y1-y0)
+				}
+			}
+		}
+	}
+
 	// Update desktop geometry
 	for i := range desktops {
 		desktops[i].Geometry = *common.CreateGeometry(rects[i])
@@ -404,7 +540,48 @@ func PhysicalHeadsGet(X *xgbutil.XUtil) []XHead {
 		return heads[i].Geometry.X < heads[j].Geometry.X
 	})
 
-	return heads
+	// Split output heads into virtual screens
+	return splitHeads(heads)
+}
+
+func splitHeads(heads []XHead) []XHead {
+	splits := []XHead{}
+
+	for i, head := range heads {
+		proportions := []float64{}
+		for ref, ps := range common.Config.ScreenSplits {
+			if ref == head.Name || ref == strconv.Itoa(i) {
+				proportions = ps
+			}
+		}
+		if len(proportions) < 2 {
+			splits = append(splits, head)
+			continue
+		}
+
+		// Divide head width by proportions
+		x := head.Geometry.X
+		for j, p := range proportions {
+			w := int(math.Round(float64(head.Geometry.Width) * p))
+			if j == len(proportions)-1 {
+				w = head.Geometry.X + head.Geometry.Width - x
+			}
+			splits = append(splits, XHead{
+				Id:      head.Id,
+				Name:    fmt.Sprintf("%s:%d", head.Name, j+1),
+				Primary: head.Primary && j == 0,
+				Geometry: common.Geometry{
+					X:      x,
+					Y:      head.Geometry.Y,
+					Width:  w,
+					Height: head.Geometry.Height,
+				},
+			})
+			x += w
+		}
+	}
+
+	return splits
 }
 
 func PointerGet(X *xgbutil.XUtil) *XPointer {
@@ -423,6 +600,8 @@ func PointerGet(X *xgbutil.XUtil) *XPointer {
 			Middle: p.Mask&xproto.ButtonMask2 == xproto.ButtonMask2,
 			Right:  p.Mask&xproto.ButtonMask3 == xproto.ButtonMask3,
 		},
+		Modifiers: p.Mask & (xproto.ModMaskShift | xproto.ModMaskLock | xproto.ModMaskControl |
+			xproto.ModMask1 | xproto.ModMask2 | xproto.ModMask3 | xproto.ModMask4 | xproto.ModMask5),
 		Position: common.Point{
 			X: int(p.RootX),
 			Y: int(p.RootY),
@@ -430,6 +609,55 @@ func PointerGet(X *xgbutil.XUtil) *XPointer {
 	}
 }
 
+func (d XDisplays) ScreenIndex(ref string) (uint, bool) {
+
+	// Resolve screen index
+	if i, err := strconv.Atoi(ref); err == nil {
+		return uint(i), i >= 0 && i < len(d.Screens)
+	}
+
+	// Resolve output name
+	for i, screen := range d.Screens {
+		if screen.Name == ref {
+			return uint(i), true
+		}
+	}
+
+	return 0, false
+}
+
+func ExternalScreen() (uint, bool) {
+	internal, err := regexp.Compile(strings.ToLower(common.Config.Dock.Internal))
+	if err != nil {
+		return 0, false
+	}
+
+	// Obtain first screen not matching internal outputs
+	for i, screen := range Workplace.Displays.Screens {
+		name, _, _ := strings.Cut(screen.Name, ":")
+		if !internal.MatchString(strings.ToLower(name)) {
+			return uint(i), true
+		}
+	}
+
+	return 0, false
+}
+
+func IsManaged(loc Location) bool {
+	screens, desktops := common.Config.TilingScreens, common.Config.TilingDesktops
+
+	// Check managed screens and desktops
+	screen := len(screens) == 0
+	for _, ref := range screens {
+		if i, ok := Workplace.Displays.ScreenIndex(ref); ok && i == loc.Screen {
+			screen = true
+		}
+	}
+	desktop := len(desktops) == 0 || common.IsInIntList(int(loc.Desktop), desktops)
+
+	return screen && desktop
+}
+
 func ScreenGet(p common.Point) uint {
 
 	// Check if point is inside screen rectangle
@@ -452,6 +680,101 @@ func ScreenGeometry(i uint) *common.Geometry {
 	return &screen.Geometry
 }
 
+func ScreenNeighbor(i uint, direction string) (uint, bool) {
+	if int(i) >= len(Workplace.Displays.Screens) {
+		return i, false
+	}
+	source := Workplace.Displays.Screens[i].Geometry.Center()
+
+	// Find nearest screen in direction
+	found := false
+	neighbor, distance := i, math.MaxInt
+	for j, screen := range Workplace.Displays.Screens {
+		target := screen.Geometry.Center()
+		dx, dy := target.X-source.X, target.Y-source.Y
+
+		// Check screen direction
+		valid := false
+		switch direction {
+		case "left":
+			valid = dx < 0 && common.AbsInt(dy) <= common.AbsInt(dx)
+		case "right":
+			valid = dx > 0 && common.AbsInt(dy) <= common.AbsInt(dx)
+		case "up":
+			valid = dy < 0 && common.AbsInt(dx) <= common.AbsInt(dy)
+		case "down":
+			valid = dy > 0 && common.AbsInt(dx) <= common.AbsInt(dy)
+		}
+		if !valid || uint(j) == i {
+			continue
+		}
+
+		// Check screen distance
+		if d := dx*dx + dy*dy; d < distance {
+			neighbor, distance = uint(j), d
+			found = true
+		}
+	}
+
+	return neighbor, found
+}
+
+func FullscreenMonitors(refs []string) (ewmh.WmFullscreenMonitors, bool) {
+	edges := ewmh.WmFullscreenMonitors{}
+
+	// Obtain monitor indices of window manager
+	if err := xinerama.Init(X.Conn()); err != nil {
+		log.Warn("Error initializing xinerama extension: ", err)
+		return edges, false
+	}
+	reply, err := xinerama.QueryScreens(X.Conn()).Reply()
+	if err != nil {
+		log.Warn("Error retrieving xinerama screens: ", err)
+		return edges, false
+	}
+
+	// Obtain referenced or all screens
+	screens := []uint{}
+	for _, ref := range refs {
+		if i, ok := Workplace.Displays.ScreenIndex(ref); ok {
+			screens = append(screens, i)
+		}
+	}
+	if len(refs) == 0 {
+		for i := range Workplace.Displays.Screens {
+			screens = append(screens, uint(i))
+		}
+	}
+
+	// Find monitors on outer edges of screens (virtual screens use their physical monitor)
+	found := 0
+	var top, bottom, left, right int
+	for _, i := range screens {
+		for j, info := range reply.ScreenInfo {
+			geom := common.Geometry{X: int(info.XOrg), Y: int(info.YOrg), Width: int(info.Width), Height: int(info.Height)}
+			if !common.IsInsideRect(ScreenGeometry(i).Center(), geom) {
+				continue
+			}
+			if found == 0 || geom.Y < top {
+				top, edges.Top = geom.Y, uint(j)
+			}
+			if found == 0 || geom.Y+geom.Height > bottom {
+				bottom, edges.Bottom = geom.Y+geom.Height, uint(j)
+			}
+			if found == 0 || geom.X < left {
+				left, edges.Left = geom.X, uint(j)
+			}
+			if found == 0 || geom.X+geom.Width > right {
+				right, edges.Right = geom.X+geom.Width, uint(j)
+			}
+			found++
+			break
+		}
+	}
+
+	return edges, found > 0
+}
+
 func DesktopGeometry(i uint) *common.Geometry {
 	if int(i) >= len(Workplace.Displays.Desktops) {
 		return &common.Geometry{}
@@ -466,6 +789,11 @@ func DesktopGeometry(i uint) *common.Geometry {
 	if desktop.Primary && len(common.Config.EdgeMarginPrimary) > 0 {
 		margin = common.Config.EdgeMarginPrimary
 	}
+	for ref, m := range common.Config.EdgeMarginScreens {
+		if screen, ok := Workplace.Displays.ScreenIndex(ref); ok && screen == i {
+			margin = m
+		}
+	}
 	if len(margin) == 4 {
 		x += margin[3]
 		y += margin[0]
@@ -482,7 +810,7 @@ func DesktopGeometry(i uint) *common.Geometry {
 }
 
 func PointerUpdate(X *xgbutil.XUtil) *XPointer {
-	previous := XPointer{XDrag{}, XButton{}, common.Point{}}
+	previous := XPointer{XDrag{}, XButton{}, 0, common.Point{}}
 	if Pointer != nil {
 		previous = *Pointer
 	}
@@ -527,18 +855,45 @@ func StateUpdate(X *xgbutil.XUtil, e xevent.PropertyNotifyEvent) {
 		log.Warn("Error retrieving atom name: ", err)
 		return
 	}
+	TraceEvent(e.Window, "", "PropertyNotify", aname)
 
 	// Update common state variables
 	if common.IsInList(aname, []string{"_NET_NUMBER_OF_DESKTOPS"}) {
 		Workplace.DesktopCount = NumberOfDesktopsGet(X)
 	} else if common.IsInList(aname, []string{"_NET_CURRENT_DESKTOP"}) {
 		Workplace.CurrentDesktop = CurrentDesktopGet(X)
+
+		// Update workarea of current desktop
+		if HasQuirk("workarea_margins") {
+			Workplace.Displays = DisplaysGet(X)
+		}
 	} else if common.IsInList(aname, []string{"_NET_DESKTOP_LAYOUT", "_NET_DESKTOP_GEOMETRY", "_NET_DESKTOP_VIEWPORT", "_NET_WORKAREA"}) {
 		Workplace.Displays = DisplaysGet(X)
 	} else if common.IsInList(aname, []string{"_NET_CLIENT_LIST_STACKING"}) {
 		Windows.Stacked = ClientListStackingGet(X)
 	} else if common.IsInList(aname, []string{"_NET_ACTIVE_WINDOW"}) {
-		Windows.Active = ActiveWindowGet(X)
+		active := ActiveWindowGet(X)
+		if active.Id != Windows.Active.Id {
+			Windows.Previous = Windows.Active
+		}
+		Windows.Active = active
+	}
+	stateCallbacks(aname, Workplace.CurrentDesktop, Workplace.CurrentScreen)
+}
+
+func StrutUpdate(X *xgbutil.XUtil, e xevent.PropertyNotifyEvent) {
+
+	// Obtain atom name from property event
+	aname, err := xprop.AtomName(X, e.Atom)
+	if err != nil || aname != "_NET_WM_STRUT_PARTIAL" {
+		return
+	}
+
+	// Update desktop dimensions
+	desktops := Workplace.Displays.Desktops
+	Workplace.Displays = DisplaysGet(X)
+	if reflect.DeepEqual(desktops, Workplace.Displays.Desktops) {
+		return
 	}
 	stateCallbacks(aname, Workplace.CurrentDesktop, Workplace.CurrentScreen)
 }
@@ -551,6 +906,24 @@ func OnStateUpdate(fun func(string, uint, uint)) {
 	stateCallbacksFun = append(stateCallbacksFun, fun)
 }
 
+func attachStrutEvents(w xproto.Window) {
+	if strutWindows == nil {
+		strutWindows = make(map[xproto.Window]bool)
+	}
+	if strutWindows[w] {
+		return
+	}
+	strutWindows[w] = true
+
+	// Attach panel property and destroy events
+	CreateXWindow(w).Instance.Listen(xproto.EventMaskStructureNotify | xproto.EventMaskPropertyChange)
+	xevent.PropertyNotifyFun(StrutUpdate).Connect(X, w)
+	xevent.DestroyNotifyFun(func(X *xgbutil.XUtil, ev xevent.DestroyNotifyEvent) {
+		delete(strutWindows, w)
+		xevent.Detach(X, w)
+	}).Connect(X, w)
+}
+
 func pointerCallbacks(pointer XPointer, desktop uint, screen uint) {
 	log.Info("Pointer event ", pointer.Button)
 
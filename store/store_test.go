@@ -0,0 +1,19 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+func useContext(t *testing.T, config common.Configuration) *Context {
+	ctx := CreateContext(nil, nil, config)
+	previous := ctx.Activate()
+
+	// Restore previous context after test
+	t.Cleanup(func() {
+		previous.Activate()
+	})
+
+	return ctx
+}
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+
+	"github.com/leukipp/cortile/v2/common"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type SyncCounter struct {
+	Id       uint32           // Sync counter id of window
+	Value    int64            // Counter value of last sync request
+	Alarm    uint32           // Sync alarm triggered by the requested counter value
+	Client   *Client          // Client of last sync request
+	Pending  *Timer           // Timeout of unacknowledged sync request
+	Next     *common.Geometry // Latest resize deferred until acknowledged
+	Failures int              // Number of unacknowledged resizes
+}
+
+type syncAlarmEvent struct {
+	Sequence uint16 // Sequence number of event
+	Alarm    uint32 // Sync alarm id of event
+	buf      []byte // Raw event data
+}
+
+func (ev syncAlarmEvent) Bytes() []byte {
+	return ev.buf
+}
+
+func (ev syncAlarmEvent) SequenceId() uint16 {
+	return ev.Sequence
+}
+
+func (ev syncAlarmEvent) String() string {
+	return fmt.Sprintf("SyncAlarmNotify {Sequence: %d, Alarm: %d}", ev.Sequence, ev.Alarm)
+}
+
+var (
+	syncOpcode   byte                                                                  // Major opcode of sync extension
+	syncChecked  bool                                                                  // Sync extension was queried
+	syncCounters map[xproto.Window]*SyncCounter = make(map[xproto.Window]*SyncCounter) // Sync counters of windows
+	syncAlarms   map[uint32]xproto.Window       = make(map[uint32]xproto.Window)       // Windows of sync alarms
+	syncMutex    sync.Mutex                                                            // Mutex for sync counters of windows
+)
+
+var (
+	syncFailures int = 3 // Unacknowledged resizes until sync is skipped
+)
+
+func SyncReset(w xproto.Window) {
+	syncMutex.Lock()
+	defer syncMutex.Unlock()
+
+	// Remove counter and destroy alarm
+	counter := syncCounters[w]
+	delete(syncCounters, w)
+	if counter == nil {
+		return
+	}
+	counter.Pending.Stop()
+	if counter.Alarm > 0 {
+		delete(syncAlarms, counter.Alarm)
+		syncRequest(11, counter.Alarm)
+	}
+}
+
+func (c *Client) deferSync(x, y, w, h int) bool {
+	timeout := time.Duration(common.Config.WindowSyncTimeout) * time.Millisecond
+	if timeout <= 0 || !syncSupported() {
+		return false
+	}
+	counter := syncCounter(c.Window.Id)
+	if counter == nil || counter.Failures >= syncFailures {
+		return false
+	}
+
+	// Defer resize until client acknowledged previous resize
+	if counter.Pending.Active() {
+		counter.Next = &common.Geometry{X: x, Y: y, Width: w, Height: h}
+		return true
+	}
+
+	// Skip resizes without size changes
+	geom := c.Latest.Dimensions.Geometry
+	if geom.Width == w && geom.Height == h {
+		return false
+	}
+
+	// Request acknowledgment of the upcoming resize
+	counter.Value += 1
+	counter.Client = c
+	if !c.syncRequest(counter) {
+		return false
+	}
+
+	// Continue without acknowledgment after timeout
+	counter.Pending = AfterFunc(timeout, func() {
+		counter.Failures += 1
+		c.Log().Debug("Sync request timed out [", counter.Failures, "/", syncFailures, "]")
+		syncDone(counter)
+	})
+
+	return false
+}
+
+func (c *Client) syncRequest(counter *SyncCounter) bool {
+	low, high := int(uint32(counter.Value)), int(uint32(counter.Value>>32))
+
+	// Arm alarm on requested counter value
+	if counter.Alarm == 0 {
+		id, err := X.Conn().NewId()
+		if err != nil {
+			return false
+		}
+		counter.Alarm = id
+		syncMutex.Lock()
+		syncAlarms[id] = c.Window.Id
+		syncMutex.Unlock()
+		syncRequest(8, id, 1|2|4|8|32, counter.Id, 0, uint32(high), uint32(low), 2, 1)
+	} else {
+		syncRequest(9, counter.Alarm, 4, uint32(high), uint32(low))
+	}
+
+	// Send sync request message to client
+	if err := Server.SyncWindow(c.Window.Id, counter.Value); err != nil {
+		return false
+	}
+
+	return true
+}
+
+func syncDone(counter *SyncCounter) {
+	counter.Pending.Stop()
+	counter.Pending = nil
+
+	// Apply latest deferred resize
+	if next := counter.Next; next != nil && counter.Client != nil {
+		counter.Next = nil
+		counter.Client.moveresize(next.Pieces())
+	}
+}
+
+func syncCounter(w xproto.Window) *SyncCounter {
+	syncMutex.Lock()
+	defer syncMutex.Unlock()
+
+	// Obtain cached sync counter
+	if counter, ok := syncCounters[w]; ok {
+		return counter
+	}
+
+	// Check sync protocol support of window
+	var counter *SyncCounter
+	protocols, _ := Server.WmProtocolsGet(w)
+	if common.IsInList("_NET_WM_SYNC_REQUEST", protocols) {
+		if ids, err := Server.PropertyNums(w, "_NET_WM_SYNC_REQUEST_COUNTER"); err == nil && len(ids) > 0 {
+			if value, err := syncQueryCounter(uint32(ids[0])); err == nil {
+				counter = &SyncCounter{Id: uint32(ids[0]), Value: value}
+			}
+		}
+	}
+	syncCounters[w] = counter
+
+	return counter
+}
+
+func syncSupported() bool {
+	if syncChecked {
+		return syncOpcode > 0
+	}
+	syncChecked = true
+
+	// Query sync extension
+	reply, err := xproto.QueryExtension(X.Conn(), 4, "SYNC").Reply()
+	if err != nil || !reply.Present {
+		log.Info("Sync extension is not available")
+		return false
+	}
+
+	// Initialize sync extension (version 3.1)
+	buf := make([]byte, 8)
+	buf[0] = reply.MajorOpcode
+	buf[1] = 0
+	xgb.Put16(buf[2:], 2)
+	buf[4], buf[5] = 3, 1
+	cookie := X.Conn().NewCookie(true, true)
+	X.Conn().NewRequest(buf, cookie)
+	if _, err := cookie.Reply(); err != nil {
+		log.Warn("Error initializing sync extension: ", err)
+		return false
+	}
+	syncOpcode = reply.MajorOpcode
+
+	// Decode alarm notify events (second event of extension)
+	xgb.NewEventFuncs[int(reply.FirstEvent)+1] = func(buf []byte) xgb.Event {
+		return syncAlarmEvent{Sequence: xgb.Get16(buf[2:]), Alarm: xgb.Get32(buf[4:]), buf: buf}
+	}
+
+	// Attach alarm notify events
+	xevent.HookFun(func(X *xgbutil.XUtil, ev interface{}) bool {
+		alarm, ok := ev.(syncAlarmEvent)
+		if !ok {
+			return true
+		}
+		syncMutex.Lock()
+		counter := syncCounters[syncAlarms[alarm.Alarm]]
+		syncMutex.Unlock()
+
+		// Continue with acknowledged resize
+		if counter != nil && counter.Pending.Active() {
+			counter.Failures = 0
+			syncDone(counter)
+		}
+
+		return false
+	}).Connect(X)
+
+	return true
+}
+
+func syncRequest(minor byte, values ...uint32) {
+
+	// Send sync request without reply
+	buf := make([]byte, 4+4*len(values))
+	buf[0] = syncOpcode
+	buf[1] = minor
+	xgb.Put16(buf[2:], uint16(len(buf)/4))
+	for i, value := range values {
+		xgb.Put32(buf[4+4*i:], value)
+	}
+	X.Conn().NewRequest(buf, X.Conn().NewCookie(false, false))
+}
+
+func syncQueryCounter(id uint32) (int64, error) {
+
+	// Request counter value
+	buf := make([]byte, 8)
+	buf[0] = syncOpcode
+	buf[1] = 5
+	xgb.Put16(buf[2:], 2)
+	xgb.Put32(buf[4:], id)
+	cookie := X.Conn().NewCookie(true, true)
+	X.Conn().NewRequest(buf, cookie)
+
+	// Read counter value (high and low part)
+	reply, err := cookie.Reply()
+	if err != nil {
+		return 0, err
+	}
+	if len(reply) < 16 {
+		return 0, fmt.Errorf("invalid sync counter reply")
+	}
+
+	return int64(int32(xgb.Get32(reply[8:])))<<32 | int64(xgb.Get32(reply[12:])), nil
+}
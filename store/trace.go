@@ -0,0 +1,80 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"path/filepath"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+type Event struct {
+	Time    time.Time     // Event timestamp
+	Window  xproto.Window // Window id of event source
+	Class   string        // Window class of event source
+	Event   string        // Event name
+	Details string        // Event details or resulting action
+}
+
+var (
+	events      []Event    // Recorded tracker and X events
+	eventsIndex int        // Next write position of recorded events
+	eventsMutex sync.Mutex // Lock for recorded events
+)
+
+var (
+	eventsSize int = 1000 // Maximum number of recorded events
+)
+
+func TraceEvent(w xproto.Window, class string, event string, details string) {
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+
+	// Overwrite oldest event when full
+	e := Event{Time: time.Now(), Window: w, Class: class, Event: event, Details: details}
+	if len(events) < eventsSize {
+		events = append(events, e)
+	} else {
+		events[eventsIndex] = e
+	}
+	eventsIndex = (eventsIndex + 1) % eventsSize
+}
+
+func (c *Client) Trace(event string, details string) {
+	TraceEvent(c.Window.Id, c.Latest.Class, event, details)
+}
+
+func TracedEvents() []Event {
+	eventsMutex.Lock()
+	defer eventsMutex.Unlock()
+
+	// Order events from oldest to newest
+	if len(events) < eventsSize {
+		return append([]Event{}, events...)
+	}
+	return append(append([]Event{}, events[eventsIndex:]...), events[:eventsIndex]...)
+}
+
+func DumpEvents() (string, error) {
+	path := filepath.Join(os.TempDir(), fmt.Sprintf("%s-events-%s.json", common.Build.Name, time.Now().Format("20060102-150405")))
+
+	// Parse recorded events
+	data, err := json.MarshalIndent(TracedEvents(), "", "  ")
+	if err != nil {
+		return path, err
+	}
+
+	// Write recorded events
+	err = os.WriteFile(path, data, 0600)
+	if err != nil {
+		return path, err
+	}
+
+	return path, nil
+}
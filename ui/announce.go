@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	announceLocation *store.Location // Workspace location of last announcement
+)
+
+func AnnounceLayout(ws *desktop.Workspace) {
+	if ws == nil || ws.Location.Desktop != store.Workplace.CurrentDesktop || store.Presenting {
+		return
+	}
+
+	// Announce layout name
+	Announce(layoutPhrase(ws))
+}
+
+func AnnounceWorkspace(ws *desktop.Workspace) {
+	if ws == nil || store.Presenting {
+		return
+	}
+
+	// Announce changed workspace only
+	if announceLocation != nil && *announceLocation == ws.Location {
+		return
+	}
+	initial := announceLocation == nil
+	location := ws.Location
+	announceLocation = &location
+	if initial {
+		return
+	}
+
+	// Announce desktop, screen and layout name
+	text := fmt.Sprintf("Desktop %d", ws.Location.Desktop+1)
+	if store.Workplace.ScreenCount > 1 {
+		text += fmt.Sprintf(", screen %d", ws.Location.Screen+1)
+	}
+	Announce(text + ", " + layoutPhrase(ws))
+}
+
+func Announce(text string) bool {
+	params := strings.Fields(common.Config.GuiAnnounce)
+	if len(params) == 0 || len(text) == 0 {
+		return false
+	}
+
+	log.Debug("Announce \"", text, "\"")
+
+	// Speak text with announce command
+	cmd := exec.Command(params[0], append(params[1:], text)...)
+	if err := cmd.Start(); err != nil {
+		log.Warn("Announcement failed: ", err)
+		return false
+	}
+	go cmd.Wait()
+
+	return true
+}
+
+func layoutPhrase(ws *desktop.Workspace) string {
+	if ws.TilingDisabled() {
+		return "tiling disabled"
+	}
+
+	// Obtain readable layout name and window count
+	name := strings.ReplaceAll(ws.ActiveLayout().GetName(), "-", " ")
+	count := len(ws.VisibleClients())
+	if count == 1 {
+		return fmt.Sprintf("%s layout, 1 window", name)
+	}
+
+	return fmt.Sprintf("%s layout, %d windows", name, count)
+}
@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	buttonMargin int      = 2                                    // Margin of title bar buttons
+	buttonNames  []string = []string{"master", "float", "close"} // Names of title bar buttons
+	buttonLabels []string = []string{"M", "F", "X"}              // Labels of title bar buttons
+)
+
+var (
+	buttons      map[xproto.Window]*Buttons = make(map[xproto.Window]*Buttons) // Title bar buttons per window
+	buttonActive xproto.Window                                                 // Active window of last button update
+	buttonTheme  string                                                        // Theme of last button update
+)
+
+type Buttons struct {
+	Client   *store.Client    // Client the buttons belong to
+	Canvas   *xgraphics.Image // Buttons canvas image
+	Window   *xwindow.Window  // Buttons overlay window
+	Geometry common.Geometry  // Buttons window geometry
+}
+
+func UpdateButtons(tr *desktop.Tracker, click func(c *store.Client, name string)) {
+	visible := map[xproto.Window]*store.Client{}
+
+	// Obtain decorated clients of current desktop
+	if common.Config.WindowButtons {
+		for _, ws := range tr.Workspaces {
+			if ws.Location.Desktop != store.Workplace.CurrentDesktop || ws.TilingDisabled() {
+				continue
+			}
+			if ws.ActiveLayout().GetManager().DecorationDisabled() {
+				continue
+			}
+			for _, c := range ws.VisibleClients() {
+				if c != nil && c.Latest.Dimensions.Extents.Top > 0 {
+					visible[c.Window.Id] = c
+				}
+			}
+		}
+	}
+
+	// Remove buttons of hidden or changed clients
+	theme := fmt.Sprint(common.ActiveTheme())
+	for w, b := range buttons {
+		c, ok := visible[w]
+		if ok && c == b.Client && b.Geometry == buttonGeometry(c) && theme == buttonTheme {
+			continue
+		}
+		b.Canvas.Destroy()
+		b.Window.Destroy()
+		delete(buttons, w)
+	}
+	buttonTheme = theme
+
+	// Create buttons of visible clients
+	for w, c := range visible {
+		if _, ok := buttons[w]; ok {
+			continue
+		}
+		if b := createButtons(c, click); b != nil {
+			buttons[w] = b
+		}
+	}
+
+	// Raise buttons above focused windows
+	if store.Windows.Active.Id != buttonActive {
+		buttonActive = store.Windows.Active.Id
+		for _, b := range buttons {
+			b.Window.Stack(xproto.StackModeAbove)
+		}
+	}
+}
+
+func createButtons(c *store.Client, click func(c *store.Client, name string)) *Buttons {
+	geom := buttonGeometry(c)
+	size := geom.Height
+
+	// Create override redirect window
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		log.Error("Buttons generation failed: ", err)
+		return nil
+	}
+	err = win.CreateChecked(store.X.RootWin(), geom.X, geom.Y, geom.Width, geom.Height,
+		xproto.CwOverrideRedirect|xproto.CwEventMask, 1, xproto.EventMaskButtonPress)
+	if err != nil {
+		log.Error("Buttons creation failed: ", err)
+		return nil
+	}
+	styleWindow(win, geom.Width, geom.Height)
+
+	// Create an empty canvas image
+	bg := bgra("background")
+	cv := xgraphics.New(store.X, image.Rect(0, 0, geom.Width, geom.Height))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw buttons onto canvas
+	font := textFont()
+	color := bgra("client_slave")
+	for i, label := range buttonLabels {
+		x := i * (size + buttonMargin)
+		drawImage(cv, &image.Uniform{color}, color, x, 0, x+size, size)
+
+		// Draw button label
+		if font != nil {
+			s := common.MaxInt(size*2/3, 1)
+			w, _ := xgraphics.Extents(font, float64(s), label)
+			cv.Text(x+size/2-w/2, (size-s)/2, bgra("text"), float64(s), font, label)
+		}
+	}
+
+	// Paint the image and map the window
+	cv.XSurfaceSet(win.Id)
+	cv.XDraw()
+	cv.XPaint(win.Id)
+	win.Map()
+
+	// Attach button events
+	xevent.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+		i := int(ev.EventX) / (size + buttonMargin)
+		if i >= 0 && i < len(buttonNames) {
+			c.Log().Info("Title bar button ", buttonNames[i], " clicked")
+			click(c, buttonNames[i])
+		}
+	}).Connect(store.X, win.Id)
+
+	return &Buttons{
+		Client:   c,
+		Canvas:   cv,
+		Window:   win,
+		Geometry: geom,
+	}
+}
+
+func buttonGeometry(c *store.Client) common.Geometry {
+	geom := c.Latest.Dimensions.Geometry
+	top := c.Latest.Dimensions.Extents.Top
+
+	// Place buttons left of the window manager buttons
+	size := common.MaxInt(top-2*buttonMargin, 8)
+	w := len(buttonNames)*(size+buttonMargin) - buttonMargin
+
+	return common.Geometry{
+		X:      geom.X + geom.Width - w - 4*top,
+		Y:      geom.Y + (top-size)/2,
+		Width:  w,
+		Height: size,
+	}
+}
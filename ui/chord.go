@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"image"
+	"strconv"
+
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+)
+
+var (
+	chordWindow *xwindow.Window   // Key chord overlay window
+	slotWindows []*xwindow.Window // Slot number overlay windows
+)
+
+func ShowChord(ws *desktop.Workspace, lines []string) {
+	if !common.Config.GuiChordOverlay {
+		return
+	}
+	ShowPrompt(ws, lines)
+}
+
+func ShowPrompt(ws *desktop.Workspace, lines []string) {
+	if ws == nil || len(lines) == 0 || store.Presenting {
+		return
+	}
+	CloseChord()
+
+	// Obtain maximum text width
+	font := textFont()
+	if font == nil {
+		return
+	}
+	width := 0
+	for _, line := range lines {
+		w, _ := xgraphics.Extents(font, float64(textSize()), line)
+		width = common.MaxInt(width, w)
+	}
+
+	// Create an empty canvas image
+	bg := bgra("background")
+	lh := textSize() + 2*textPadding()
+	cv := xgraphics.New(store.X, image.Rect(0, 0, width+2*textPadding()+4*rectGap(), len(lines)*lh+2*rectGap()))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw text lines
+	for i, line := range lines {
+		drawText(cv, line, bgra("text"), cv.Rect.Dx()/2, rectGap()+(i+1)*lh-textPadding(), textSize())
+	}
+
+	// Show the canvas graphics
+	chordWindow = showGraphics(cv, ws, 0)
+}
+
+func ShowSlots(ws *desktop.Workspace, clients []*store.Client) {
+	if ws == nil || len(clients) == 0 || store.Presenting {
+		return
+	}
+	CloseChord()
+
+	// Obtain slot label size
+	font := textFont()
+	if font == nil {
+		return
+	}
+	size := 3 * textSize()
+	bg := bgra("background")
+
+	// Draw slot number on each tile
+	for i, c := range clients {
+		if c == nil {
+			continue
+		}
+		cv := xgraphics.New(store.X, image.Rect(0, 0, size, size))
+		cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+		drawText(cv, strconv.Itoa(i+1), bgra("text"), size/2, size/2+textSize(), 2*textSize())
+
+		// Show the canvas graphics in tile center
+		center := c.Latest.Dimensions.Geometry.Center()
+		if win := createGraphics(cv, center.X-size/2, center.Y-size/2); win != nil {
+			slotWindows = append(slotWindows, win)
+		}
+	}
+}
+
+func CloseChord() {
+	closeGraphics(chordWindow)
+	chordWindow = nil
+
+	// Close slot number overlays
+	for _, win := range slotWindows {
+		win.Destroy()
+	}
+	slotWindows = nil
+}
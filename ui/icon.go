@@ -26,6 +26,8 @@ var (
 )
 
 func UpdateIcon(ws *desktop.Workspace) {
+	UpdateIndicator(ws)
+
 	location := store.Location{Desktop: store.Workplace.CurrentDesktop, Screen: store.Workplace.CurrentScreen}
 	if ws == nil || ws.Location != location || len(common.Config.TilingIcon) == 0 {
 		return
@@ -46,33 +48,7 @@ func UpdateIcon(ws *desktop.Workspace) {
 	draw.Draw(icon, icon.Bounds(), &image.Uniform{rgba("icon_background")}, image.Point{}, draw.Src)
 
 	// Draw layout rectangles
-	switch name {
-	case "vertical-left":
-		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
-	case "vertical-right":
-		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y1), &col, image.Point{}, draw.Src)
-	case "horizontal-top":
-		draw.Draw(icon, image.Rect(x0, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x0+(x1-x0)/2-layoutMargin, y1), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
-	case "horizontal-bottom":
-		draw.Draw(icon, image.Rect(x0, y0, x0+(x1-x0)/2-layoutMargin, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+(x1-x0)/2+layoutMargin, y0, x1, y0+(y1-y0)/2-layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/2+layoutMargin, x1, y1), &col, image.Point{}, draw.Src)
-	case "maximized":
-		draw.Draw(icon, image.Rect(x0, y0, x1, y0+(y1-y0)/5-layoutMargin/2), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0+(y1-y0)/5+layoutMargin/2, x1, y1), &col, image.Point{}, draw.Src)
-	case "fullscreen":
-		draw.Draw(icon, image.Rect(x0, y0, x1, y1), &col, image.Point{}, draw.Src)
-	case "disabled":
-		draw.Draw(icon, image.Rect(x0, y0, x0+2*layoutMargin, y1-2*layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0, y0, x1-2*layoutMargin, y0+2*layoutMargin), &col, image.Point{}, draw.Src)
-		draw.Draw(icon, image.Rect(x0+2*layoutMargin+20, y0+2*layoutMargin+20, x1, y1), &col, image.Point{}, draw.Src)
-	}
+	drawLayout(icon, name, x0, y0, x1, y1, layoutMargin, &col)
 
 	// Draw hint rectangle
 	if common.HasUnseenInfos() {
@@ -94,6 +70,38 @@ func UpdateIcon(ws *desktop.Workspace) {
 	systray.SetIcon(data.Bytes())
 }
 
+func drawLayout(img draw.Image, name string, x0 int, y0 int, x1 int, y1 int, margin int, col image.Image) {
+
+	// Draw layout rectangles
+	switch name {
+	case "vertical-left":
+		draw.Draw(img, image.Rect(x0, y0, x0+(x1-x0)/2-margin, y1), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0+(x1-x0)/2+margin, y0, x1, y0+(y1-y0)/2-margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0+(x1-x0)/2+margin, y0+(y1-y0)/2+margin, x1, y1), col, image.Point{}, draw.Src)
+	case "vertical-right":
+		draw.Draw(img, image.Rect(x0, y0, x0+(x1-x0)/2-margin, y0+(y1-y0)/2-margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0, y0+(y1-y0)/2+margin, x0+(x1-x0)/2-margin, y1), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0+(x1-x0)/2+margin, y0, x1, y1), col, image.Point{}, draw.Src)
+	case "horizontal-top":
+		draw.Draw(img, image.Rect(x0, y0, x1, y0+(y1-y0)/2-margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0, y0+(y1-y0)/2+margin, x0+(x1-x0)/2-margin, y1), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0+(x1-x0)/2+margin, y0+(y1-y0)/2+margin, x1, y1), col, image.Point{}, draw.Src)
+	case "horizontal-bottom":
+		draw.Draw(img, image.Rect(x0, y0, x0+(x1-x0)/2-margin, y0+(y1-y0)/2-margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0+(x1-x0)/2+margin, y0, x1, y0+(y1-y0)/2-margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0, y0+(y1-y0)/2+margin, x1, y1), col, image.Point{}, draw.Src)
+	case "maximized":
+		draw.Draw(img, image.Rect(x0, y0, x1, y0+(y1-y0)/5-margin/2), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0, y0+(y1-y0)/5+margin/2, x1, y1), col, image.Point{}, draw.Src)
+	case "fullscreen":
+		draw.Draw(img, image.Rect(x0, y0, x1, y1), col, image.Point{}, draw.Src)
+	case "disabled":
+		draw.Draw(img, image.Rect(x0, y0, x0+2*margin, y1-2*margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0, y0, x1-2*margin, y0+2*margin), col, image.Point{}, draw.Src)
+		draw.Draw(img, image.Rect(x0+2*margin+margin*5/3, y0+2*margin+margin*5/3, x1, y1), col, image.Point{}, draw.Src)
+	}
+}
+
 func HintIcon(active bool) []byte {
 	if !active {
 		return EmptyIcon()
@@ -141,7 +149,7 @@ func rgba(name string) color.RGBA {
 }
 
 func bgra(name string) xgraphics.BGRA {
-	rgba := common.Config.Colors[name]
+	rgba := common.ThemeColor(name)
 
 	// Validate length
 	if len(rgba) != 4 {
@@ -0,0 +1,149 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	indicator       *Indicator                 // Status indicator of active workspace
+	indicatorActive xproto.Window              // Active window of last indicator update
+	indicatorClick  func(button xproto.Button) // Callback of indicator clicks
+)
+
+type Indicator struct {
+	State  string           // Displayed workspace state
+	Canvas *xgraphics.Image // Indicator canvas image
+	Window *xwindow.Window  // Indicator overlay window
+}
+
+func BindIndicator(click func(button xproto.Button)) {
+	indicatorClick = click
+}
+
+func UpdateIndicator(ws *desktop.Workspace) {
+	location := store.Location{Desktop: store.Workplace.CurrentDesktop, Screen: store.Workplace.CurrentScreen}
+	if ws != nil && ws.Location != location {
+		return
+	}
+	corner := common.Config.GuiIndicator
+
+	// Obtain displayed workspace state
+	state := ""
+	if len(corner) > 0 && ws != nil && !store.Presenting {
+		name := ws.ActiveLayout().GetName()
+		if ws.TilingDisabled() {
+			name = "disabled"
+		}
+		masters := ws.ActiveLayout().GetManager().Masters.Maximum
+		state = fmt.Sprintf("%d:%d:%s:%d:%s:%v", ws.Location.Desktop, ws.Location.Screen, name, masters, corner, common.ActiveTheme())
+	}
+
+	// Remove indicator of changed state
+	if indicator != nil && indicator.State != state {
+		indicator.Canvas.Destroy()
+		indicator.Window.Destroy()
+		indicator = nil
+	}
+
+	// Create indicator of current state
+	if indicator == nil && len(state) > 0 {
+		indicator = createIndicator(ws, state)
+	}
+
+	// Raise indicator above focused windows
+	if indicator != nil && store.Windows.Active.Id != indicatorActive {
+		indicatorActive = store.Windows.Active.Id
+		indicator.Window.Stack(xproto.StackModeAbove)
+	}
+}
+
+func createIndicator(ws *desktop.Workspace, state string) *Indicator {
+	font := textFont()
+	if font == nil {
+		return nil
+	}
+	size := textSize()
+	h := size + 2*textPadding()
+
+	// Obtain layout name and texts
+	name := ws.ActiveLayout().GetName()
+	if ws.TilingDisabled() {
+		name = "disabled"
+	}
+	desk := fmt.Sprint(ws.Location.Desktop + 1)
+	masters := fmt.Sprintf("M%d", ws.ActiveLayout().GetManager().Masters.Maximum)
+	dw, _ := xgraphics.Extents(font, float64(size), desk)
+	mw, _ := xgraphics.Extents(font, float64(size), masters)
+	w := dw + h + mw + 4*textPadding()
+
+	// Calculate corner position
+	dim := store.ScreenGeometry(ws.Location.Screen)
+	x, y := dim.X, dim.Y
+	switch common.Config.GuiIndicator {
+	case "top_right":
+		x = dim.X + dim.Width - w
+	case "bottom_right":
+		x, y = dim.X+dim.Width-w, dim.Y+dim.Height-h
+	case "bottom_left":
+		y = dim.Y + dim.Height - h
+	}
+
+	// Create override redirect window
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		log.Error("Indicator generation failed: ", err)
+		return nil
+	}
+	err = win.CreateChecked(store.X.RootWin(), x, y, w, h,
+		xproto.CwOverrideRedirect|xproto.CwEventMask, 1, xproto.EventMaskButtonPress)
+	if err != nil {
+		log.Error("Indicator creation failed: ", err)
+		return nil
+	}
+	styleWindow(win, w, h)
+
+	// Create an empty canvas image
+	bg := bgra("background")
+	cv := xgraphics.New(store.X, image.Rect(0, 0, w, h))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw desktop number, layout glyph and master count
+	col := bgra("text")
+	cv.Text(textPadding(), textPadding(), col, float64(size), font, desk)
+	gx := dw + 2*textPadding()
+	drawLayout(cv, name, gx+textPadding(), 2*textPadding(), gx+h-textPadding(), h-2*textPadding(), 1, &image.Uniform{bgra("client_master")})
+	cv.Text(gx+h+textPadding(), textPadding(), col, float64(size), font, masters)
+
+	// Paint the image and map the window
+	cv.XSurfaceSet(win.Id)
+	cv.XDraw()
+	cv.XPaint(win.Id)
+	win.Map()
+
+	// Attach click events
+	xevent.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+		if indicatorClick != nil {
+			indicatorClick(ev.Detail)
+		}
+	}).Connect(store.X, win.Id)
+
+	return &Indicator{
+		State:  state,
+		Canvas: cv,
+		Window: win,
+	}
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"image"
+	"time"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/mousebind"
+	"github.com/jezek/xgbutil/xcursor"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	inspectDuration time.Duration = 15000            // Duration of inspection overlay in milliseconds
+	pickTimeout     time.Duration = 10 * time.Second // Timeout of window picker without click
+)
+
+var (
+	picker      func(w xproto.Window) // Callback of active window picker
+	pickerBound bool                  // Picker button events are attached
+	pickerTimer *store.Timer          // Timer to release the pointer without click
+	inspector   *xwindow.Window       // Inspection overlay window
+)
+
+func PickWindow(pick func(w xproto.Window)) bool {
+	if picker != nil {
+		return false
+	}
+
+	// Grab pointer with crosshair cursor
+	bindPicker()
+	cursor, err := xcursor.CreateCursor(store.X, xcursor.Crosshair)
+	if err != nil {
+		log.Warn("Error creating cursor: ", err)
+		return false
+	}
+	ok, err := mousebind.GrabPointer(store.X, store.X.RootWin(), 0, cursor)
+	xproto.FreeCursor(store.X.Conn(), cursor)
+	if !ok || err != nil {
+		log.Warn("Error grabbing pointer: ", err)
+		return false
+	}
+	picker = pick
+
+	// Release pointer without click
+	pickerTimer = store.AfterFunc(pickTimeout, func() {
+		picker = nil
+		mousebind.UngrabPointer(store.X)
+	})
+
+	return true
+}
+
+func ShowInspection(ws *desktop.Workspace, lines []string) {
+	if ws == nil || len(lines) == 0 {
+		return
+	}
+	closeGraphics(inspector)
+
+	// Obtain maximum text width
+	font := textFont()
+	if font == nil {
+		return
+	}
+	width := 0
+	for _, line := range lines {
+		w, _ := xgraphics.Extents(font, float64(textSize()), line)
+		width = common.MaxInt(width, w)
+	}
+
+	// Create an empty canvas image
+	bg := bgra("background")
+	lh := textSize() + 2*textPadding()
+	cv := xgraphics.New(store.X, image.Rect(0, 0, width+4*textPadding()+2*rectGap(), len(lines)*lh+2*rectGap()))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw left aligned text lines
+	for i, line := range lines {
+		cv.Text(rectGap()+2*textPadding(), rectGap()+i*lh+textPadding(), bgra("text"), float64(textSize()), font, line)
+	}
+
+	// Show the canvas graphics
+	inspector = showGraphics(cv, ws, inspectDuration)
+}
+
+func bindPicker() {
+	if pickerBound {
+		return
+	}
+	pickerBound = true
+
+	// Pick window below pointer on click
+	xevent.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+		if picker == nil {
+			return
+		}
+		pick := picker
+		picker = nil
+		pickerTimer.Stop()
+		mousebind.UngrabPointer(store.X)
+
+		// Cancel picker on right click or empty root
+		if ev.Detail != xproto.ButtonIndex1 || ev.Child == 0 {
+			return
+		}
+		if w, ok := store.ClientWindow(ev.Child); ok {
+			pick(w)
+		}
+	}).Connect(store.X, store.X.RootWin())
+}
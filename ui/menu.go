@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/keybind"
+	"github.com/jezek/xgbutil/mousebind"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	menu      *Menu // Active window menu
+	menuBound bool  // Menu key events are attached
+)
+
+type Menu struct {
+	Entries []MenuEntry         // Menu entries in display order
+	Index   int                 // Selected entry index
+	Choose  func(action string) // Callback of chosen entry
+	Canvas  *xgraphics.Image    // Menu canvas image
+	Window  *xwindow.Window     // Menu overlay window
+}
+
+type MenuEntry struct {
+	Action string // Action string executed on selection
+	Label  string // Text shown in the menu
+}
+
+func ShowMenu(c *store.Client, entries []MenuEntry, choose func(action string)) bool {
+	if c == nil || len(entries) == 0 {
+		return false
+	}
+	CloseMenu()
+
+	// Obtain maximum text width
+	font := textFont()
+	if font == nil {
+		return false
+	}
+	width := 0
+	for _, entry := range entries {
+		w, _ := xgraphics.Extents(font, float64(textSize()), entry.Label)
+		width = common.MaxInt(width, w)
+	}
+	lh := textSize() + 2*textPadding()
+	w, h := width+4*textPadding()+2*rectGap(), len(entries)*lh+2*rectGap()
+
+	// Calculate position next to client within screen
+	dim := store.ScreenGeometry(c.Latest.Location.Screen)
+	w, h = common.MinInt(w, dim.Width), common.MinInt(h, dim.Height)
+	cx, cy, _, _ := c.OuterGeometry()
+	x := common.MaxInt(dim.X, common.MinInt(cx+rectGap(), dim.X+dim.Width-w))
+	y := common.MaxInt(dim.Y, common.MinInt(cy+rectGap(), dim.Y+dim.Height-h))
+
+	// Create override redirect window
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		log.Error("Menu generation failed: ", err)
+		return false
+	}
+	err = win.CreateChecked(store.X.RootWin(), x, y, w, h,
+		xproto.CwOverrideRedirect|xproto.CwEventMask, 1, xproto.EventMaskButtonPress|xproto.EventMaskPointerMotion)
+	if err != nil {
+		log.Error("Menu creation failed: ", err)
+		return false
+	}
+	styleWindow(win, w, h)
+
+	// Grab keyboard until menu is closed
+	bindMenu()
+	if err := keybind.GrabKeyboard(store.X, store.X.RootWin()); err != nil {
+		log.Warn("Error grabbing keyboard: ", err)
+		win.Destroy()
+		return false
+	}
+
+	// Grab pointer to close menu on outside clicks
+	xproto.GrabPointer(store.X.Conn(), true, store.X.RootWin(), uint16(xproto.EventMaskButtonPress),
+		xproto.GrabModeAsync, xproto.GrabModeAsync, 0, 0, xproto.TimeCurrentTime)
+
+	menu = &Menu{
+		Entries: entries,
+		Choose:  choose,
+		Canvas:  xgraphics.New(store.X, image.Rect(0, 0, w, h)),
+		Window:  win,
+	}
+
+	// Select entries with the pointer
+	xevent.MotionNotifyFun(func(X *xgbutil.XUtil, ev xevent.MotionNotifyEvent) {
+		selectMenu(menuIndex(int(ev.EventY)))
+	}).Connect(store.X, win.Id)
+	xevent.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+		if selectMenu(menuIndex(int(ev.EventY))) {
+			chooseMenu()
+		}
+	}).Connect(store.X, win.Id)
+
+	// Paint the image and map the window
+	menu.Canvas.XSurfaceSet(win.Id)
+	drawMenu()
+	win.Map()
+
+	return true
+}
+
+func CloseMenu() {
+	if menu == nil {
+		return
+	}
+
+	// Release keyboard and pointer and close window
+	keybind.UngrabKeyboard(store.X)
+	mousebind.UngrabPointer(store.X)
+	xevent.Detach(store.X, menu.Window.Id)
+	menu.Canvas.Destroy()
+	menu.Window.Destroy()
+	menu = nil
+}
+
+func drawMenu() {
+	if menu == nil {
+		return
+	}
+	cv := menu.Canvas
+	font := textFont()
+	lh := textSize() + 2*textPadding()
+
+	// Draw background onto canvas
+	bg := bgra("background")
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw entries onto canvas
+	for i, entry := range menu.Entries {
+		y := rectGap() + i*lh
+		if i == menu.Index {
+			color := bgra("client_master")
+			drawImage(cv, &image.Uniform{color}, color, rectGap(), y, cv.Rect.Dx()-rectGap(), y+lh)
+		}
+		cv.Text(rectGap()+2*textPadding(), y+textPadding(), bgra("text"), float64(textSize()), font, entry.Label)
+	}
+
+	// Update canvas
+	cv.XDraw()
+	cv.XPaint(menu.Window.Id)
+}
+
+func bindMenu() {
+	if menuBound {
+		return
+	}
+	menuBound = true
+
+	// Navigate menu with keyboard
+	xevent.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+		if menu == nil {
+			return
+		}
+		n := len(menu.Entries)
+		switch keybind.LookupString(X, ev.State, ev.Detail) {
+		case "Up", "k":
+			selectMenu((menu.Index - 1 + n) % n)
+		case "Down", "j", "Tab":
+			selectMenu((menu.Index + 1) % n)
+		case "Home":
+			selectMenu(0)
+		case "End":
+			selectMenu(n - 1)
+		case "Return", "KP_Enter", "space":
+			chooseMenu()
+		case "Escape":
+			CloseMenu()
+		}
+	}).Connect(store.X, store.X.RootWin())
+
+	// Close menu on outside clicks
+	xevent.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+		CloseMenu()
+	}).Connect(store.X, store.X.RootWin())
+}
+
+func menuIndex(y int) int {
+	return (y - rectGap()) / (textSize() + 2*textPadding())
+}
+
+func selectMenu(index int) bool {
+	if menu == nil || index < 0 || index >= len(menu.Entries) {
+		return false
+	}
+
+	// Redraw changed selection
+	if index != menu.Index {
+		menu.Index = index
+		drawMenu()
+	}
+
+	return true
+}
+
+func chooseMenu() {
+	if menu == nil {
+		return
+	}
+	entry, choose := menu.Entries[menu.Index], menu.Choose
+
+	// Close menu and execute entry
+	CloseMenu()
+	choose(entry.Action)
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"image"
 	"math"
+	"os"
 	"time"
 
 	"image/draw"
@@ -11,6 +12,9 @@ import (
 
 	"github.com/BurntSushi/freetype-go/freetype/truetype"
 
+	"github.com/jezek/xgb/shape"
+	"github.com/jezek/xgb/xproto"
+
 	"github.com/jezek/xgbutil/ewmh"
 	"github.com/jezek/xgbutil/icccm"
 	"github.com/jezek/xgbutil/motif"
@@ -27,17 +31,21 @@ import (
 
 var (
 	fontSize   int = 16 // Size of text font
-	fontMargin int = 4  // Margin of text font
 	rectMargin int = 4  // Margin of layout rectangles
 )
 
 var (
-	gui map[uint]*xwindow.Window = make(map[uint]*xwindow.Window) // Overlay window
+	gui      map[uint]*xwindow.Window = make(map[uint]*xwindow.Window) // Overlay window
+	fontData *truetype.Font                                            // Parsed text font
+	fontPath string                                                    // Parsed text font path
 )
 
 func ShowLayout(ws *desktop.Workspace) {
+	AnnounceLayout(ws)
+	UpdateIndicator(ws)
+
 	location := store.Location{Desktop: store.Workplace.CurrentDesktop}
-	if ws == nil || ws.Location.Desktop != location.Desktop || common.Config.TilingGui <= 0 {
+	if ws == nil || ws.Location.Desktop != location.Desktop || common.Config.TilingGui <= 0 || store.Presenting || store.IsIdle() {
 		return
 	}
 
@@ -55,15 +63,15 @@ func ShowLayout(ws *desktop.Workspace) {
 		_, _, w, h := scale(dim.X, dim.Y, dim.Width, dim.Height)
 
 		// Create an empty canvas image
-		bg := bgra("gui_background")
-		cv := xgraphics.New(store.X, image.Rect(0, 0, w+rectMargin, h+fontSize+2*fontMargin+2*rectMargin))
+		bg := bgra("background")
+		cv := xgraphics.New(store.X, image.Rect(0, 0, w+rectGap(), h+textSize()+2*textPadding()+2*rectGap()))
 		cv.For(func(x int, y int) xgraphics.BGRA { return bg })
 
 		// Draw client rectangles
 		drawClients(cv, ws, name)
 
 		// Draw layout name
-		drawText(cv, name, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin, fontSize)
+		drawText(cv, name, bgra("text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*textPadding()-rectGap(), textSize())
 
 		// Show the canvas graphics
 		showGraphics(cv, ws, time.Duration(common.Config.TilingGui))
@@ -83,8 +91,8 @@ func drawClients(cv *xgraphics.Image, ws *desktop.Workspace, layout string) {
 		x, y, w, h := scale(0, 0, dim.Width, dim.Height)
 
 		// Draw client rectangle onto canvas
-		color := bgra("gui_client_slave")
-		drawImage(cv, &image.Uniform{color}, color, x+rectMargin, y+rectMargin, x+w, y+h)
+		color := bgra("client_slave")
+		drawImage(cv, &image.Uniform{color}, color, x+rectGap(), y+rectGap(), x+w, y+h)
 
 		return
 	}
@@ -110,18 +118,24 @@ func drawClients(cv *xgraphics.Image, ws *desktop.Workspace, layout string) {
 		iconSize /= 2
 
 		// Obtain rectangle color
-		color := bgra("gui_client_slave")
+		color := bgra("client_slave")
 		if mg.IsMaster(c) || common.IsInList(layout, []string{"maximized", "fullscreen"}) {
-			color = bgra("gui_client_master")
+			color = bgra("client_master")
 		}
 
 		// Draw client rectangle onto canvas
-		drawImage(cv, &image.Uniform{color}, color, x+rectMargin, y+rectMargin, x+w, y+h)
+		drawImage(cv, &image.Uniform{color}, color, x+rectGap(), y+rectGap(), x+w, y+h)
 
 		// Draw client icon onto canvas
-		ico, err := xgraphics.FindIcon(store.X, c.Window.Id, iconSize, iconSize)
+		ico, err := store.IconGet(c.Window.Id, iconSize)
 		if err == nil {
-			drawImage(cv, ico, color, x+rectMargin/2+w/2-iconSize/2, y+rectMargin/2+h/2-iconSize/2, x+w, y+h)
+			drawImage(cv, ico, color, x+rectGap()/2+w/2-iconSize/2, y+rectGap()/2+h/2-iconSize/2, x+w, y+h)
+		}
+
+		// Draw fixed size marker onto canvas
+		if c.Fixed > 0 {
+			marker := bgra("text")
+			drawImage(cv, &image.Uniform{marker}, marker, x+2*rectGap(), y+2*rectGap(), x+4*rectGap(), y+4*rectGap())
 		}
 	}
 }
@@ -136,15 +150,14 @@ func drawImage(cv *xgraphics.Image, img image.Image, color xgraphics.BGRA, x0 in
 }
 
 func drawText(cv *xgraphics.Image, txt string, color xgraphics.BGRA, x int, y int, size int) {
-	font, err := truetype.Parse(goregular.TTF)
-	if err != nil {
-		log.Error("Parsing font failed: ", err)
+	font := textFont()
+	if font == nil {
 		return
 	}
 
 	// Obtain maximum font size
 	w, _ := xgraphics.Extents(font, float64(size), txt)
-	if w > 2*(x-fontMargin-rectMargin) {
+	if w > 2*(x-textPadding()-rectGap()) && size > 1 {
 		drawText(cv, txt, color, x, y, size-1)
 		return
 	}
@@ -153,20 +166,95 @@ func drawText(cv *xgraphics.Image, txt string, color xgraphics.BGRA, x int, y in
 	cv.Text(x-w/2, y-size, color, float64(size), font, txt)
 }
 
+func textFont() *truetype.Font {
+	path := common.ActiveTheme().FontPath
+	if fontData != nil && fontPath == path {
+		return fontData
+	}
+
+	// Read custom font file
+	data := goregular.TTF
+	if len(path) > 0 {
+		custom, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn("Reading font failed: ", err)
+		} else {
+			data = custom
+		}
+	}
+
+	// Parse font data
+	parsed, err := truetype.Parse(data)
+	if err != nil {
+		log.Warn("Parsing font failed: ", err)
+		parsed, err = truetype.Parse(goregular.TTF)
+		if err != nil {
+			log.Error("Parsing font failed: ", err)
+			return nil
+		}
+	}
+
+	// Cache parsed font
+	fontData, fontPath = parsed, path
+
+	return fontData
+}
+
+func textSize() int {
+	if size := common.ActiveTheme().FontSize; size > 0 {
+		return size
+	}
+	return fontSize
+}
+
+func textPadding() int {
+	return common.ActiveTheme().Padding
+}
+
+func rectGap() int {
+	if common.Config.GuiLargePrint {
+		return int(float64(rectMargin) * common.LargePrintScale)
+	}
+	return rectMargin
+}
+
 func showGraphics(img *xgraphics.Image, ws *desktop.Workspace, duration time.Duration) *xwindow.Window {
+
+	// Calculate window position
+	dim := dimensions(ws)
+	x, y := dim.X+dim.Width/2-img.Rect.Dx()/2, dim.Y+dim.Height/2-img.Rect.Dy()/2
+
+	// Create the graphics window
+	win := createGraphics(img, x, y)
+	if win == nil {
+		return nil
+	}
+
+	// Close previous opened window
+	if v, ok := gui[ws.Location.Screen]; ok {
+		v.Destroy()
+	}
+	gui[ws.Location.Screen] = win
+
+	// Close window after given duration
+	if duration > 0 {
+		time.AfterFunc(duration*time.Millisecond, win.Destroy)
+	}
+
+	return win
+}
+
+func createGraphics(img *xgraphics.Image, x int, y int) *xwindow.Window {
 	win, err := xwindow.Generate(img.X)
 	if err != nil {
 		log.Error("Graphics generation failed: ", err)
 		return nil
 	}
-
-	// Calculate window dimensions
-	dim := dimensions(ws)
 	w, h := img.Rect.Dx(), img.Rect.Dy()
-	x, y := dim.X+dim.Width/2-w/2, dim.Y+dim.Height/2-h/2
 
 	// Create the graphics window
 	win.Create(img.X.RootWin(), x, y, w, h, 0)
+	styleWindow(win, w, h)
 
 	// Set class and name
 	icccm.WmClassSet(win.X, win.Id, &icccm.WmClass{
@@ -218,18 +306,51 @@ func showGraphics(img *xgraphics.Image, ws *desktop.Workspace, duration time.Dur
 	// Move focus to active window
 	store.ActiveWindowSet(store.X, &store.Windows.Active)
 
-	// Close previous opened window
-	if v, ok := gui[ws.Location.Screen]; ok {
-		v.Destroy()
+	return win
+}
+
+func styleWindow(win *xwindow.Window, w int, h int) {
+	style := common.ActiveTheme()
+
+	// Set window opacity
+	if style.Opacity < 1 {
+		ewmh.WmWindowOpacitySet(win.X, win.Id, style.Opacity)
 	}
-	gui[ws.Location.Screen] = win
 
-	// Close window after given duration
-	if duration > 0 {
-		time.AfterFunc(duration*time.Millisecond, win.Destroy)
+	// Obtain corner radius
+	r := common.MinInt(style.Radius, common.MinInt(w, h)/2)
+	if r <= 0 {
+		return
+	}
+	if err := shape.Init(win.X.Conn()); err != nil {
+		log.Warn("Shape extension failed: ", err)
+		return
 	}
 
-	return win
+	// Round window corners
+	rects := []xproto.Rectangle{{X: 0, Y: int16(r), Width: uint16(w), Height: uint16(h - 2*r)}}
+	for i := 0; i < r; i++ {
+		dy := float64(r-i) - 0.5
+		dx := r - int(math.Round(math.Sqrt(float64(r*r)-dy*dy)))
+		rects = append(rects,
+			xproto.Rectangle{X: int16(dx), Y: int16(i), Width: uint16(w - 2*dx), Height: 1},
+			xproto.Rectangle{X: int16(dx), Y: int16(h - 1 - i), Width: uint16(w - 2*dx), Height: 1})
+	}
+	shape.Rectangles(win.X.Conn(), shape.SoSet, shape.SkBounding, xproto.ClipOrderingUnsorted, win.Id, 0, 0, rects)
+}
+
+func closeGraphics(win *xwindow.Window) {
+	if win == nil {
+		return
+	}
+
+	// Remove and destroy overlay window
+	for screen, w := range gui {
+		if w == win {
+			delete(gui, screen)
+		}
+	}
+	win.Destroy()
 }
 
 func dimensions(ws *desktop.Workspace) *common.Geometry {
@@ -244,10 +365,15 @@ func dimensions(ws *desktop.Workspace) *common.Geometry {
 }
 
 func scale(x, y, w, h int) (sx, sy, sw, sh int) {
-	s := 10
+	s := 10.0
+
+	// Enlarge dimensions in large print mode
+	if common.Config.GuiLargePrint {
+		s /= common.LargePrintScale
+	}
 
 	// Rescale dimensions by factor s
-	sx, sy, sw, sh = x/s, y/s, w/s, h/s
+	sx, sy, sw, sh = int(float64(x)/s), int(float64(y)/s), int(float64(w)/s), int(float64(h)/s)
 
 	return
 }
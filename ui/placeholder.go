@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"image"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jezek/xgbutil/ewmh"
+	"github.com/jezek/xgbutil/icccm"
+	"github.com/jezek/xgbutil/motif"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	placeholderDots  int           = 8                      // Number of spinner dots
+	placeholderDelay time.Duration = 250 * time.Millisecond // Delay until placeholder window is managed
+	placeholderFrame time.Duration = 125 * time.Millisecond // Duration of spinner animation frames
+)
+
+func ShowPlaceholder(tr *desktop.Tracker, a *desktop.Autostart) bool {
+	if !common.Config.WindowPlaceholder || a == nil || store.Presenting {
+		return false
+	}
+	ws := tr.WorkspaceAt(a.Location.Desktop, a.Location.Screen)
+	if ws == nil || ws.TilingDisabled() {
+		return false
+	}
+
+	// Create placeholder window
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		log.Error("Placeholder generation failed: ", err)
+		return false
+	}
+	dim := store.DesktopGeometry(a.Location.Screen)
+	win.Create(store.X.RootWin(), dim.X, dim.Y, dim.Width/2, dim.Height/2, 0)
+
+	// Set class, name and desktop
+	label := placeholderLabel(a)
+	icccm.WmClassSet(win.X, win.Id, &icccm.WmClass{
+		Instance: common.Build.Name,
+		Class:    common.Build.Name,
+	})
+	icccm.WmNameSet(win.X, win.Id, label)
+	ewmh.WmDesktopSet(win.X, win.Id, a.Location.Desktop)
+
+	// Set states and hints for undecorated windows
+	ewmh.WmStateSet(win.X, win.Id, []string{
+		"_NET_WM_STATE_SKIP_TASKBAR",
+		"_NET_WM_STATE_SKIP_PAGER",
+	})
+	motif.WmHintsSet(win.X, win.Id, &motif.Hints{
+		Flags:      motif.HintFunctions | motif.HintDecorations,
+		Function:   motif.FunctionNone,
+		Decoration: motif.DecorationNone,
+	})
+	win.Map()
+
+	// Reserve tile after window is managed
+	store.AfterFunc(placeholderDelay, func() {
+		if !tr.AddPlaceholder(a, store.CreateClient(win.Id)) {
+			win.Destroy()
+			return
+		}
+		store.AfterFunc(placeholderFrame, func() {
+			animatePlaceholder(tr, a, win, nil, label, 0)
+		})
+	})
+
+	return true
+}
+
+func animatePlaceholder(tr *desktop.Tracker, a *desktop.Autostart, win *xwindow.Window, cv *xgraphics.Image, label string, frame int) {
+
+	// Release placeholder of swapped or expired clients
+	if a.Expired() {
+		tr.ReleasePlaceholder(a)
+	}
+	geom, err := win.Geometry()
+	if a.Placeholder == nil || err != nil {
+		if cv != nil {
+			cv.Destroy()
+		}
+		win.Destroy()
+		return
+	}
+
+	// Recreate canvas on size changes
+	w, h := geom.Width(), geom.Height()
+	if cv == nil || cv.Rect.Dx() != w || cv.Rect.Dy() != h {
+		if cv != nil {
+			cv.Destroy()
+		}
+		cv = xgraphics.New(store.X, image.Rect(0, 0, w, h))
+		cv.XSurfaceSet(win.Id)
+	}
+
+	// Draw placeholder frame
+	drawPlaceholder(cv, label, frame)
+	cv.XDraw()
+	cv.XPaint(win.Id)
+
+	// Draw next frame on event loop
+	store.AfterFunc(placeholderFrame, func() {
+		animatePlaceholder(tr, a, win, cv, label, frame+1)
+	})
+}
+
+func drawPlaceholder(cv *xgraphics.Image, label string, frame int) {
+	w, h := cv.Rect.Dx(), cv.Rect.Dy()
+
+	// Draw background and client rectangle
+	bg := bgra("background")
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+	color := bgra("client_slave")
+	drawImage(cv, &image.Uniform{color}, color, rectGap(), rectGap(), w-rectGap(), h-rectGap())
+
+	// Draw spinner dots around center
+	r := common.MinInt(w, h) / 8
+	s := common.MaxInt(r/4, 2)
+	for i := 0; i < placeholderDots; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(placeholderDots)
+		x := w/2 + int(float64(r)*math.Cos(angle))
+		y := h/2 + int(float64(r)*math.Sin(angle))
+		dot := bgra("client_master")
+		if i == frame%placeholderDots {
+			dot = bgra("text")
+		}
+		drawImage(cv, &image.Uniform{dot}, dot, x-s/2, y-s/2, x+s/2, y+s/2)
+	}
+
+	// Draw application label
+	drawText(cv, label, bgra("text"), w/2, h/2+r+2*s+textSize(), textSize())
+}
+
+func placeholderLabel(a *desktop.Autostart) string {
+	if len(a.Class) > 0 {
+		return a.Class
+	}
+
+	// Obtain program name of command
+	fields := strings.Fields(a.Command)
+	if len(fields) == 0 {
+		return common.Build.Name
+	}
+
+	return filepath.Base(fields[0])
+}
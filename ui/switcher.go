@@ -0,0 +1,219 @@
+package ui
+
+import (
+	"image"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/keybind"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	switcherSize   int = 48 // Size of switcher icons
+	switcherMargin int = 8  // Margin of switcher icons
+)
+
+var (
+	switcher      *Switcher // Active window switcher
+	switcherBound bool      // Switcher key events are attached
+)
+
+type Switcher struct {
+	Clients []*store.Client  // Clients in most recently used order
+	Index   int              // Selected client index
+	Canvas  *xgraphics.Image // Switcher canvas image
+	Window  *xwindow.Window  // Switcher overlay window
+}
+
+func ShowSwitcher(tr *desktop.Tracker, ws *desktop.Workspace, step int) bool {
+	if store.Presenting {
+		return false
+	}
+
+	// Cycle active switcher
+	if switcher != nil {
+		n := len(switcher.Clients)
+		switcher.Index = ((switcher.Index+step)%n + n) % n
+		drawSwitcher()
+		return true
+	}
+
+	// Obtain switcher clients
+	clients := switcherClients(tr, ws)
+	if len(clients) < 2 {
+		return false
+	}
+	index := ((step % len(clients)) + len(clients)) % len(clients)
+
+	// Activate client directly without held modifier
+	if !modifierHeld() {
+		activateClient(clients[index])
+		return true
+	}
+
+	// Grab keyboard until modifier is released
+	bindSwitcher()
+	if err := keybind.GrabKeyboard(store.X, store.X.RootWin()); err != nil {
+		log.Warn("Error grabbing keyboard: ", err)
+		activateClient(clients[index])
+		return true
+	}
+
+	// Limit clients to screen width
+	dim := dimensions(ws)
+	if limit := (dim.Width - rectGap()) / (switcherSize + 2*switcherMargin + rectGap()); len(clients) > limit && limit > 1 {
+		clients = clients[:limit]
+		index = common.MinInt(index, limit-1)
+	}
+
+	// Create an empty canvas image
+	w := len(clients)*(switcherSize+2*switcherMargin+rectGap()) + rectGap()
+	h := switcherSize + 2*switcherMargin + textSize() + 2*textPadding() + 2*rectGap()
+	switcher = &Switcher{
+		Clients: clients,
+		Index:   index,
+		Canvas:  xgraphics.New(store.X, image.Rect(0, 0, w, h)),
+	}
+
+	// Show the canvas graphics
+	drawSwitcher()
+	switcher.Window = showGraphics(switcher.Canvas, ws, 0)
+
+	return true
+}
+
+func switcherClients(tr *desktop.Tracker, ws *desktop.Workspace) []*store.Client {
+	if common.Config.GuiSwitcherGlobal {
+		ws = nil
+	}
+
+	// Obtain clients in most recently used order
+	clients := tr.FocusHistory(ws)
+
+	// Append clients without focus history
+	for _, c := range tr.Clients {
+		if ws != nil && tr.ClientWorkspace(c) != ws {
+			continue
+		}
+		found := false
+		for _, h := range clients {
+			found = found || h == c
+		}
+		if !found {
+			clients = append(clients, c)
+		}
+	}
+
+	return clients
+}
+
+func drawSwitcher() {
+	if switcher == nil {
+		return
+	}
+	cv := switcher.Canvas
+
+	// Draw background onto canvas
+	bg := bgra("background")
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw client icons onto canvas
+	for i, c := range switcher.Clients {
+		x := rectGap() + i*(switcherSize+2*switcherMargin+rectGap())
+		y := rectGap()
+
+		// Obtain rectangle color
+		color := bgra("client_slave")
+		if i == switcher.Index {
+			color = bgra("client_master")
+		}
+
+		// Draw client rectangle onto canvas
+		drawImage(cv, &image.Uniform{color}, color, x, y, x+switcherSize+2*switcherMargin, y+switcherSize+2*switcherMargin)
+
+		// Draw client icon onto canvas
+		ico, err := store.IconGet(c.Window.Id, switcherSize)
+		if err == nil {
+			drawImage(cv, ico, color, x+switcherMargin, y+switcherMargin, x+switcherMargin+switcherSize, y+switcherMargin+switcherSize)
+		}
+	}
+
+	// Draw client title
+	title := switcher.Clients[switcher.Index].Latest.Name
+	drawText(cv, title, bgra("text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*textPadding()-rectGap(), textSize())
+
+	// Update canvas
+	if switcher.Window != nil {
+		cv.XDraw()
+		cv.XPaint(switcher.Window.Id)
+	}
+}
+
+func bindSwitcher() {
+	if switcherBound {
+		return
+	}
+	switcherBound = true
+
+	// Cancel switcher on escape
+	xevent.KeyPressFun(func(X *xgbutil.XUtil, ev xevent.KeyPressEvent) {
+		if switcher != nil && keybind.LookupString(X, 0, ev.Detail) == "Escape" {
+			closeSwitcher(false)
+		}
+	}).Connect(store.X, store.X.RootWin())
+
+	// Activate client on modifier release
+	xevent.KeyReleaseFun(func(X *xgbutil.XUtil, ev xevent.KeyReleaseEvent) {
+		if switcher != nil && keybind.ModGet(X, ev.Detail)&^xproto.ModMaskShift != 0 {
+			closeSwitcher(true)
+		}
+	}).Connect(store.X, store.X.RootWin())
+}
+
+func modifierHeld() bool {
+	pointer, err := xproto.QueryPointer(store.X.Conn(), store.X.RootWin()).Reply()
+	if err != nil {
+		return false
+	}
+
+	// Ignore shift, caps lock and num lock modifiers
+	mods := uint16(xproto.ModMaskControl | xproto.ModMask1 | xproto.ModMask3 | xproto.ModMask4 | xproto.ModMask5)
+
+	return pointer.Mask&mods != 0
+}
+
+func closeSwitcher(activate bool) {
+	if switcher == nil {
+		return
+	}
+	c := switcher.Clients[switcher.Index]
+
+	// Release keyboard and close window
+	keybind.UngrabKeyboard(store.X)
+	closeGraphics(switcher.Window)
+	switcher = nil
+
+	// Activate selected client
+	if activate {
+		activateClient(c)
+	}
+}
+
+func activateClient(c *store.Client) {
+
+	// Switch to desktop of client
+	if c.Latest.Location.Desktop != store.Workplace.CurrentDesktop {
+		store.CurrentDesktopSet(store.X, c.Latest.Location.Desktop)
+	}
+	store.ActiveWindowSet(store.X, c.Window)
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/jezek/xgb/xproto"
+
+	"github.com/jezek/xgbutil"
+	"github.com/jezek/xgbutil/xevent"
+	"github.com/jezek/xgbutil/xgraphics"
+	"github.com/jezek/xgbutil/xwindow"
+
+	"github.com/leukipp/cortile/v2/common"
+	"github.com/leukipp/cortile/v2/desktop"
+	"github.com/leukipp/cortile/v2/store"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	tabs      map[*desktop.Group]*Tabs = make(map[*desktop.Group]*Tabs) // Tab strips per window group
+	tabActive xproto.Window                                             // Active window of last tab update
+)
+
+type Tabs struct {
+	Group    *desktop.Group   // Window group the tabs belong to
+	State    string           // Members and theme of last draw
+	Canvas   *xgraphics.Image // Tabs canvas image
+	Window   *xwindow.Window  // Tabs overlay window
+	Geometry common.Geometry  // Tabs window geometry
+}
+
+func UpdateTabs(tr *desktop.Tracker, click func(c *store.Client)) {
+	visible := map[*desktop.Group]bool{}
+
+	// Obtain window groups of current desktop
+	for _, ws := range tr.Workspaces {
+		if ws.Location.Desktop != store.Workplace.CurrentDesktop || ws.TilingDisabled() {
+			continue
+		}
+		for _, g := range ws.Groups {
+			if g.Tabs.Width > 0 && g.Tabs.Height > 0 {
+				visible[g] = true
+			}
+		}
+	}
+
+	// Remove tabs of hidden or changed groups
+	for g, t := range tabs {
+		if visible[g] && t.Geometry == g.Tabs && t.State == tabState(g) {
+			continue
+		}
+		t.Canvas.Destroy()
+		t.Window.Destroy()
+		delete(tabs, g)
+	}
+
+	// Create tabs of visible groups
+	for g := range visible {
+		if _, ok := tabs[g]; ok {
+			continue
+		}
+		if t := createTabs(g, click); t != nil {
+			tabs[g] = t
+		}
+	}
+
+	// Raise tabs above focused windows
+	if store.Windows.Active.Id != tabActive {
+		tabActive = store.Windows.Active.Id
+		for _, t := range tabs {
+			t.Window.Stack(xproto.StackModeAbove)
+		}
+	}
+}
+
+func createTabs(g *desktop.Group, click func(c *store.Client)) *Tabs {
+	geom := g.Tabs
+	members := append([]*store.Client{}, g.Members...)
+	if len(members) == 0 || geom.Width < len(members) {
+		return nil
+	}
+	size := geom.Width / len(members)
+
+	// Create override redirect window
+	win, err := xwindow.Generate(store.X)
+	if err != nil {
+		log.Error("Tabs generation failed: ", err)
+		return nil
+	}
+	err = win.CreateChecked(store.X.RootWin(), geom.X, geom.Y, geom.Width, geom.Height,
+		xproto.CwOverrideRedirect|xproto.CwEventMask, 1, xproto.EventMaskButtonPress)
+	if err != nil {
+		log.Error("Tabs creation failed: ", err)
+		return nil
+	}
+	styleWindow(win, geom.Width, geom.Height)
+
+	// Create an empty canvas image
+	bg := bgra("background")
+	cv := xgraphics.New(store.X, image.Rect(0, 0, geom.Width, geom.Height))
+	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
+
+	// Draw tabs onto canvas
+	font := textFont()
+	for i, c := range members {
+		x := i * size
+		color := bgra("client_slave")
+		if c == g.Active {
+			color = bgra("client_master")
+		}
+		drawImage(cv, &image.Uniform{color}, color, x+buttonMargin, 0, x+size-buttonMargin, geom.Height)
+
+		// Draw tab label shortened to tab width
+		if font != nil {
+			s := common.MaxInt(geom.Height*2/3, 1)
+			label := []rune(tabLabel(c))
+			w, _ := xgraphics.Extents(font, float64(s), string(label))
+			for w > size-2*textPadding()-2*buttonMargin && len(label) > 1 {
+				label = label[:len(label)-1]
+				w, _ = xgraphics.Extents(font, float64(s), string(label))
+			}
+			cv.Text(x+size/2-w/2, (geom.Height-s)/2, bgra("text"), float64(s), font, string(label))
+		}
+	}
+
+	// Paint the image and map the window
+	cv.XSurfaceSet(win.Id)
+	cv.XDraw()
+	cv.XPaint(win.Id)
+	win.Map()
+
+	// Attach tab events
+	xevent.ButtonPressFun(func(X *xgbutil.XUtil, ev xevent.ButtonPressEvent) {
+		i := int(ev.EventX) / size
+		if i >= 0 && i < len(members) {
+			members[i].Log().Info("Group tab clicked")
+			click(members[i])
+		}
+	}).Connect(store.X, win.Id)
+
+	return &Tabs{
+		Group:    g,
+		State:    tabState(g),
+		Canvas:   cv,
+		Window:   win,
+		Geometry: geom,
+	}
+}
+
+func tabState(g *desktop.Group) string {
+	state := fmt.Sprint(common.ActiveTheme(), g.Active.Window.Id)
+
+	// Combine member windows and titles
+	for _, c := range g.Members {
+		state += fmt.Sprint(c.Window.Id, c.Latest.Name)
+	}
+
+	return state
+}
+
+func tabLabel(c *store.Client) string {
+	if len(c.Latest.Name) > 0 {
+		return c.Latest.Name
+	}
+	return c.Latest.Class
+}
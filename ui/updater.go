@@ -214,8 +214,8 @@ func showProgress(ws *desktop.Workspace) {
 	w, h := logoSize+logoMargin*2, logoSize+logoMargin*2
 
 	// Create an empty canvas image
-	bg := bgra("gui_background")
-	cv = xgraphics.New(store.X, image.Rect(0, 0, w+2*rectMargin, h+fontSize+2*fontMargin+2*rectMargin))
+	bg := bgra("background")
+	cv = xgraphics.New(store.X, image.Rect(0, 0, w+2*rectGap(), h+textSize()+2*textPadding()+2*rectGap()))
 	cv.For(func(x int, y int) xgraphics.BGRA { return bg })
 
 	// Show the canvas graphics
@@ -232,15 +232,15 @@ func updateProgress(txt string) {
 	x, y, w, h := 0, 0, size.X, size.Y
 
 	// Draw background onto canvas
-	color := bgra("gui_client_slave")
-	drawImage(cv, &image.Uniform{color}, color, x+rectMargin, y+rectMargin, x+w-rectMargin, y+h-rectMargin)
+	color := bgra("client_slave")
+	drawImage(cv, &image.Uniform{color}, color, x+rectGap(), y+rectGap(), x+w-rectGap(), y+h-rectGap())
 
 	// Draw logo onto canvas
 	logo, _, _ := image.Decode(bytes.NewBuffer(common.File.Logo))
-	drawImage(cv, xgraphics.NewConvert(store.X, logo), color, x+rectMargin+logoMargin, y+rectMargin+logoMargin, x+w-rectMargin, y+h-rectMargin)
+	drawImage(cv, xgraphics.NewConvert(store.X, logo), color, x+rectGap()+logoMargin, y+rectGap()+logoMargin, x+w-rectGap(), y+h-rectGap())
 
 	// Draw text onto canvas
-	drawText(cv, txt, bgra("gui_text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*fontMargin-rectMargin-logoMargin/2, fontSize)
+	drawText(cv, txt, bgra("text"), cv.Rect.Dx()/2, cv.Rect.Dy()-2*textPadding()-rectGap()-logoMargin/2, textSize())
 
 	// Update canvas
 	cv.XDraw()
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"path/filepath"
+
+	"github.com/leukipp/cortile/v2/common"
+)
+
+func runWorkers() {
+	displays := []string{}
+	for _, display := range strings.Split(common.Args.Displays, ",") {
+		if display = strings.TrimSpace(display); len(display) > 0 {
+			displays = append(displays, display)
+		}
+	}
+	if len(displays) == 0 {
+		return
+	}
+
+	// Start one worker process per display
+	workers := []*exec.Cmd{}
+	for _, display := range displays {
+		instance := workerInstance(display)
+
+		cmd := exec.Command(common.Process.Path, workerArgs(instance)...)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("DISPLAY=%s", display), "NOTIFY_SOCKET=")
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+		if err := cmd.Start(); err != nil {
+			fmt.Println(fmt.Errorf("worker for display %s can't be started (%s)", display, err))
+			continue
+		}
+		workers = append(workers, cmd)
+	}
+
+	// Forward signals to worker processes
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	go func() {
+		for sig := range signals {
+			if sig != syscall.SIGHUP {
+				common.SystemdNotify("STOPPING=1")
+			}
+			for _, cmd := range workers {
+				cmd.Process.Signal(sig)
+			}
+		}
+	}()
+	common.SystemdNotify("READY=1")
+
+	// Wait for worker processes
+	code := 0
+	for _, cmd := range workers {
+		if err := cmd.Wait(); err != nil {
+			code = 1
+		}
+	}
+	if len(workers) < len(displays) {
+		code = 1
+	}
+
+	// Prevent main instance start
+	os.Exit(code)
+}
+
+func workerInstance(display string) string {
+
+	// Convert display name (e.g. host:1.0) into dbus compatible name (e.g. displayhost1_0)
+	name := strings.Replace(strings.TrimPrefix(display, ":"), ":", "", -1)
+	name = regexp.MustCompile(`[^A-Za-z0-9]`).ReplaceAllString(name, "_")
+
+	return fmt.Sprintf("display%s", name)
+}
+
+func workerArgs(instance string) []string {
+	suffix := func(path string) string {
+		ext := filepath.Ext(path)
+		return fmt.Sprintf("%s.%s%s", strings.TrimSuffix(path, ext), instance, ext)
+	}
+
+	// Worker specific arguments
+	args := []string{
+		"-instance", instance,
+		"-lock", suffix(common.Args.Lock),
+		"-log", suffix(common.Args.Log),
+		"-cache", filepath.Join(common.Args.Cache, instance),
+	}
+
+	// Remaining arguments of the supervisor
+	skip := false
+	for _, arg := range os.Args[1:] {
+		if skip {
+			skip = false
+			continue
+		}
+		name := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if strings.HasPrefix(arg, "-") && common.IsInList(name, []string{"displays", "instance", "lock", "log", "cache"}) {
+			skip = !strings.Contains(arg, "=")
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	return args
+}